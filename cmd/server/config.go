@@ -0,0 +1,22 @@
+package main
+
+import (
+	"errors"
+	"go-clean-architecture/internal/infrastructure/config"
+	"os"
+)
+
+// errConfigUsage is returned for missing or unknown config arguments
+var errConfigUsage = errors.New("usage: server [flags] config print")
+
+// runConfig handles the "config" subcommand. loadErr is returned after
+// printing so an invalid configuration still fails the command.
+func runConfig(cfg *config.Config, args []string, loadErr error) error {
+	if len(args) != 1 || args[0] != "print" {
+		return errConfigUsage
+	}
+	if err := cfg.Print(os.Stdout); err != nil {
+		return err
+	}
+	return loadErr
+}
@@ -2,82 +2,404 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"errors"
+	"flag"
+	"fmt"
 	"go-clean-architecture/internal/adapter/controller"
 	"go-clean-architecture/internal/adapter/repository"
+	"go-clean-architecture/internal/infrastructure/auth"
+	"go-clean-architecture/internal/infrastructure/cache"
+	"go-clean-architecture/internal/infrastructure/config"
+	"go-clean-architecture/internal/infrastructure/cors"
 	"go-clean-architecture/internal/infrastructure/database"
+	"go-clean-architecture/internal/infrastructure/health"
+	"go-clean-architecture/internal/infrastructure/idempotency"
+	"go-clean-architecture/internal/infrastructure/logger"
+	"go-clean-architecture/internal/infrastructure/metrics"
+	"go-clean-architecture/internal/infrastructure/outbox"
+	"go-clean-architecture/internal/infrastructure/ratelimit"
 	"go-clean-architecture/internal/infrastructure/server"
+	"go-clean-architecture/internal/infrastructure/tracing"
+	"go-clean-architecture/internal/infrastructure/webhook"
 	"go-clean-architecture/internal/usecase"
-	"log"
+	"go-clean-architecture/internal/usecase/interfaces"
+	"go-clean-architecture/pkg/cursor"
+	"go-clean-architecture/pkg/response"
+	"go-clean-architecture/pkg/version"
+	"io"
+	"log/slog"
 	"os"
 	"os/signal"
+	"strings"
+	"sync"
 	"syscall"
-	"time"
 
 	"github.com/joho/godotenv"
+	"gorm.io/gorm"
 )
 
 func main() {
 	// Load environment variables from .env file if it exists
-	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found, using system environment variables")
+	envErr := godotenv.Load()
+
+	// Read settings from the config file, the environment and flags
+	cfg, args, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if cfg == nil {
+		os.Exit(2)
+	}
+
+	// Printing works even for an invalid configuration to help fixing it
+	if len(args) > 0 && args[0] == "config" {
+		if err := runConfig(cfg, args[1:], err); err != nil {
+			exitConfig(err)
+		}
+		return
+	}
+	if err != nil {
+		exitConfig(err)
 	}
 
-	// Initialize database connection
-	dbConfig := database.NewConfig()
-	db, err := database.Connect(dbConfig)
+	// Configure the structured logger used by every package through slog.Default
+	appLogger, err := logger.New(&cfg.Log, os.Stderr)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		fatal("Failed to configure logger", "error", err)
+	}
+	slog.SetDefault(appLogger)
+
+	if envErr != nil {
+		slog.Info("No .env file found, using system environment variables")
+	}
+
+	// SIGINT and SIGTERM cancel startup as well as the running server
+	signalCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	// Startup failing in the background stops the server the same way, with
+	// the failure as the cause
+	ctx, fail := context.WithCancelCause(signalCtx)
+	defer fail(nil)
+
+	// Run the migrate subcommand instead of the server when requested
+	if len(args) > 0 {
+		if args[0] != "migrate" {
+			fatal("Unknown command, expected \"migrate\" or \"config\"", "command", args[0])
+		}
+		if err := runMigrate(ctx, &cfg.Database, args[1:]); err != nil {
+			fatal("Migration failed", "error", err)
+		}
+		return
 	}
 
-	// Run database migrations
-	if err := database.AutoMigrate(db); err != nil {
-		log.Fatalf("Failed to run database migrations: %v", err)
+	// Initialize tracing before anything that creates spans
+	cfg.Tracing.ServiceVersion = version.Version
+	shutdownTracing, err := tracing.Setup(context.Background(), &cfg.Tracing)
+	if err != nil {
+		fatal("Failed to initialize tracing", "error", err)
 	}
 
 	// Initialize repositories
-	userRepo := repository.NewUserRepository(db)
+	var db *gorm.DB
+	// Readiness checks and metrics are registered by the dependencies that need them
+	probes := health.NewHealth(version.Version, version.Commit)
+	appMetrics := metrics.NewMetrics()
+
+	var userRepo interfaces.UserRepository
+	var outboxRepo interfaces.OutboxRepository
+	var webhookRepo interfaces.WebhookRepository
+	var deliveryRepo interfaces.WebhookDeliveryRepository
+	var txManager interfaces.TxManager
+	var replicaSet *database.ReplicaSet
+	var migrator *database.Migrator
+	switch cfg.Repository {
+	case config.RepositoryMemory:
+		slog.Warn("Using in-memory user repository, data will not be persisted")
+		userRepo = repository.NewMemoryUserRepository()
+		outboxRepo = repository.NewMemoryOutboxRepository()
+		webhookRepo, deliveryRepo = repository.NewMemoryWebhookRepositories()
+		txManager = repository.NewMemoryTxManager()
+	case config.RepositoryGorm:
+		// Open the connection pool, the database is waited for once the
+		// server answers health checks
+		dbConfig := &cfg.Database
+		conn, err := database.Open(dbConfig)
+		if err != nil {
+			fatal("Failed to open database", "error", err)
+		}
+		db = conn
+
+		migrator, err = database.NewMigrator(db)
+		if err != nil {
+			fatal("Failed to load database migrations", "error", err)
+		}
+
+		probes.Register(
+			health.Check{Name: "database", Checker: database.PingChecker(db), Critical: true},
+			health.Check{Name: "database_pool", Checker: database.PoolChecker(db, 0.9)},
+			health.Check{Name: "migrations", Checker: database.MigrationChecker(migrator), Critical: true},
+		)
+
+		sqlDB, err := db.DB()
+		if err != nil {
+			fatal("Failed to access database pool", "error", err)
+		}
+		appMetrics.RegisterDBStats(sqlDB, dbConfig.DBName)
+
+		// Send lookups and listings to read replicas when configured
+		replicaDBs, err := database.ConnectReplicas(dbConfig)
+		if err != nil {
+			fatal("Failed to connect to read replicas", "error", err)
+		}
+		var replicas repository.ReadReplicas
+		if len(replicaDBs) > 0 {
+			replicaSet = database.NewReplicaSet(replicaDBs, dbConfig.ReplicaCheckInterval)
+			replicas = replicaSet
+			probes.Register(health.Check{Name: "database_replicas", Checker: replicaSet.Checker()})
+			for i, replica := range replicaDBs {
+				if sqlDB, err := replica.DB(); err == nil {
+					appMetrics.RegisterDBStats(sqlDB, fmt.Sprintf("%s_replica_%d", dbConfig.DBName, i))
+				}
+			}
+		}
+
+		userRepo = repository.NewUserRepository(db, replicas)
+		outboxRepo = repository.NewOutboxRepository(db)
+		webhookRepo = repository.NewWebhookRepository(db)
+		deliveryRepo = repository.NewWebhookDeliveryRepository(db)
+		txManager, err = repository.NewTxManager(db, interfaces.TxOptions{
+			Isolation:   interfaces.IsolationLevel(dbConfig.TxIsolation),
+			MaxAttempts: dbConfig.TxMaxAttempts,
+		})
+		if err != nil {
+			fatal("Invalid DB_TX_ISOLATION", "error", err)
+		}
+	}
+
+	// Cache user lookups in front of the repository when CACHE_DRIVER is set
+	cacheConfig := &cfg.Cache
+	userCache, err := cache.New(cacheConfig)
+	if err != nil {
+		fatal("Failed to initialize cache", "error", err)
+	}
+	if userCache != nil {
+		slog.Info("User cache enabled", "driver", cacheConfig.Driver, "ttl", cacheConfig.TTL)
+		userRepo = repository.NewCachedUserRepository(userRepo, userCache, cacheConfig.TTL, cacheConfig.NegativeTTL)
+	}
+
+	// Initialize auth services
+	passwordHasher := auth.NewBcryptHasher(0)
+	if cfg.DevMode && cfg.JWT.Algorithm == auth.AlgorithmHS256 && cfg.JWT.Secret == "" {
+		slog.Warn("DEV_MODE without JWT_SECRET, using a random key, tokens will not survive restarts")
+		cfg.JWT.Secret = string(randomSecret())
+	}
+	tokenService, err := auth.NewJWTService(&cfg.JWT)
+	if err != nil {
+		fatal("Failed to initialize token service", "error", err)
+	}
 
 	// Initialize use cases
-	userUseCase := usecase.NewUserUseCase(userRepo)
+	userUseCase := usecase.NewUserUseCase(userRepo, outboxRepo, txManager, passwordHasher, appMetrics)
+	authUseCase := usecase.NewAuthUseCase(userRepo, passwordHasher, tokenService)
+	webhookUseCase := usecase.NewWebhookUseCase(webhookRepo, deliveryRepo, appMetrics)
+
+	// Errors use the envelope unless ERROR_FORMAT=problem or the client asks for problem details
+	if err := response.SetErrorFormat(cfg.ErrorFormat); err != nil {
+		fatal("Invalid ERROR_FORMAT", "error", err)
+	}
 
 	// Initialize controllers
-	userController := controller.NewUserController(userUseCase)
+	userController := controller.NewUserController(userUseCase, cursor.NewCodec([]byte(cfg.CursorSecret)))
+	authController := controller.NewAuthController(authUseCase)
+	webhookController := controller.NewWebhookController(webhookUseCase)
+
+	// Remember responses of requests sent with an Idempotency-Key
+	idempotencyStore, err := idempotency.NewStore(&cfg.Idempotency)
+	if err != nil {
+		fatal("Failed to initialize idempotency store", "error", err)
+	}
+
+	// Limit request rates per client and route group
+	rateLimitStore, err := ratelimit.NewStore(&cfg.RateLimit)
+	if err != nil {
+		fatal("Failed to initialize rate limit store", "error", err)
+	}
+
+	// Allow browsers on configured origins to call the API
+	corsPolicy, err := cors.NewPolicy(&cfg.CORS)
+	if err != nil {
+		fatal("Invalid CORS configuration", "error", err)
+	}
 
 	// Initialize HTTP server
-	httpServer := server.NewServer(userController)
+	httpServer, err := server.NewServer(&cfg.Server, userController, authController, webhookController, probes, appMetrics,
+		idempotency.NewIdempotency(idempotencyStore, &cfg.Idempotency),
+		ratelimit.NewLimiter(rateLimitStore, &cfg.RateLimit), corsPolicy)
+	if err != nil {
+		fatal("Failed to create server", "error", err)
+	}
 
-	// Start HTTP server
-	if err := httpServer.Start(os.Getenv("PORT")); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	// Start HTTP server first so probes answer while the database comes up
+	if err := httpServer.Start(); err != nil {
+		fatal("Failed to start server", "error", err)
 	}
 
-	log.Println("Server started successfully")
+	// Everything needing the database starts in the background once it
+	// answers, so the server reports not ready while it is down. Startup
+	// failures and signals both go through the graceful shutdown below.
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+
+		if db != nil {
+			if err := database.WaitForConnection(ctx, db, &cfg.Database); err != nil {
+				fail(err)
+				return
+			}
 
-	// Wait for interrupt signal for graceful shutdown
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+			// Migrations normally run through "server migrate up", but can be applied on boot
+			if cfg.Database.MigrateOnStart {
+				if err := migrator.Up(ctx); err != nil {
+					fail(fmt.Errorf("failed to run database migrations: %w", err))
+					return
+				}
+			}
+		}
+
+		// Bootstrap the first admin account when configured
+		if admin := cfg.Admin; admin.Email != "" {
+			if err := userUseCase.EnsureAdmin(ctx, admin.Name, admin.Email, admin.Password); err != nil {
+				fail(fmt.Errorf("failed to create admin user: %w", err))
+				return
+			}
+		}
+
+		// Publish recorded domain events in the background, queueing webhook
+		// deliveries that the dispatcher then sends
+		publisher := outbox.NewMultiPublisher(outbox.NewLogPublisher(slog.Default()), webhookUseCase)
+		relay := outbox.NewRelay(outboxRepo, txManager, publisher, &cfg.Outbox)
+		dispatcher := webhook.NewDispatcher(webhookRepo, deliveryRepo, nil, &cfg.Webhook)
+		workers.Add(2)
+		go func() {
+			defer workers.Done()
+			relay.Run(workerCtx)
+		}()
+		go func() {
+			defer workers.Done()
+			dispatcher.Run(workerCtx)
+		}()
+		if replicaSet != nil {
+			workers.Add(1)
+			go func() {
+				defer workers.Done()
+				replicaSet.Run(workerCtx)
+			}()
+		}
+		slog.Info("Background workers started")
+	}()
 
-	log.Println("Received shutdown signal, initiating graceful shutdown...")
+	slog.Info("Server started successfully", "version", version.Version, "commit", version.Commit)
+
+	// Wait for interrupt signal or a startup failure for graceful shutdown
+	<-ctx.Done()
+	stop()
+
+	// Signals cancel with context.Canceled, failures with their error
+	startupErr := context.Cause(ctx)
+	if errors.Is(startupErr, context.Canceled) {
+		startupErr = nil
+		slog.Info("Received shutdown signal, initiating graceful shutdown")
+	} else {
+		slog.Error("Startup failed, initiating graceful shutdown", "error", startupErr)
+	}
 
 	// Create shutdown context with timeout
-	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer shutdownCancel()
 
 	// Shutdown HTTP server
 	if err := httpServer.Shutdown(shutdownCtx); err != nil {
-		log.Printf("Server shutdown error: %v", err)
+		slog.Error("Server shutdown error", "error", err)
+	}
+
+	// Stop background workers and any startup still waiting for the
+	// database, unfinished work is picked up after restart
+	stopWorkers()
+	workers.Wait()
+
+	// Flush pending spans
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("Tracing shutdown error", "error", err)
+	}
+
+	// Close cache connections
+	if closer, ok := userCache.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			slog.Error("Cache close error", "error", err)
+		}
+	}
+	if closer, ok := idempotencyStore.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			slog.Error("Idempotency store close error", "error", err)
+		}
+	}
+	if closer, ok := rateLimitStore.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			slog.Error("Rate limit store close error", "error", err)
+		}
 	}
 
-	// Close database connection
-	sqlDB, err := db.DB()
-	if err == nil {
-		if err := sqlDB.Close(); err != nil {
-			log.Printf("Database close error: %v", err)
-		} else {
-			log.Println("Database connection closed")
+	// Close database connections
+	if replicaSet != nil {
+		if err := replicaSet.Close(); err != nil {
+			slog.Error("Read replica close error", "error", err)
+		}
+	}
+	if db != nil {
+		sqlDB, err := db.DB()
+		if err == nil {
+			if err := sqlDB.Close(); err != nil {
+				slog.Error("Database close error", "error", err)
+			} else {
+				slog.Info("Database connection closed")
+			}
 		}
 	}
 
-	log.Println("Server shutdown complete")
+	if startupErr != nil {
+		fatal("Server stopped after startup failed", "error", startupErr)
+	}
+	slog.Info("Server shutdown complete")
+}
+
+// randomSecret returns a key that only lasts until the process exits
+func randomSecret() []byte {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		fatal("Failed to generate random secret", "error", err)
+	}
+	return secret
+}
+
+// exitConfig reports configuration errors one per line and exits. The
+// logger is not configured yet, so they are written to stderr as is.
+func exitConfig(err error) {
+	if errors.Is(err, errConfigUsage) {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	fmt.Fprintln(os.Stderr, "Invalid configuration:")
+	for _, line := range strings.Split(err.Error(), "\n") {
+		fmt.Fprintln(os.Stderr, "  "+line)
+	}
+	os.Exit(1)
+}
+
+// fatal logs an error and exits
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
 }
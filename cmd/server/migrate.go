@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-clean-architecture/internal/infrastructure/database"
+	"log/slog"
+	"os"
+	"strconv"
+	"text/tabwriter"
+)
+
+// errMigrateUsage is returned for missing or unknown migrate arguments
+var errMigrateUsage = errors.New("usage: server [flags] migrate up|down|status|to <version>")
+
+// runMigrate handles the "migrate" subcommand
+func runMigrate(ctx context.Context, dbConfig *database.Config, args []string) error {
+	if len(args) == 0 {
+		return errMigrateUsage
+	}
+
+	db, err := database.Connect(ctx, dbConfig)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	}()
+
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		if err := migrator.Up(ctx); err != nil {
+			return err
+		}
+	case "down":
+		if err := migrator.Down(ctx); err != nil {
+			return err
+		}
+	case "to":
+		if len(args) != 2 {
+			return errMigrateUsage
+		}
+		version, err := strconv.ParseUint(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version %q: %w", args[1], err)
+		}
+		if err := migrator.To(ctx, version); err != nil {
+			return err
+		}
+	case "status":
+		return printMigrationStatus(ctx, migrator)
+	default:
+		return errMigrateUsage
+	}
+
+	version, err := migrator.Version(ctx)
+	if err != nil {
+		return err
+	}
+	slog.Info("Database migrated", "version", version)
+	return nil
+}
+
+// printMigrationStatus writes a table of known migrations to stdout
+func printMigrationStatus(ctx context.Context, migrator *database.Migrator) error {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, status := range statuses {
+		state, appliedAt := "pending", "-"
+		if status.Applied {
+			state, appliedAt = "applied", status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", status.Version, status.Name, state, appliedAt)
+	}
+	return w.Flush()
+}
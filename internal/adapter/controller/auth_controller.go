@@ -0,0 +1,161 @@
+package controller
+
+import (
+	"go-clean-architecture/internal/entity"
+	"go-clean-architecture/internal/usecase"
+	"go-clean-architecture/pkg/response"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuthController handles HTTP requests for authentication
+type AuthController struct {
+	authUseCase *usecase.AuthUseCase
+}
+
+// NewAuthController creates a new auth controller instance
+func NewAuthController(authUseCase *usecase.AuthUseCase) *AuthController {
+	return &AuthController{
+		authUseCase: authUseCase,
+	}
+}
+
+// loginRequest is the body of POST /auth/login
+type loginRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+// refreshRequest is the body of POST /auth/refresh
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// tokenResponse is returned by login and refresh
+type tokenResponse struct {
+	AccessToken      string    `json:"access_token"`
+	TokenType        string    `json:"token_type"`
+	ExpiresIn        int64     `json:"expires_in"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+// Login handles POST /auth/login
+func (ctrl *AuthController) Login(c *gin.Context) error {
+	var req loginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return badRequest("Invalid request body", err)
+	}
+
+	tokens, err := ctrl.authUseCase.Login(c.Request.Context(), req.Email, req.Password)
+	if err != nil {
+		return err
+	}
+
+	response.Success(c, "Logged in successfully", newTokenResponse(tokens))
+	return nil
+}
+
+// Refresh handles POST /auth/refresh
+func (ctrl *AuthController) Refresh(c *gin.Context) error {
+	var req refreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return badRequest("Invalid request body", err)
+	}
+
+	tokens, err := ctrl.authUseCase.Refresh(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		return err
+	}
+
+	response.Success(c, "Token refreshed successfully", newTokenResponse(tokens))
+	return nil
+}
+
+// authErrorKey stores why Authenticate rejected the request's token
+const authErrorKey = "auth_error"
+
+// Authenticate is a middleware that stores the user of a valid access token
+// in the request context and only records an invalid one, so a rate limiter
+// placed after it also counts requests with forged tokens. RequireAuth or
+// OptionalAuth must follow to reject them.
+func (ctrl *AuthController) Authenticate(c *gin.Context) {
+	if c.GetHeader("Authorization") == "" {
+		c.Next()
+		return
+	}
+	user, err := ctrl.verify(c)
+	if err != nil {
+		c.Set(authErrorKey, err)
+		c.Next()
+		return
+	}
+	c.Request = c.Request.WithContext(usecase.ContextWithUser(c.Request.Context(), user))
+	c.Next()
+}
+
+// RequireAuth is a middleware that rejects requests without a valid access
+// token and stores the authenticated user in the request context
+func (ctrl *AuthController) RequireAuth(c *gin.Context) {
+	if c.GetHeader("Authorization") == "" {
+		_ = c.Error(unauthorized("Missing bearer token"))
+		c.Abort()
+		return
+	}
+	ctrl.authenticate(c)
+}
+
+// OptionalAuth is a middleware that authenticates the request when an
+// access token is sent and lets anonymous requests through otherwise
+func (ctrl *AuthController) OptionalAuth(c *gin.Context) {
+	if c.GetHeader("Authorization") == "" {
+		c.Next()
+		return
+	}
+	ctrl.authenticate(c)
+}
+
+// authenticate stores the user in the request context, reusing the outcome
+// of Authenticate when it ran, and rejects the request on an invalid token
+func (ctrl *AuthController) authenticate(c *gin.Context) {
+	if _, ok := usecase.UserFromContext(c.Request.Context()); ok {
+		c.Next()
+		return
+	}
+	if err, ok := c.Get(authErrorKey); ok {
+		_ = c.Error(err.(error))
+		c.Abort()
+		return
+	}
+
+	user, err := ctrl.verify(c)
+	if err != nil {
+		_ = c.Error(err)
+		c.Abort()
+		return
+	}
+	c.Request = c.Request.WithContext(usecase.ContextWithUser(c.Request.Context(), user))
+	c.Next()
+}
+
+// verify validates the bearer token and returns its user
+func (ctrl *AuthController) verify(c *gin.Context) (*entity.User, error) {
+	scheme, token, _ := strings.Cut(c.GetHeader("Authorization"), " ")
+	if !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return nil, unauthorized("Missing bearer token")
+	}
+	return ctrl.authUseCase.Authenticate(c.Request.Context(), strings.TrimSpace(token))
+}
+
+// newTokenResponse converts a token pair into the response body
+func newTokenResponse(tokens *usecase.TokenPair) tokenResponse {
+	return tokenResponse{
+		AccessToken:      tokens.AccessToken,
+		TokenType:        "Bearer",
+		ExpiresIn:        int64(time.Until(tokens.AccessExpiresAt).Seconds()),
+		RefreshToken:     tokens.RefreshToken,
+		RefreshExpiresAt: tokens.RefreshExpiresAt,
+	}
+}
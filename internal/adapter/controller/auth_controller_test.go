@@ -0,0 +1,200 @@
+package controller
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"go-clean-architecture/internal/adapter/repository"
+	"go-clean-architecture/internal/entity"
+	"go-clean-architecture/internal/infrastructure/auth"
+	"go-clean-architecture/internal/infrastructure/ratelimit"
+	"go-clean-architecture/internal/usecase"
+	"go-clean-architecture/internal/usecase/interfaces"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// tokenConfigs returns an HS256 and an RS256 token configuration
+func tokenConfigs(t *testing.T) map[string]auth.Config {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	keyFile := filepath.Join(t.TempDir(), "private.pem")
+	block := &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	base := auth.Config{Issuer: "test", AccessTTL: time.Minute, RefreshTTL: time.Hour}
+	hs256, rs256 := base, base
+	hs256.Algorithm, hs256.Secret = auth.AlgorithmHS256, "0123456789abcdef0123456789abcdef"
+	rs256.Algorithm, rs256.PrivateKeyFile = auth.AlgorithmRS256, keyFile
+	return map[string]auth.Config{"HS256": hs256, "RS256": rs256}
+}
+
+// newTokenService creates a token service, failing the test on error
+func newTokenService(t *testing.T, config auth.Config) interfaces.TokenService {
+	t.Helper()
+	tokens, err := auth.NewJWTService(&config)
+	if err != nil {
+		t.Fatalf("NewJWTService() error = %v", err)
+	}
+	return tokens
+}
+
+// newAuthRouter serves /required behind RequireAuth and /optional behind
+// OptionalAuth, both answering with the authenticated user ID or 0. Under
+// /authenticate the same routes run Authenticate first.
+func newAuthRouter(users interfaces.UserRepository, tokens interfaces.TokenService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	ctrl := NewAuthController(usecase.NewAuthUseCase(users, auth.NewBcryptHasher(0), tokens))
+	whoami := func(c *gin.Context) {
+		var id uint
+		if user, ok := usecase.UserFromContext(c.Request.Context()); ok {
+			id = user.ID
+		}
+		c.String(http.StatusOK, "%d", id)
+	}
+
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.GET("/required", ctrl.RequireAuth, whoami)
+	router.GET("/optional", ctrl.OptionalAuth, whoami)
+	router.GET("/authenticate/required", ctrl.Authenticate, ctrl.RequireAuth, whoami)
+	router.GET("/authenticate/optional", ctrl.Authenticate, ctrl.OptionalAuth, whoami)
+	return router
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	for algorithm, config := range tokenConfigs(t) {
+		t.Run(algorithm, func(t *testing.T) {
+			users := repository.NewMemoryUserRepository()
+			active := &entity.User{Name: "Active", Email: "active@example.com"}
+			inactive := &entity.User{Name: "Inactive", Email: "inactive@example.com"}
+			for _, user := range []*entity.User{active, inactive} {
+				if err := users.Create(context.Background(), user); err != nil {
+					t.Fatalf("Create() error = %v", err)
+				}
+			}
+			inactive.Active = false
+			if err := users.Update(context.Background(), inactive); err != nil {
+				t.Fatalf("Update() error = %v", err)
+			}
+
+			tokens := newTokenService(t, config)
+			expiredConfig := config
+			expiredConfig.AccessTTL = -time.Minute
+			expiredTokens := newTokenService(t, expiredConfig)
+
+			bearer := func(issue func(*entity.User) (string, time.Time, error), user *entity.User) string {
+				t.Helper()
+				token, _, err := issue(user)
+				if err != nil {
+					t.Fatalf("issue token error = %v", err)
+				}
+				return "Bearer " + token
+			}
+
+			router := newAuthRouter(users, tokens)
+			tests := []struct {
+				name          string
+				authorization string
+				wantRequired  int
+				wantOptional  int
+				wantUser      uint
+			}{
+				{name: "anonymous", wantRequired: http.StatusUnauthorized, wantOptional: http.StatusOK},
+				{name: "valid token", authorization: bearer(tokens.IssueAccessToken, active), wantRequired: http.StatusOK, wantOptional: http.StatusOK, wantUser: active.ID},
+				{name: "other scheme", authorization: "Basic dXNlcjpwYXNz", wantRequired: http.StatusUnauthorized, wantOptional: http.StatusUnauthorized},
+				{name: "empty bearer", authorization: "Bearer ", wantRequired: http.StatusUnauthorized, wantOptional: http.StatusUnauthorized},
+				{name: "expired token", authorization: bearer(expiredTokens.IssueAccessToken, active), wantRequired: http.StatusUnauthorized, wantOptional: http.StatusUnauthorized},
+				{name: "refresh token used as access token", authorization: bearer(tokens.IssueRefreshToken, active), wantRequired: http.StatusUnauthorized, wantOptional: http.StatusUnauthorized},
+				{name: "deactivated user", authorization: bearer(tokens.IssueAccessToken, inactive), wantRequired: http.StatusUnauthorized, wantOptional: http.StatusUnauthorized},
+			}
+
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					paths := map[string]int{
+						"/required":              tt.wantRequired,
+						"/optional":              tt.wantOptional,
+						"/authenticate/required": tt.wantRequired,
+						"/authenticate/optional": tt.wantOptional,
+					}
+					for path, want := range paths {
+						r := httptest.NewRequest(http.MethodGet, path, nil)
+						if tt.authorization != "" {
+							r.Header.Set("Authorization", tt.authorization)
+						}
+						w := httptest.NewRecorder()
+						router.ServeHTTP(w, r)
+
+						if w.Code != want {
+							t.Errorf("GET %s status = %d, want %d", path, w.Code, want)
+							continue
+						}
+						if want == http.StatusOK && w.Body.String() != fmt.Sprint(tt.wantUser) {
+							t.Errorf("GET %s user = %s, want %d", path, w.Body.String(), tt.wantUser)
+						}
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestAuthenticateBeforeLimiter(t *testing.T) {
+	config := tokenConfigs(t)["HS256"]
+	users := repository.NewMemoryUserRepository()
+	user := &entity.User{Name: "Alice", Email: "alice@example.com"}
+	if err := users.Create(context.Background(), user); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	tokens := newTokenService(t, config)
+	token, _, err := tokens.IssueAccessToken(user)
+	if err != nil {
+		t.Fatalf("IssueAccessToken() error = %v", err)
+	}
+
+	limit, err := ratelimit.ParseLimit("2/m")
+	if err != nil {
+		t.Fatalf("ParseLimit() error = %v", err)
+	}
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), &ratelimit.Config{Limits: map[string]ratelimit.Limit{ratelimit.GroupUsers: limit}})
+	ctrl := NewAuthController(usecase.NewAuthUseCase(users, auth.NewBcryptHasher(0), tokens))
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.GET("/users", ctrl.Authenticate, limiter.Middleware(ratelimit.GroupUsers), ctrl.RequireAuth, func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	get := func(authorization string) int {
+		r := httptest.NewRequest(http.MethodGet, "/users", nil)
+		r.Header.Set("Authorization", authorization)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	// Forged tokens are rejected but still spend the bucket of their IP
+	for i, want := range []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests} {
+		if got := get("Bearer forged"); got != want {
+			t.Errorf("forged request %d status = %d, want %d", i+1, got, want)
+		}
+	}
+	// A valid token from the same IP is limited by its user
+	if got := get("Bearer " + token); got != http.StatusOK {
+		t.Errorf("valid request status = %d, want %d", got, http.StatusOK)
+	}
+}
@@ -0,0 +1,129 @@
+package controller
+
+import (
+	"errors"
+	"go-clean-architecture/internal/entity"
+	"go-clean-architecture/pkg/response"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HandlerFunc is a gin handler that reports failures by returning them
+type HandlerFunc func(c *gin.Context) error
+
+// Handle adapts a HandlerFunc to gin, leaving the error for ErrorHandler
+func Handle(handler HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := handler(c); err != nil {
+			_ = c.Error(err)
+			c.Abort()
+		}
+	}
+}
+
+// ErrorHandler is a middleware that writes the response for the last error
+// recorded by a handler, mapping domain errors by their kind
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		writeError(c, c.Errors.Last().Err)
+	}
+}
+
+// requestError is a problem with the HTTP request itself, such as a malformed
+// body or precondition, rather than a domain error
+type requestError struct {
+	status  int
+	code    string
+	message string
+	err     error
+}
+
+// Error returns the message followed by the cause
+func (e *requestError) Error() string {
+	if e.err != nil {
+		return e.message + ": " + e.err.Error()
+	}
+	return e.message
+}
+
+// Unwrap returns the cause
+func (e *requestError) Unwrap() error {
+	return e.err
+}
+
+// badRequest reports a malformed request, err may carry field errors
+func badRequest(message string, err error) error {
+	return &requestError{status: http.StatusBadRequest, code: "bad_request", message: message, err: err}
+}
+
+// unauthorized reports a request without usable credentials
+func unauthorized(message string) error {
+	return &requestError{status: http.StatusUnauthorized, code: "unauthorized", message: message}
+}
+
+// preconditionFailed reports a conditional request that cannot match
+func preconditionFailed(message string) error {
+	return &requestError{status: http.StatusPreconditionFailed, code: "precondition_failed", message: message}
+}
+
+// unsupportedMediaType reports a request body in an unsupported format
+func unsupportedMediaType(message string) error {
+	return &requestError{status: http.StatusUnsupportedMediaType, code: "unsupported_media_type", message: message}
+}
+
+// unprocessableEntity reports a well formed request that cannot be applied
+func unprocessableEntity(message string, err error) error {
+	return &requestError{status: http.StatusUnprocessableEntity, code: "unprocessable_entity", message: message, err: err}
+}
+
+// kindStatus maps domain error kinds to HTTP status codes
+var kindStatus = map[entity.ErrorKind]int{
+	entity.KindNotFound:     http.StatusNotFound,
+	entity.KindConflict:     http.StatusConflict,
+	entity.KindInvalid:      http.StatusBadRequest,
+	entity.KindUnauthorized: http.StatusUnauthorized,
+	entity.KindForbidden:    http.StatusForbidden,
+	entity.KindUnavailable:  http.StatusServiceUnavailable,
+}
+
+// writeError translates an error into a status code and response body
+func writeError(c *gin.Context, err error) {
+	var (
+		status  int
+		code    string
+		message string
+		cause   error
+	)
+
+	var reqErr *requestError
+	var domainErr *entity.Error
+	switch {
+	case errors.As(err, &reqErr):
+		status, code, message, cause = reqErr.status, reqErr.code, reqErr.message, reqErr.err
+	case errors.As(err, &domainErr):
+		status, code, message = kindStatus[domainErr.Kind], domainErr.Code, err.Error()
+		// A stale If-Match means the client's precondition no longer holds
+		if errors.Is(err, entity.ErrVersionConflict) && c.GetHeader("If-Match") != "" {
+			status = http.StatusPreconditionFailed
+		}
+	default:
+		// Internal details are logged with the request ID instead of being sent
+		slog.ErrorContext(c.Request.Context(), "Request failed", "error", err)
+		status, code, message = http.StatusInternalServerError, "internal_error", "Internal server error"
+	}
+
+	switch status {
+	case http.StatusUnauthorized:
+		c.Header("WWW-Authenticate", `Bearer realm="api"`)
+	case http.StatusServiceUnavailable:
+		c.Header("Retry-After", "1")
+	}
+	response.Error(c, status, code, message, cause)
+}
@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"go-clean-architecture/internal/entity"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// userETag returns the strong entity tag for a user representation
+func userETag(user *entity.User) string {
+	return `"` + strconv.FormatUint(uint64(user.Version), 10) + `"`
+}
+
+// requiredVersion returns the version required by If-Match, 0 if there is
+// no precondition, or an error when the header can never match
+func requiredVersion(c *gin.Context) (uint, error) {
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return 0, preconditionFailed("If-Match does not match the current user version")
+	}
+	return version, nil
+}
+
+// ifMatchVersion extracts the version a client expects from If-Match.
+// It returns 0 when there is no precondition and ok=false when the header
+// can never match, such as a weak or foreign entity tag.
+func ifMatchVersion(c *gin.Context) (version uint, ok bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return 0, true
+	}
+
+	// Only a single strong entity tag is supported
+	if !strings.HasPrefix(header, `"`) || !strings.HasSuffix(header, `"`) || len(header) < 2 {
+		return 0, false
+	}
+	parsed, err := strconv.ParseUint(header[1:len(header)-1], 10, 32)
+	if err != nil || parsed == 0 {
+		return 0, false
+	}
+	return uint(parsed), true
+}
+
+// ifNoneMatch reports whether If-None-Match matches the current entity tag
+// using weak comparison
+func ifNoneMatch(c *gin.Context, etag string) bool {
+	header := c.GetHeader("If-None-Match")
+	if header == "" {
+		return false
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -1,77 +1,88 @@
 package controller
 
 import (
-	"errors"
+	"fmt"
 	"go-clean-architecture/internal/entity"
 	"go-clean-architecture/internal/usecase"
+	"go-clean-architecture/internal/usecase/interfaces"
+	"go-clean-architecture/pkg/cursor"
 	"go-clean-architecture/pkg/response"
+	"io"
+	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 )
 
 // UserController handles HTTP requests for user operations
 type UserController struct {
 	userUseCase *usecase.UserUseCase
+	cursors     *cursor.Codec
+}
+
+// cursorToken is the signed payload behind the cursor query parameter
+type cursorToken struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uint      `json:"i"`
+	Backward  bool      `json:"b,omitempty"`
 }
 
 // NewUserController creates a new user controller instance
-func NewUserController(userUseCase *usecase.UserUseCase) *UserController {
+func NewUserController(userUseCase *usecase.UserUseCase, cursors *cursor.Codec) *UserController {
 	return &UserController{
 		userUseCase: userUseCase,
+		cursors:     cursors,
 	}
 }
 
 // CreateUser handles POST /users
-func (ctrl *UserController) CreateUser(c *gin.Context) {
+func (ctrl *UserController) CreateUser(c *gin.Context) error {
 	var user entity.User
 	if err := c.ShouldBindJSON(&user); err != nil {
-		response.BadRequest(c, "Invalid request body", err.Error())
-		return
+		return badRequest("Invalid request body", err)
 	}
 
 	if err := ctrl.userUseCase.CreateUser(c.Request.Context(), &user); err != nil {
-		switch {
-		case errors.Is(err, entity.ErrUserAlreadyExists):
-			response.Conflict(c, "User with this email already exists")
-		case errors.Is(err, entity.ErrInvalidUserName), errors.Is(err, entity.ErrInvalidUserEmail):
-			response.BadRequest(c, "Invalid user data", err.Error())
-		default:
-			response.InternalError(c, "Failed to create user", err.Error())
-		}
-		return
+		return err
 	}
 
 	response.Created(c, "User created successfully", user)
+	return nil
 }
 
 // GetUser handles GET /users/:id
-func (ctrl *UserController) GetUser(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 32)
+func (ctrl *UserController) GetUser(c *gin.Context) error {
+	id, err := userID(c)
 	if err != nil {
-		response.BadRequest(c, "Invalid user ID", err.Error())
-		return
+		return err
 	}
 
-	user, err := ctrl.userUseCase.GetUser(c.Request.Context(), uint(id))
+	user, err := ctrl.userUseCase.GetUser(c.Request.Context(), id)
 	if err != nil {
-		switch {
-		case errors.Is(err, entity.ErrUserNotFound):
-			response.NotFound(c, "User not found")
-		case errors.Is(err, entity.ErrInvalidUserID):
-			response.BadRequest(c, "Invalid user ID", err.Error())
-		default:
-			response.InternalError(c, "Failed to retrieve user", err.Error())
-		}
-		return
+		return err
+	}
+
+	etag := userETag(user)
+	c.Header("ETag", etag)
+	if ifNoneMatch(c, etag) {
+		c.Status(http.StatusNotModified)
+		return nil
 	}
 
 	response.Success(c, "User retrieved successfully", user)
+	return nil
 }
 
-// GetAllUsers handles GET /users
-func (ctrl *UserController) GetAllUsers(c *gin.Context) {
+// GetAllUsers handles GET /users.
+// Passing a cursor parameter (empty for the first page) switches to keyset pagination.
+func (ctrl *UserController) GetAllUsers(c *gin.Context) error {
+	if _, ok := c.GetQuery("cursor"); ok {
+		return ctrl.getUsersByCursor(c)
+	}
+
 	pageStr := c.DefaultQuery("page", "1")
 	pageSizeStr := c.DefaultQuery("page_size", "10")
 
@@ -85,109 +96,276 @@ func (ctrl *UserController) GetAllUsers(c *gin.Context) {
 		pageSize = 10
 	}
 
-	users, total, err := ctrl.userUseCase.GetAllUsers(c.Request.Context(), page, pageSize)
+	query, err := parseUserQuery(c)
+	if err != nil {
+		return badRequest("Invalid query parameters", err)
+	}
+
+	users, total, err := ctrl.userUseCase.GetAllUsers(c.Request.Context(), query, page, pageSize)
 	if err != nil {
-		response.InternalError(c, "Failed to retrieve users", err.Error())
-		return
+		return err
 	}
 
 	response.Paginated(c, "Users retrieved successfully", users, total, page, pageSize)
+	return nil
+}
+
+// getUsersByCursor handles GET /users with keyset pagination
+func (ctrl *UserController) getUsersByCursor(c *gin.Context) error {
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+
+	query, err := parseUserQuery(c)
+	if err != nil {
+		return badRequest("Invalid query parameters", err)
+	}
+
+	page := interfaces.CursorPage{Limit: pageSize}
+	if token := c.Query("cursor"); token != "" {
+		var decoded cursorToken
+		if err := ctrl.cursors.Decode(token, &decoded); err != nil {
+			return badRequest("Invalid cursor", err)
+		}
+		page.Cursor = &interfaces.UserCursor{CreatedAt: decoded.CreatedAt, ID: decoded.ID}
+		page.Backward = decoded.Backward
+	}
+
+	result, err := ctrl.userUseCase.GetUsersByCursor(c.Request.Context(), query, page)
+	if err != nil {
+		return err
+	}
+
+	nextCursor, err := ctrl.encodeCursor(result.Next, false)
+	if err != nil {
+		return err
+	}
+	prevCursor, err := ctrl.encodeCursor(result.Prev, true)
+	if err != nil {
+		return err
+	}
+
+	response.CursorPaginated(c, "Users retrieved successfully", result.Users, pageSize, nextCursor, prevCursor)
+	return nil
+}
+
+// encodeCursor signs a keyset position, returning an empty token for nil
+func (ctrl *UserController) encodeCursor(position *interfaces.UserCursor, backward bool) (string, error) {
+	if position == nil {
+		return "", nil
+	}
+	return ctrl.cursors.Encode(cursorToken{
+		CreatedAt: position.CreatedAt,
+		ID:        position.ID,
+		Backward:  backward,
+	})
+}
+
+// parseUserQuery builds a listing query from the filter and sort parameters
+func parseUserQuery(c *gin.Context) (interfaces.UserQuery, error) {
+	var query interfaces.UserQuery
+
+	if activeStr := c.Query("active"); activeStr != "" {
+		active, err := strconv.ParseBool(activeStr)
+		if err != nil {
+			return query, fmt.Errorf("active: %w", err)
+		}
+		query.Filter.Active = &active
+	}
+
+	query.Filter.EmailDomain = strings.TrimPrefix(strings.TrimSpace(c.Query("email_domain")), "@")
+	query.Filter.Search = strings.TrimSpace(c.Query("q"))
+
+	if afterStr := c.Query("created_after"); afterStr != "" {
+		after, err := parseTime(afterStr)
+		if err != nil {
+			return query, fmt.Errorf("created_after: %w", err)
+		}
+		query.Filter.CreatedAfter = &after
+	}
+
+	if beforeStr := c.Query("created_before"); beforeStr != "" {
+		before, err := parseTime(beforeStr)
+		if err != nil {
+			return query, fmt.Errorf("created_before: %w", err)
+		}
+		query.Filter.CreatedBefore = &before
+	}
+
+	// Sort fields are comma separated, a leading "-" means descending
+	if sortStr := c.Query("sort"); sortStr != "" {
+		for _, field := range strings.Split(sortStr, ",") {
+			field = strings.TrimSpace(field)
+			desc := strings.HasPrefix(field, "-")
+			field = strings.TrimPrefix(field, "-")
+			if field == "" {
+				return query, fmt.Errorf("sort: empty field")
+			}
+			query.Sort = append(query.Sort, interfaces.SortField{Field: field, Desc: desc})
+		}
+	}
+
+	return query, nil
+}
+
+// parseTime accepts RFC 3339 timestamps or plain dates
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected RFC 3339 timestamp or YYYY-MM-DD date")
+	}
+	return t, nil
 }
 
 // UpdateUser handles PUT /users/:id
-func (ctrl *UserController) UpdateUser(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 32)
+func (ctrl *UserController) UpdateUser(c *gin.Context) error {
+	id, err := userID(c)
+	if err != nil {
+		return err
+	}
+
+	expectedVersion, err := requiredVersion(c)
 	if err != nil {
-		response.BadRequest(c, "Invalid user ID", err.Error())
-		return
+		return err
 	}
 
 	var user entity.User
 	if err := c.ShouldBindJSON(&user); err != nil {
-		response.BadRequest(c, "Invalid request body", err.Error())
-		return
-	}
-
-	if err := ctrl.userUseCase.UpdateUser(c.Request.Context(), uint(id), &user); err != nil {
-		switch {
-		case errors.Is(err, entity.ErrUserNotFound):
-			response.NotFound(c, "User not found")
-		case errors.Is(err, entity.ErrInvalidUserID), errors.Is(err, entity.ErrInvalidUserName), errors.Is(err, entity.ErrInvalidUserEmail):
-			response.BadRequest(c, "Invalid user data", err.Error())
-		default:
-			response.InternalError(c, "Failed to update user", err.Error())
+		return badRequest("Invalid request body", err)
+	}
+
+	if err := ctrl.userUseCase.UpdateUser(c.Request.Context(), id, &user, expectedVersion); err != nil {
+		return err
+	}
+
+	c.Header("ETag", userETag(&user))
+	response.Success(c, "User updated successfully", user)
+	return nil
+}
+
+// PatchUser handles PATCH /users/:id
+func (ctrl *UserController) PatchUser(c *gin.Context) error {
+	id, err := userID(c)
+	if err != nil {
+		return err
+	}
+
+	expectedVersion, err := requiredVersion(c)
+	if err != nil {
+		return err
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return badRequest("Invalid request body", err)
+	}
+
+	var patch *entity.UserPatch
+	switch c.ContentType() {
+	case mergePatchMediaType, binding.MIMEJSON:
+		patch, err = decodeMergePatch(body)
+		if err != nil {
+			return badRequest("Invalid merge patch", err)
+		}
+	case jsonPatchMediaType:
+		current, err := ctrl.userUseCase.GetUser(c.Request.Context(), id)
+		if err != nil {
+			return err
+		}
+
+		patch, err = applyJSONPatch(current, body)
+		if err != nil {
+			return unprocessableEntity("Failed to apply JSON patch", err)
+		}
+
+		// The patch was evaluated against this version, so it must still be current
+		if expectedVersion == 0 {
+			expectedVersion = current.Version
 		}
-		return
+	default:
+		c.Header("Accept-Patch", acceptPatch)
+		return unsupportedMediaType("Unsupported patch format")
 	}
 
+	user, err := ctrl.userUseCase.PatchUser(c.Request.Context(), id, patch, expectedVersion)
+	if err != nil {
+		return err
+	}
+
+	c.Header("ETag", userETag(user))
 	response.Success(c, "User updated successfully", user)
+	return nil
 }
 
 // DeleteUser handles DELETE /users/:id
-func (ctrl *UserController) DeleteUser(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 32)
-	if err != nil {
-		response.BadRequest(c, "Invalid user ID", err.Error())
-		return
-	}
-
-	if err := ctrl.userUseCase.DeleteUser(c.Request.Context(), uint(id)); err != nil {
-		switch {
-		case errors.Is(err, entity.ErrUserNotFound):
-			response.NotFound(c, "User not found")
-		case errors.Is(err, entity.ErrInvalidUserID):
-			response.BadRequest(c, "Invalid user ID", err.Error())
-		default:
-			response.InternalError(c, "Failed to delete user", err.Error())
-		}
-		return
+func (ctrl *UserController) DeleteUser(c *gin.Context) error {
+	id, err := userID(c)
+	if err != nil {
+		return err
+	}
+
+	expectedVersion, err := requiredVersion(c)
+	if err != nil {
+		return err
+	}
+
+	if err := ctrl.userUseCase.DeleteUser(c.Request.Context(), id, expectedVersion); err != nil {
+		return err
 	}
 
 	response.Success(c, "User deleted successfully", nil)
+	return nil
 }
 
 // ActivateUser handles PUT /users/:id/activate
-func (ctrl *UserController) ActivateUser(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 32)
+func (ctrl *UserController) ActivateUser(c *gin.Context) error {
+	id, err := userID(c)
 	if err != nil {
-		response.BadRequest(c, "Invalid user ID", err.Error())
-		return
+		return err
 	}
 
-	if err := ctrl.userUseCase.ActivateUser(c.Request.Context(), uint(id)); err != nil {
-		switch {
-		case errors.Is(err, entity.ErrUserNotFound):
-			response.NotFound(c, "User not found")
-		default:
-			response.InternalError(c, "Failed to activate user", err.Error())
-		}
-		return
+	expectedVersion, err := requiredVersion(c)
+	if err != nil {
+		return err
+	}
+
+	if err := ctrl.userUseCase.ActivateUser(c.Request.Context(), id, expectedVersion); err != nil {
+		return err
 	}
 
 	response.Success(c, "User activated successfully", nil)
+	return nil
 }
 
 // DeactivateUser handles PUT /users/:id/deactivate
-func (ctrl *UserController) DeactivateUser(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 32)
+func (ctrl *UserController) DeactivateUser(c *gin.Context) error {
+	id, err := userID(c)
 	if err != nil {
-		response.BadRequest(c, "Invalid user ID", err.Error())
-		return
+		return err
 	}
 
-	if err := ctrl.userUseCase.DeactivateUser(c.Request.Context(), uint(id)); err != nil {
-		switch {
-		case errors.Is(err, entity.ErrUserNotFound):
-			response.NotFound(c, "User not found")
-		default:
-			response.InternalError(c, "Failed to deactivate user", err.Error())
-		}
-		return
+	expectedVersion, err := requiredVersion(c)
+	if err != nil {
+		return err
+	}
+
+	if err := ctrl.userUseCase.DeactivateUser(c.Request.Context(), id, expectedVersion); err != nil {
+		return err
 	}
 
 	response.Success(c, "User deactivated successfully", nil)
+	return nil
+}
+
+// userID parses the :id path parameter
+func userID(c *gin.Context) (uint, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return 0, entity.ErrInvalidUserID.Wrap(err)
+	}
+	return uint(id), nil
 }
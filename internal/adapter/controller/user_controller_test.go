@@ -0,0 +1,234 @@
+package controller
+
+import (
+	"context"
+	"go-clean-architecture/internal/adapter/repository"
+	"go-clean-architecture/internal/entity"
+	"go-clean-architecture/internal/infrastructure/auth"
+	"go-clean-architecture/internal/infrastructure/metrics"
+	"go-clean-architecture/internal/usecase"
+	"go-clean-architecture/internal/usecase/interfaces"
+	"go-clean-architecture/pkg/cursor"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// racingRepository runs a concurrent write once, right before the first
+// Update or Delete, as if another client won the race between the version
+// check and the write
+type racingRepository struct {
+	interfaces.UserRepository
+	race func()
+}
+
+func (r *racingRepository) Update(ctx context.Context, user *entity.User) error {
+	r.runRace()
+	return r.UserRepository.Update(ctx, user)
+}
+
+func (r *racingRepository) Delete(ctx context.Context, id, version uint) error {
+	r.runRace()
+	return r.UserRepository.Delete(ctx, id, version)
+}
+
+func (r *racingRepository) runRace() {
+	if race := r.race; race != nil {
+		r.race = nil
+		race()
+	}
+}
+
+// newUserRouter serves the user routes for an admin over repo
+func newUserRouter(repo interfaces.UserRepository) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	uc := usecase.NewUserUseCase(repo, repository.NewMemoryOutboxRepository(), repository.NewMemoryTxManager(), auth.NewBcryptHasher(0), metrics.NewMetrics())
+	ctrl := NewUserController(uc, cursor.NewCodec([]byte("0123456789abcdef0123456789abcdef")))
+
+	router := gin.New()
+	router.Use(ErrorHandler(), func(c *gin.Context) {
+		admin := &entity.User{ID: 1000, Role: entity.RoleAdmin}
+		c.Request = c.Request.WithContext(usecase.ContextWithUser(c.Request.Context(), admin))
+	})
+	router.GET("/users/:id", Handle(ctrl.GetUser))
+	router.PUT("/users/:id", Handle(ctrl.UpdateUser))
+	router.PATCH("/users/:id", Handle(ctrl.PatchUser))
+	router.DELETE("/users/:id", Handle(ctrl.DeleteUser))
+	return router
+}
+
+// serve sends a request with an optional JSON body and headers
+func serve(router *gin.Engine, method, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	for name, value := range headers {
+		r.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	return w
+}
+
+// seedUser stores a user at version 1 and returns it
+func seedUser(t *testing.T, repo interfaces.UserRepository) *entity.User {
+	t.Helper()
+	user := &entity.User{Name: "Alice", Email: "alice@example.com"}
+	if err := repo.Create(context.Background(), user); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if user.Version != 1 {
+		t.Fatalf("Create() version = %d, want 1", user.Version)
+	}
+	return user
+}
+
+func TestUserControllerConditionalGet(t *testing.T) {
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		want        int
+	}{
+		{name: "no precondition", want: http.StatusOK},
+		{name: "current tag", ifNoneMatch: `"1"`, want: http.StatusNotModified},
+		{name: "weak current tag", ifNoneMatch: `W/"1"`, want: http.StatusNotModified},
+		{name: "current tag in a list", ifNoneMatch: `"7", "1"`, want: http.StatusNotModified},
+		{name: "any tag", ifNoneMatch: `*`, want: http.StatusNotModified},
+		{name: "stale tag", ifNoneMatch: `"2"`, want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := repository.NewMemoryUserRepository()
+			seedUser(t, repo)
+			router := newUserRouter(repo)
+
+			w := serve(router, http.MethodGet, "/users/1", "", map[string]string{"If-None-Match": tt.ifNoneMatch})
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+			if got := w.Header().Get("ETag"); got != `"1"` {
+				t.Errorf("ETag = %q, want %q", got, `"1"`)
+			}
+			if tt.want == http.StatusNotModified && w.Body.Len() != 0 {
+				t.Errorf("304 body = %s, want none", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestUserControllerIfMatch(t *testing.T) {
+	requests := map[string]struct {
+		method string
+		body   string
+	}{
+		"PUT":    {method: http.MethodPut, body: `{"name": "Alice B", "email": "alice@example.com"}`},
+		"PATCH":  {method: http.MethodPatch, body: `{"name": "Alice B"}`},
+		"DELETE": {method: http.MethodDelete},
+	}
+	tests := []struct {
+		name    string
+		ifMatch string
+		want    int
+	}{
+		{name: "current version", ifMatch: `"1"`, want: http.StatusOK},
+		{name: "any version", ifMatch: `*`, want: http.StatusOK},
+		{name: "stale version", ifMatch: `"2"`, want: http.StatusPreconditionFailed},
+		{name: "unquoted", ifMatch: `1`, want: http.StatusPreconditionFailed},
+		{name: "weak tag", ifMatch: `W/"1"`, want: http.StatusPreconditionFailed},
+		{name: "not a version", ifMatch: `"abc"`, want: http.StatusPreconditionFailed},
+		{name: "version zero", ifMatch: `"0"`, want: http.StatusPreconditionFailed},
+		{name: "list of tags", ifMatch: `"1", "2"`, want: http.StatusPreconditionFailed},
+	}
+
+	for method, req := range requests {
+		for _, tt := range tests {
+			t.Run(method+" "+tt.name, func(t *testing.T) {
+				repo := repository.NewMemoryUserRepository()
+				seedUser(t, repo)
+				router := newUserRouter(repo)
+
+				w := serve(router, req.method, "/users/1", req.body, map[string]string{"If-Match": tt.ifMatch})
+				if w.Code != tt.want {
+					t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+				}
+				if tt.want == http.StatusPreconditionFailed {
+					if !strings.Contains(w.Body.String(), "precondition_failed") && !strings.Contains(w.Body.String(), "version_conflict") {
+						t.Errorf("body = %s, want a precondition error", w.Body.String())
+					}
+					if user, err := repo.GetByID(context.Background(), 1); err != nil || user.Version != 1 {
+						t.Errorf("user after a failed precondition = %+v, %v, want it unchanged", user, err)
+					}
+					return
+				}
+				if req.method != http.MethodDelete {
+					if got := w.Header().Get("ETag"); got != `"2"` {
+						t.Errorf("ETag = %q, want %q", got, `"2"`)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestUserControllerLostUpdateWithoutIfMatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		race   func(repo interfaces.UserRepository, user *entity.User) error
+		want   int
+	}{
+		{
+			name:   "PUT racing a PUT",
+			method: http.MethodPut,
+			body:   `{"name": "Alice B", "email": "alice@example.com"}`,
+			race: func(repo interfaces.UserRepository, user *entity.User) error {
+				user.Name = "Alice C"
+				return repo.Update(context.Background(), user)
+			},
+			want: http.StatusConflict,
+		},
+		{
+			name:   "DELETE racing a PUT",
+			method: http.MethodDelete,
+			race: func(repo interfaces.UserRepository, user *entity.User) error {
+				user.Name = "Alice C"
+				return repo.Update(context.Background(), user)
+			},
+			want: http.StatusConflict,
+		},
+		{
+			name:   "PUT racing a DELETE",
+			method: http.MethodPut,
+			body:   `{"name": "Alice B", "email": "alice@example.com"}`,
+			race: func(repo interfaces.UserRepository, user *entity.User) error {
+				return repo.Delete(context.Background(), user.ID, user.Version)
+			},
+			want: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memory := repository.NewMemoryUserRepository()
+			user := seedUser(t, memory)
+			repo := &racingRepository{UserRepository: memory}
+			repo.race = func() {
+				if err := tt.race(memory, user); err != nil {
+					t.Errorf("concurrent write error = %v", err)
+				}
+			}
+			router := newUserRouter(repo)
+
+			w := serve(router, tt.method, "/users/1", tt.body, nil)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go-clean-architecture/internal/entity"
+	"go-clean-architecture/pkg/response"
+	"sort"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// Media types accepted by PATCH /users/:id
+const (
+	mergePatchMediaType = "application/merge-patch+json"
+	jsonPatchMediaType  = "application/json-patch+json"
+)
+
+// acceptPatch lists the supported patch formats for the Accept-Patch header
+const acceptPatch = mergePatchMediaType + ", " + jsonPatchMediaType
+
+// decodeMergePatch converts a JSON Merge Patch (RFC 7396) document into a user patch
+func decodeMergePatch(doc []byte) (*entity.UserPatch, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(doc, &fields); err != nil || fields == nil {
+		return nil, fmt.Errorf("merge patch must be a JSON object")
+	}
+
+	patch := &entity.UserPatch{}
+	var fieldErrors response.FieldErrors
+	for field, raw := range fields {
+		isNull := bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+
+		var target interface{}
+		switch field {
+		case "name":
+			target = &patch.Name
+		case "email":
+			target = &patch.Email
+		case "phone":
+			// Removing the phone clears it
+			phone := ""
+			patch.Phone = &phone
+			if isNull {
+				continue
+			}
+			target = patch.Phone
+		case "active":
+			target = &patch.Active
+		case "role":
+			target = &patch.Role
+		case "password":
+			target = &patch.Password
+		default:
+			fieldErrors = append(fieldErrors, response.FieldError{Field: field, Rule: "unknown", Message: "cannot be patched"})
+			continue
+		}
+
+		if isNull {
+			fieldErrors = append(fieldErrors, response.FieldError{Field: field, Rule: "required", Message: "cannot be removed"})
+			continue
+		}
+		if err := json.Unmarshal(raw, target); err != nil {
+			fieldErrors = append(fieldErrors, response.FieldError{Field: field, Rule: "type", Message: "has an invalid type"})
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		// Map iteration order is random, keep the error list stable
+		sort.Slice(fieldErrors, func(i, j int) bool {
+			return fieldErrors[i].Field < fieldErrors[j].Field
+		})
+		return nil, fieldErrors
+	}
+	return patch, validatePatch(patch)
+}
+
+// applyJSONPatch runs a JSON Patch (RFC 6902) against the current user and
+// returns the resulting changes as a user patch
+func applyJSONPatch(current *entity.User, doc []byte) (*entity.UserPatch, error) {
+	operations, err := jsonpatch.DecodePatch(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	original, err := json.Marshal(current)
+	if err != nil {
+		return nil, err
+	}
+
+	patched, err := operations.Apply(original)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reduce the result to the fields that actually changed
+	changes, err := jsonpatch.CreateMergePatch(original, patched)
+	if err != nil {
+		return nil, err
+	}
+	return decodeMergePatch(changes)
+}
+
+// userPatchRules mirrors the binding rules of entity.User for patched fields
+type userPatchRules struct {
+	Name     *string `json:"name" binding:"omitnil,required"`
+	Email    *string `json:"email" binding:"omitnil,required,email"`
+	Role     *string `json:"role" binding:"omitnil,required,oneof=admin operator self"`
+	Password *string `json:"password" binding:"omitnil,required,min=8,max=72"`
+}
+
+// validatePatch applies the same binding rules as entity.User to the patched fields
+func validatePatch(patch *entity.UserPatch) error {
+	return binding.Validator.ValidateStruct(userPatchRules{
+		Name:     patch.Name,
+		Email:    patch.Email,
+		Role:     patch.Role,
+		Password: patch.Password,
+	})
+}
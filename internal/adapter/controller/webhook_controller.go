@@ -0,0 +1,166 @@
+package controller
+
+import (
+	"go-clean-architecture/internal/entity"
+	"go-clean-architecture/internal/usecase"
+	"go-clean-architecture/pkg/response"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// webhookRequest is the body of webhook create and update requests
+type webhookRequest struct {
+	URL    string   `json:"url" binding:"required"`
+	Events []string `json:"events" binding:"required,min=1"`
+	// Secret is generated on create when omitted and kept on update
+	Secret string `json:"secret" binding:"omitempty,min=16,max=255"`
+	Active *bool  `json:"active"`
+}
+
+// webhook converts the request to an entity, defaulting to active
+func (r *webhookRequest) webhook() *entity.Webhook {
+	active := r.Active == nil || *r.Active
+	return &entity.Webhook{
+		URL:    r.URL,
+		Events: r.Events,
+		Secret: r.Secret,
+		Active: active,
+	}
+}
+
+// WebhookController handles HTTP requests for webhook subscriptions
+type WebhookController struct {
+	webhookUseCase *usecase.WebhookUseCase
+}
+
+// NewWebhookController creates a new webhook controller instance
+func NewWebhookController(webhookUseCase *usecase.WebhookUseCase) *WebhookController {
+	return &WebhookController{
+		webhookUseCase: webhookUseCase,
+	}
+}
+
+// CreateWebhook handles POST /webhooks. The response is the only one that
+// includes the signing secret.
+func (ctrl *WebhookController) CreateWebhook(c *gin.Context) error {
+	var req webhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return badRequest("Invalid request body", err)
+	}
+
+	webhook := req.webhook()
+	if err := ctrl.webhookUseCase.CreateWebhook(c.Request.Context(), webhook); err != nil {
+		return err
+	}
+
+	response.Created(c, "Webhook created successfully", webhook)
+	return nil
+}
+
+// GetWebhook handles GET /webhooks/:id
+func (ctrl *WebhookController) GetWebhook(c *gin.Context) error {
+	id, err := webhookID(c)
+	if err != nil {
+		return err
+	}
+
+	webhook, err := ctrl.webhookUseCase.GetWebhook(c.Request.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	response.Success(c, "Webhook retrieved successfully", withoutSecret(webhook))
+	return nil
+}
+
+// GetAllWebhooks handles GET /webhooks
+func (ctrl *WebhookController) GetAllWebhooks(c *gin.Context) error {
+	webhooks, err := ctrl.webhookUseCase.ListWebhooks(c.Request.Context())
+	if err != nil {
+		return err
+	}
+
+	for _, webhook := range webhooks {
+		withoutSecret(webhook)
+	}
+	response.Success(c, "Webhooks retrieved successfully", webhooks)
+	return nil
+}
+
+// UpdateWebhook handles PUT /webhooks/:id
+func (ctrl *WebhookController) UpdateWebhook(c *gin.Context) error {
+	id, err := webhookID(c)
+	if err != nil {
+		return err
+	}
+
+	var req webhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return badRequest("Invalid request body", err)
+	}
+
+	webhook := req.webhook()
+	if err := ctrl.webhookUseCase.UpdateWebhook(c.Request.Context(), id, webhook); err != nil {
+		return err
+	}
+
+	response.Success(c, "Webhook updated successfully", withoutSecret(webhook))
+	return nil
+}
+
+// DeleteWebhook handles DELETE /webhooks/:id
+func (ctrl *WebhookController) DeleteWebhook(c *gin.Context) error {
+	id, err := webhookID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := ctrl.webhookUseCase.DeleteWebhook(c.Request.Context(), id); err != nil {
+		return err
+	}
+
+	response.Success(c, "Webhook deleted successfully", nil)
+	return nil
+}
+
+// GetDeliveries handles GET /webhooks/:id/deliveries
+func (ctrl *WebhookController) GetDeliveries(c *gin.Context) error {
+	id, err := webhookID(c)
+	if err != nil {
+		return err
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+
+	deliveries, total, err := ctrl.webhookUseCase.ListDeliveries(c.Request.Context(), id, page, pageSize)
+	if err != nil {
+		return err
+	}
+
+	response.Paginated(c, "Webhook deliveries retrieved successfully", deliveries, total, page, pageSize)
+	return nil
+}
+
+// webhookID parses the :id path parameter
+func webhookID(c *gin.Context) (uint, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return 0, entity.ErrInvalidWebhookID.Wrap(err)
+	}
+	return uint(id), nil
+}
+
+// withoutSecret clears the signing secret before a webhook is returned
+func withoutSecret(webhook *entity.Webhook) *entity.Webhook {
+	webhook.Secret = ""
+	return webhook
+}
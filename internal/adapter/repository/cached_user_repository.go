@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"go-clean-architecture/internal/entity"
+	"go-clean-architecture/internal/usecase/interfaces"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// cacheEntry is the encoded form of a cached lookup. Email keys only hold
+// the user ID so a user is cached once and invalidated through its ID key.
+type cacheEntry struct {
+	Found bool
+	ID    uint
+	User  *entity.User
+}
+
+// cachedUserRepository decorates a UserRepository with a read-through cache.
+// Lookups by ID and email are cached, including not-found results, and
+// concurrent misses for the same key share a single repository call.
+// Inside a transaction reads bypass the cache and invalidation waits for
+// the commit, so the cache never holds uncommitted data.
+type cachedUserRepository struct {
+	next        interfaces.UserRepository
+	cache       interfaces.Cache
+	ttl         time.Duration
+	negativeTTL time.Duration
+	group       singleflight.Group
+}
+
+// NewCachedUserRepository creates a new caching user repository instance.
+// A negativeTTL of zero disables caching of not-found results.
+func NewCachedUserRepository(next interfaces.UserRepository, cache interfaces.Cache, ttl, negativeTTL time.Duration) interfaces.UserRepository {
+	return &cachedUserRepository{
+		next:        next,
+		cache:       cache,
+		ttl:         ttl,
+		negativeTTL: negativeTTL,
+	}
+}
+
+// Create stores a new user and drops a cached not-found for its email
+func (r *cachedUserRepository) Create(ctx context.Context, user *entity.User) error {
+	if err := r.next.Create(ctx, user); err != nil {
+		return err
+	}
+	r.invalidate(ctx, idKey(user.ID), emailKey(user.Email))
+	return nil
+}
+
+// GetByID retrieves a user by ID through the cache
+func (r *cachedUserRepository) GetByID(ctx context.Context, id uint) (*entity.User, error) {
+	if _, ok := txFromContext(ctx); ok {
+		return r.next.GetByID(ctx, id)
+	}
+
+	key := idKey(id)
+	if entry, ok := r.lookup(ctx, key); ok {
+		if !entry.Found {
+			return nil, entity.ErrUserNotFound
+		}
+		return entry.User, nil
+	}
+
+	result, err, _ := r.group.Do(key, func() (any, error) {
+		user, err := r.next.GetByID(context.WithoutCancel(ctx), id)
+		switch {
+		case err == nil:
+			r.store(ctx, key, cacheEntry{Found: true, ID: user.ID, User: user}, r.ttl)
+		case errors.Is(err, entity.ErrUserNotFound):
+			r.store(ctx, key, cacheEntry{}, r.negativeTTL)
+		}
+		return user, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cloneUser(result.(*entity.User)), nil
+}
+
+// GetByEmail retrieves a user by email through the cache
+func (r *cachedUserRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
+	if _, ok := txFromContext(ctx); ok {
+		return r.next.GetByEmail(ctx, email)
+	}
+
+	key := emailKey(email)
+	if entry, ok := r.lookup(ctx, key); ok {
+		if !entry.Found {
+			return nil, entity.ErrUserNotFound
+		}
+		// The mapping may be stale when the user changed email or was deleted
+		user, err := r.GetByID(ctx, entry.ID)
+		if err == nil && user.Email == email {
+			return user, nil
+		}
+		if err != nil && !errors.Is(err, entity.ErrUserNotFound) {
+			return nil, err
+		}
+		r.invalidate(ctx, key)
+	}
+
+	result, err, _ := r.group.Do(key, func() (any, error) {
+		user, err := r.next.GetByEmail(context.WithoutCancel(ctx), email)
+		switch {
+		case err == nil:
+			r.store(ctx, idKey(user.ID), cacheEntry{Found: true, ID: user.ID, User: user}, r.ttl)
+			r.store(ctx, key, cacheEntry{Found: true, ID: user.ID}, r.ttl)
+		case errors.Is(err, entity.ErrUserNotFound):
+			r.store(ctx, key, cacheEntry{}, r.negativeTTL)
+		}
+		return user, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cloneUser(result.(*entity.User)), nil
+}
+
+// GetAll is not cached
+func (r *cachedUserRepository) GetAll(ctx context.Context, query interfaces.UserQuery, limit, offset int) ([]*entity.User, error) {
+	return r.next.GetAll(ctx, query, limit, offset)
+}
+
+// GetAllByCursor is not cached
+func (r *cachedUserRepository) GetAllByCursor(ctx context.Context, filter interfaces.UserFilter, page interfaces.CursorPage) ([]*entity.User, error) {
+	return r.next.GetAllByCursor(ctx, filter, page)
+}
+
+// Update updates a user and invalidates its cache entries
+func (r *cachedUserRepository) Update(ctx context.Context, user *entity.User) error {
+	err := r.next.Update(ctx, user)
+	// A conflict means the cached copy may be outdated, so drop it either way
+	r.invalidate(ctx, idKey(user.ID), emailKey(user.Email))
+	return err
+}
+
+// Patch partially updates a user and invalidates its cache entries
+func (r *cachedUserRepository) Patch(ctx context.Context, id, version uint, patch *entity.UserPatch) error {
+	err := r.next.Patch(ctx, id, version, patch)
+	keys := []string{idKey(id)}
+	if patch.Email != nil {
+		keys = append(keys, emailKey(*patch.Email))
+	}
+	r.invalidate(ctx, keys...)
+	return err
+}
+
+// Delete soft deletes a user and invalidates its cache entry
+func (r *cachedUserRepository) Delete(ctx context.Context, id, version uint) error {
+	err := r.next.Delete(ctx, id, version)
+	r.invalidate(ctx, idKey(id))
+	return err
+}
+
+// Count is not cached
+func (r *cachedUserRepository) Count(ctx context.Context, filter interfaces.UserFilter) (int64, error) {
+	return r.next.Count(ctx, filter)
+}
+
+// lookup returns the cached entry for key. Cache failures are logged and
+// treated as misses so the repository keeps serving requests.
+func (r *cachedUserRepository) lookup(ctx context.Context, key string) (cacheEntry, bool) {
+	var entry cacheEntry
+	data, ok, err := r.cache.Get(ctx, key)
+	if err != nil {
+		slog.WarnContext(ctx, "User cache read failed", "key", key, "error", err)
+		return entry, false
+	}
+	if !ok {
+		return entry, false
+	}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&entry); err != nil {
+		slog.WarnContext(ctx, "User cache entry is corrupt", "key", key, "error", err)
+		r.invalidate(ctx, key)
+		return entry, false
+	}
+	return entry, true
+}
+
+// store writes an entry to the cache, skipping it when ttl is not positive
+func (r *cachedUserRepository) store(ctx context.Context, key string, entry cacheEntry, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+	// gob is used because PasswordHash is excluded from the JSON encoding
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(entry); err != nil {
+		slog.WarnContext(ctx, "User cache encode failed", "key", key, "error", err)
+		return
+	}
+	if err := r.cache.Set(context.WithoutCancel(ctx), key, buf.Bytes(), ttl); err != nil {
+		slog.WarnContext(ctx, "User cache write failed", "key", key, "error", err)
+	}
+}
+
+// invalidate removes keys from the cache once the transaction in ctx commits
+func (r *cachedUserRepository) invalidate(ctx context.Context, keys ...string) {
+	afterCommit(ctx, func() {
+		if err := r.cache.Delete(context.WithoutCancel(ctx), keys...); err != nil {
+			slog.WarnContext(ctx, "User cache invalidation failed", "keys", keys, "error", err)
+		}
+	})
+}
+
+// idKey returns the cache key of a user ID
+func idKey(id uint) string {
+	return "user:id:" + strconv.FormatUint(uint64(id), 10)
+}
+
+// emailKey returns the cache key of a user email
+func emailKey(email string) string {
+	return "user:email:" + email
+}
@@ -0,0 +1,248 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"go-clean-architecture/internal/entity"
+	"go-clean-architecture/internal/infrastructure/cache"
+	"go-clean-architecture/internal/testutil"
+	"go-clean-architecture/internal/usecase/interfaces"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const (
+	testTTL         = 50 * time.Millisecond
+	testNegativeTTL = 20 * time.Millisecond
+)
+
+// clockedCache is a cache with a function advancing its clock
+type clockedCache struct {
+	cache   interfaces.Cache
+	advance func(time.Duration)
+}
+
+// cacheBackends lists the in-process LRU and Redis, stood in for by miniredis
+var cacheBackends = []testutil.Backend[clockedCache]{
+	{Name: "lru", New: func(t *testing.T) clockedCache {
+		c, err := cache.NewLRUCache(100)
+		if err != nil {
+			t.Fatalf("NewLRUCache() error = %v", err)
+		}
+		return clockedCache{cache: c, advance: time.Sleep}
+	}},
+	{Name: "redis", New: func(t *testing.T) clockedCache {
+		client, server := testutil.NewRedis(t)
+		return clockedCache{cache: cache.NewRedisCache(client), advance: server.FastForward}
+	}},
+}
+
+// countingUserRepository counts lookups reaching the wrapped repository.
+// When release is set GetByID blocks until it is closed.
+type countingUserRepository struct {
+	interfaces.UserRepository
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (r *countingUserRepository) GetByID(ctx context.Context, id uint) (*entity.User, error) {
+	r.calls.Add(1)
+	if r.release != nil {
+		<-r.release
+	}
+	return r.UserRepository.GetByID(ctx, id)
+}
+
+func (r *countingUserRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
+	r.calls.Add(1)
+	return r.UserRepository.GetByEmail(ctx, email)
+}
+
+// newCachedRepository returns a cached repository over a memory repository
+// holding alice, the counting layer between them and the cache clock
+func newCachedRepository(t *testing.T, backend testutil.Backend[clockedCache], ttl time.Duration) (interfaces.UserRepository, *countingUserRepository, func(time.Duration)) {
+	t.Helper()
+	counting := &countingUserRepository{UserRepository: NewMemoryUserRepository()}
+	seedUsers(t, counting, &entity.User{Name: "Alice", Email: "alice@example.com"})
+	c := backend.New(t)
+	return NewCachedUserRepository(counting, c.cache, ttl, testNegativeTTL), counting, c.advance
+}
+
+func TestCachedUserRepositoryReadThrough(t *testing.T) {
+	testutil.ForEachBackend(t, cacheBackends, func(t *testing.T, backend testutil.Backend[clockedCache]) {
+		ctx := context.Background()
+		repo, counting, _ := newCachedRepository(t, backend, testTTL)
+
+		for i := 0; i < 3; i++ {
+			user, err := repo.GetByID(ctx, 1)
+			if err != nil {
+				t.Fatalf("GetByID() error = %v", err)
+			}
+			if user.Email != "alice@example.com" {
+				t.Fatalf("GetByID() email = %q", user.Email)
+			}
+		}
+		// The email lookup reuses the user cached by ID
+		if _, err := repo.GetByEmail(ctx, "alice@example.com"); err != nil {
+			t.Fatalf("GetByEmail() error = %v", err)
+		}
+		if _, err := repo.GetByEmail(ctx, "alice@example.com"); err != nil {
+			t.Fatalf("GetByEmail() error = %v", err)
+		}
+		if got := counting.calls.Load(); got != 2 {
+			t.Errorf("repository calls = %d, want 2", got)
+		}
+	})
+}
+
+func TestCachedUserRepositoryTTL(t *testing.T) {
+	testutil.ForEachBackend(t, cacheBackends, func(t *testing.T, backend testutil.Backend[clockedCache]) {
+		ctx := context.Background()
+		repo, counting, advance := newCachedRepository(t, backend, testTTL)
+
+		if _, err := repo.GetByID(ctx, 1); err != nil {
+			t.Fatalf("GetByID() error = %v", err)
+		}
+		advance(testTTL + 10*time.Millisecond)
+		if _, err := repo.GetByID(ctx, 1); err != nil {
+			t.Fatalf("GetByID() error = %v", err)
+		}
+		if got := counting.calls.Load(); got != 2 {
+			t.Errorf("repository calls = %d, want 2 after expiry", got)
+		}
+	})
+}
+
+func TestCachedUserRepositoryInvalidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		write   func(ctx context.Context, repo interfaces.UserRepository) error
+		want    string
+		wantErr error
+	}{
+		{
+			name: "update",
+			write: func(ctx context.Context, repo interfaces.UserRepository) error {
+				return repo.Update(ctx, &entity.User{ID: 1, Name: "Alicia", Email: "alice@example.com", Version: 1})
+			},
+			want: "Alicia",
+		},
+		{
+			name: "patch",
+			write: func(ctx context.Context, repo interfaces.UserRepository) error {
+				name := "Alicia"
+				return repo.Patch(ctx, 1, 1, &entity.UserPatch{Name: &name})
+			},
+			want: "Alicia",
+		},
+		{
+			name: "delete",
+			write: func(ctx context.Context, repo interfaces.UserRepository) error {
+				return repo.Delete(ctx, 1, 1)
+			},
+			wantErr: entity.ErrUserNotFound,
+		},
+	}
+
+	testutil.ForEachBackend(t, cacheBackends, func(t *testing.T, backend testutil.Backend[clockedCache]) {
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				ctx := context.Background()
+				repo, _, _ := newCachedRepository(t, backend, time.Hour)
+
+				if _, err := repo.GetByID(ctx, 1); err != nil {
+					t.Fatalf("GetByID() error = %v", err)
+				}
+				if _, err := repo.GetByEmail(ctx, "alice@example.com"); err != nil {
+					t.Fatalf("GetByEmail() error = %v", err)
+				}
+				if err := tt.write(ctx, repo); err != nil {
+					t.Fatalf("write error = %v", err)
+				}
+
+				byID, err := repo.GetByID(ctx, 1)
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("GetByID() error = %v, want %v", err, tt.wantErr)
+				}
+				byEmail, emailErr := repo.GetByEmail(ctx, "alice@example.com")
+				if !errors.Is(emailErr, tt.wantErr) {
+					t.Fatalf("GetByEmail() error = %v, want %v", emailErr, tt.wantErr)
+				}
+				if err == nil && (byID.Name != tt.want || byEmail.Name != tt.want) {
+					t.Errorf("names = %q, %q, want %q", byID.Name, byEmail.Name, tt.want)
+				}
+			})
+		}
+	})
+}
+
+func TestCachedUserRepositoryNegativeCaching(t *testing.T) {
+	testutil.ForEachBackend(t, cacheBackends, func(t *testing.T, backend testutil.Backend[clockedCache]) {
+		ctx := context.Background()
+		repo, counting, advance := newCachedRepository(t, backend, time.Hour)
+		const email = "bob@example.com"
+
+		for i := 0; i < 2; i++ {
+			if _, err := repo.GetByEmail(ctx, email); !errors.Is(err, entity.ErrUserNotFound) {
+				t.Fatalf("GetByEmail() error = %v, want %v", err, entity.ErrUserNotFound)
+			}
+		}
+		if got := counting.calls.Load(); got != 1 {
+			t.Errorf("repository calls = %d, want 1 while not-found is cached", got)
+		}
+
+		advance(testNegativeTTL + 10*time.Millisecond)
+		if _, err := repo.GetByEmail(ctx, email); !errors.Is(err, entity.ErrUserNotFound) {
+			t.Fatalf("GetByEmail() error = %v, want %v", err, entity.ErrUserNotFound)
+		}
+		if got := counting.calls.Load(); got != 2 {
+			t.Errorf("repository calls = %d, want 2 after the not-found expired", got)
+		}
+
+		// Creating the user drops the cached not-found
+		if err := repo.Create(ctx, &entity.User{Name: "Bob", Email: email}); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		if _, err := repo.GetByEmail(ctx, email); err != nil {
+			t.Errorf("GetByEmail() after create error = %v", err)
+		}
+	})
+}
+
+func TestCachedUserRepositorySingleflight(t *testing.T) {
+	testutil.ForEachBackend(t, cacheBackends, func(t *testing.T, backend testutil.Backend[clockedCache]) {
+		ctx := context.Background()
+		// Without storing results only the shared call can collapse lookups
+		repo, counting, _ := newCachedRepository(t, backend, 0)
+		counting.release = make(chan struct{})
+
+		const callers = 10
+		var wg sync.WaitGroup
+		errs := make(chan error, callers)
+		for i := 0; i < callers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := repo.GetByID(ctx, 1)
+				errs <- err
+			}()
+		}
+
+		// Give every caller time to join the call in flight
+		time.Sleep(50 * time.Millisecond)
+		close(counting.release)
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			if err != nil {
+				t.Fatalf("GetByID() error = %v", err)
+			}
+		}
+		if got := counting.calls.Load(); got != 1 {
+			t.Errorf("repository calls = %d, want 1", got)
+		}
+	})
+}
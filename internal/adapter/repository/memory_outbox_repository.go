@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go-clean-architecture/internal/entity"
+	"go-clean-architecture/internal/usecase/interfaces"
+	"sync"
+	"time"
+)
+
+// memoryOutboxMessage is an outbox message with its delivery state
+type memoryOutboxMessage struct {
+	interfaces.OutboxMessage
+	nextAttemptAt time.Time
+}
+
+// memoryOutboxRepository implements the OutboxRepository interface in memory.
+// It mirrors the ordering guarantees of the GORM-backed outboxRepository.
+type memoryOutboxRepository struct {
+	mu       sync.Mutex
+	messages []*memoryOutboxMessage
+	nextID   uint64
+}
+
+// NewMemoryOutboxRepository creates a new in-memory outbox repository instance
+func NewMemoryOutboxRepository() interfaces.OutboxRepository {
+	return &memoryOutboxRepository{nextID: 1}
+}
+
+// Add stores events in the outbox
+func (r *memoryOutboxRepository) Add(ctx context.Context, events ...entity.Event) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for _, event := range events {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s event: %w", event.EventType(), err)
+		}
+		r.messages = append(r.messages, &memoryOutboxMessage{
+			OutboxMessage: interfaces.OutboxMessage{
+				ID:          r.nextID,
+				EventType:   event.EventType(),
+				AggregateID: event.AggregateID(),
+				Payload:     payload,
+				OccurredAt:  now,
+			},
+			nextAttemptAt: now,
+		})
+		r.nextID++
+	}
+	return nil
+}
+
+// Pending returns the oldest due message of each aggregate
+func (r *memoryOutboxRepository) Pending(ctx context.Context, now time.Time, limit int) ([]*interfaces.OutboxMessage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var messages []*interfaces.OutboxMessage
+	seen := make(map[uint]bool)
+	for _, message := range r.messages {
+		if len(messages) == limit {
+			break
+		}
+		if seen[message.AggregateID] {
+			continue
+		}
+		// Later messages of the aggregate wait for this one even when it is not due
+		seen[message.AggregateID] = true
+		if message.nextAttemptAt.After(now) {
+			continue
+		}
+		clone := message.OutboxMessage
+		messages = append(messages, &clone)
+	}
+	return messages, nil
+}
+
+// MarkPublished records that a message was delivered and drops it
+func (r *memoryOutboxRepository) MarkPublished(ctx context.Context, id uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, message := range r.messages {
+		if message.ID == id {
+			// Nothing reads published messages back, so they are not kept
+			r.messages = append(r.messages[:i], r.messages[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+// MarkFailed records a failed attempt and schedules the next one
+func (r *memoryOutboxRepository) MarkFailed(ctx context.Context, id uint64, next time.Time, reason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, message := range r.messages {
+		if message.ID == id {
+			message.Attempts++
+			message.nextAttemptAt = next
+			return nil
+		}
+	}
+	return nil
+}
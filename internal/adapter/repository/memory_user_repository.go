@@ -0,0 +1,370 @@
+package repository
+
+import (
+	"context"
+	"go-clean-architecture/internal/entity"
+	"go-clean-architecture/internal/usecase/interfaces"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// memoryUserRepository implements the UserRepository interface in memory.
+// It mirrors the semantics of the GORM-backed userRepository so use cases
+// can be exercised without a database.
+type memoryUserRepository struct {
+	mu     sync.RWMutex
+	users  map[uint]*entity.User
+	nextID uint
+}
+
+// NewMemoryUserRepository creates a new in-memory user repository instance
+func NewMemoryUserRepository() interfaces.UserRepository {
+	return &memoryUserRepository{
+		users:  make(map[uint]*entity.User),
+		nextID: 1,
+	}
+}
+
+// Create stores a new user in memory
+func (r *memoryUserRepository) Create(ctx context.Context, user *entity.User) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// Run the same business hook GORM runs before insert
+	if err := user.BeforeCreate(nil); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// The unique index on email also covers soft deleted rows
+	if r.emailTaken(user.Email, 0) {
+		return entity.ErrUserAlreadyExists
+	}
+
+	now := time.Now()
+	user.ID = r.nextID
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = now
+	}
+	if user.UpdatedAt.IsZero() {
+		user.UpdatedAt = now
+	}
+	// GORM omits zero values for columns with a default, so false becomes true
+	if !user.Active {
+		user.Active = true
+	}
+
+	r.users[user.ID] = cloneUser(user)
+	r.nextID++
+	return nil
+}
+
+// GetByID retrieves a user by ID
+func (r *memoryUserRepository) GetByID(ctx context.Context, id uint) (*entity.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[id]
+	if !ok || user.DeletedAt.Valid {
+		return nil, entity.ErrUserNotFound
+	}
+	return cloneUser(user), nil
+}
+
+// GetByEmail retrieves a user by email
+func (r *memoryUserRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if user.Email == email && !user.DeletedAt.Valid {
+			return cloneUser(user), nil
+		}
+	}
+	return nil, entity.ErrUserNotFound
+}
+
+// GetAll retrieves users matching the query with pagination
+func (r *memoryUserRepository) GetAll(ctx context.Context, query interfaces.UserQuery, limit, offset int) ([]*entity.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := r.matchingUsers(query.Filter)
+	sortUsers(users, query.Sort)
+
+	return paginate(users, limit, offset), nil
+}
+
+// GetAllByCursor retrieves users adjacent to a keyset cursor, newest first
+func (r *memoryUserRepository) GetAllByCursor(ctx context.Context, filter interfaces.UserFilter, page interfaces.CursorPage) ([]*entity.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := r.matchingUsers(filter)
+	sortUsers(users, []interfaces.SortField{
+		{Field: interfaces.UserSortCreatedAt, Desc: true},
+		{Field: interfaces.UserSortID, Desc: true},
+	})
+
+	if page.Cursor == nil {
+		return paginate(users, page.Limit, 0), nil
+	}
+
+	// Index of the first row strictly after the cursor in display order
+	after := sort.Search(len(users), func(i int) bool {
+		return compareToCursor(users[i], page.Cursor) < 0
+	})
+
+	if !page.Backward {
+		return paginate(users[after:], page.Limit, 0), nil
+	}
+
+	// Rows strictly before the cursor, keeping the ones closest to it
+	before := after
+	for before > 0 && compareToCursor(users[before-1], page.Cursor) == 0 {
+		before--
+	}
+	start := before - page.Limit
+	if start < 0 {
+		start = 0
+	}
+	return users[start:before], nil
+}
+
+// Update overwrites an existing user if its version still matches
+func (r *memoryUserRepository) Update(ctx context.Context, user *entity.User) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.users[user.ID]
+	if !ok || existing.DeletedAt.Valid {
+		return entity.ErrUserNotFound
+	}
+	if existing.Version != user.Version {
+		return entity.ErrVersionConflict
+	}
+	if r.emailTaken(user.Email, user.ID) {
+		return entity.ErrUserAlreadyExists
+	}
+
+	// Every column except the immutable ones is overwritten
+	user.Version++
+	user.UpdatedAt = time.Now()
+	user.CreatedAt = existing.CreatedAt
+	user.DeletedAt = existing.DeletedAt
+	r.users[user.ID] = cloneUser(user)
+	return nil
+}
+
+// Patch updates only the fields set in the patch if the version still matches
+func (r *memoryUserRepository) Patch(ctx context.Context, id, version uint, patch *entity.UserPatch) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok || user.DeletedAt.Valid {
+		return entity.ErrUserNotFound
+	}
+	if user.Version != version {
+		return entity.ErrVersionConflict
+	}
+	if patch.Email != nil && r.emailTaken(*patch.Email, id) {
+		return entity.ErrUserAlreadyExists
+	}
+
+	patch.Apply(user)
+	user.Version++
+	user.UpdatedAt = time.Now()
+	return nil
+}
+
+// Delete soft deletes a user by ID if its version still matches
+func (r *memoryUserRepository) Delete(ctx context.Context, id, version uint) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok || user.DeletedAt.Valid {
+		return entity.ErrUserNotFound
+	}
+	if user.Version != version {
+		return entity.ErrVersionConflict
+	}
+
+	user.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	user.Version++
+	return nil
+}
+
+// Count returns the number of users matching the filter
+func (r *memoryUserRepository) Count(ctx context.Context, filter interfaces.UserFilter) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return int64(len(r.matchingUsers(filter))), nil
+}
+
+// matchingUsers returns copies of all users that are not soft deleted and
+// match the filter. Callers must hold the lock.
+func (r *memoryUserRepository) matchingUsers(filter interfaces.UserFilter) []*entity.User {
+	users := make([]*entity.User, 0, len(r.users))
+	for _, user := range r.users {
+		if !user.DeletedAt.Valid && matchesFilter(user, filter) {
+			users = append(users, cloneUser(user))
+		}
+	}
+	return users
+}
+
+// emailTaken reports whether another user, deleted or not, owns the email.
+// Callers must hold the lock.
+func (r *memoryUserRepository) emailTaken(email string, exceptID uint) bool {
+	for id, user := range r.users {
+		if id != exceptID && user.Email == email {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesFilter evaluates a filter the same way applyUserFilter does in SQL
+func matchesFilter(user *entity.User, filter interfaces.UserFilter) bool {
+	if filter.Active != nil && user.Active != *filter.Active {
+		return false
+	}
+	if filter.EmailDomain != "" && !strings.HasSuffix(strings.ToLower(user.Email), "@"+strings.ToLower(filter.EmailDomain)) {
+		return false
+	}
+	if filter.CreatedAfter != nil && !user.CreatedAt.After(*filter.CreatedAfter) {
+		return false
+	}
+	if filter.CreatedBefore != nil && !user.CreatedAt.Before(*filter.CreatedBefore) {
+		return false
+	}
+	if filter.Search != "" {
+		search := strings.ToLower(filter.Search)
+		if !strings.Contains(strings.ToLower(user.Name), search) &&
+			!strings.Contains(strings.ToLower(user.Email), search) &&
+			!strings.Contains(strings.ToLower(user.Phone), search) {
+			return false
+		}
+	}
+	return true
+}
+
+// sortUsers orders users by the given fields, defaulting to newest first
+func sortUsers(users []*entity.User, fields []interfaces.SortField) {
+	if len(fields) == 0 {
+		fields = []interfaces.SortField{{Field: interfaces.UserSortCreatedAt, Desc: true}}
+	}
+
+	sort.SliceStable(users, func(i, j int) bool {
+		for _, field := range fields {
+			cmp := compareUserField(users[i], users[j], field.Field)
+			if cmp == 0 {
+				continue
+			}
+			if field.Desc {
+				return cmp > 0
+			}
+			return cmp < 0
+		}
+		return users[i].ID > users[j].ID
+	})
+}
+
+// compareUserField compares a single sortable column of two users
+func compareUserField(a, b *entity.User, field string) int {
+	switch field {
+	case interfaces.UserSortID:
+		return cmpUint(a.ID, b.ID)
+	case interfaces.UserSortName:
+		return strings.Compare(a.Name, b.Name)
+	case interfaces.UserSortEmail:
+		return strings.Compare(a.Email, b.Email)
+	case interfaces.UserSortCreatedAt:
+		return a.CreatedAt.Compare(b.CreatedAt)
+	case interfaces.UserSortUpdatedAt:
+		return a.UpdatedAt.Compare(b.UpdatedAt)
+	}
+	return 0
+}
+
+// compareToCursor compares a user's keyset position with a cursor
+func compareToCursor(user *entity.User, cursor *interfaces.UserCursor) int {
+	if c := user.CreatedAt.Compare(cursor.CreatedAt); c != 0 {
+		return c
+	}
+	return cmpUint(user.ID, cursor.ID)
+}
+
+// cmpUint compares two unsigned integers
+func cmpUint(a, b uint) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// paginate applies limit and offset the way SQL LIMIT/OFFSET does
+func paginate[T any](items []T, limit, offset int) []T {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(items) {
+		return []T{}
+	}
+	items = items[offset:]
+	if limit >= 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
+
+// cloneUser returns a copy so callers cannot mutate stored state
+func cloneUser(user *entity.User) *entity.User {
+	clone := *user
+	return &clone
+}
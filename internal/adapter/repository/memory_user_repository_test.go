@@ -0,0 +1,331 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"go-clean-architecture/internal/entity"
+	"go-clean-architecture/internal/usecase/interfaces"
+	"slices"
+	"testing"
+	"time"
+)
+
+// seedUsers creates users whose creation times are one minute apart, in
+// the order given
+func seedUsers(t *testing.T, repo interfaces.UserRepository, users ...*entity.User) {
+	t.Helper()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, user := range users {
+		if user.CreatedAt.IsZero() {
+			user.CreatedAt = base.Add(time.Duration(i) * time.Minute)
+		}
+		if err := repo.Create(context.Background(), user); err != nil {
+			t.Fatalf("Create(%s) error = %v", user.Email, err)
+		}
+	}
+}
+
+// userIDs returns the IDs of users in order
+func userIDs(users []*entity.User) []uint {
+	ids := make([]uint, len(users))
+	for i, user := range users {
+		ids[i] = user.ID
+	}
+	return ids
+}
+
+func TestMemoryUserRepositoryCreate(t *testing.T) {
+	tests := []struct {
+		name    string
+		user    *entity.User
+		wantErr error
+	}{
+		{name: "valid", user: &entity.User{Name: "Carol", Email: "carol@example.com"}},
+		{name: "email taken", user: &entity.User{Name: "Alice 2", Email: "alice@example.com"}, wantErr: entity.ErrUserAlreadyExists},
+		{name: "email of deleted user", user: &entity.User{Name: "Bob 2", Email: "bob@example.com"}, wantErr: entity.ErrUserAlreadyExists},
+		{name: "missing name", user: &entity.User{Email: "dave@example.com"}, wantErr: entity.ErrInvalidUserName},
+		{name: "missing email", user: &entity.User{Name: "Dave"}, wantErr: entity.ErrInvalidUserEmail},
+		{name: "unknown role", user: &entity.User{Name: "Dave", Email: "dave@example.com", Role: "root"}, wantErr: entity.ErrInvalidUserRole},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := NewMemoryUserRepository()
+			bob := &entity.User{Name: "Bob", Email: "bob@example.com"}
+			seedUsers(t, repo, &entity.User{Name: "Alice", Email: "alice@example.com"}, bob)
+			if err := repo.Delete(ctx, bob.ID, bob.Version); err != nil {
+				t.Fatalf("Delete() error = %v", err)
+			}
+
+			err := repo.Create(ctx, tt.user)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Create() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			got, err := repo.GetByID(ctx, tt.user.ID)
+			if err != nil {
+				t.Fatalf("GetByID() error = %v", err)
+			}
+			if got.Version != 1 || !got.Active || got.Role != entity.RoleSelf {
+				t.Errorf("created user = version %d, active %t, role %q, want 1, true, %q",
+					got.Version, got.Active, got.Role, entity.RoleSelf)
+			}
+		})
+	}
+}
+
+func TestMemoryUserRepositorySoftDelete(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryUserRepository()
+	alice := &entity.User{Name: "Alice", Email: "alice@example.com"}
+	bob := &entity.User{Name: "Bob", Email: "bob@example.com"}
+	seedUsers(t, repo, alice, bob)
+
+	if err := repo.Delete(ctx, alice.ID, alice.Version); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	if _, err := repo.GetByID(ctx, alice.ID); !errors.Is(err, entity.ErrUserNotFound) {
+		t.Errorf("GetByID() error = %v, want %v", err, entity.ErrUserNotFound)
+	}
+	if _, err := repo.GetByEmail(ctx, alice.Email); !errors.Is(err, entity.ErrUserNotFound) {
+		t.Errorf("GetByEmail() error = %v, want %v", err, entity.ErrUserNotFound)
+	}
+	users, err := repo.GetAll(ctx, interfaces.UserQuery{}, 10, 0)
+	if err != nil {
+		t.Fatalf("GetAll() error = %v", err)
+	}
+	if got, want := userIDs(users), []uint{bob.ID}; !slices.Equal(got, want) {
+		t.Errorf("GetAll() ids = %v, want %v", got, want)
+	}
+	count, err := repo.Count(ctx, interfaces.UserFilter{})
+	if err != nil || count != 1 {
+		t.Errorf("Count() = %d, %v, want 1, nil", count, err)
+	}
+	if err := repo.Delete(ctx, alice.ID, alice.Version); !errors.Is(err, entity.ErrUserNotFound) {
+		t.Errorf("second Delete() error = %v, want %v", err, entity.ErrUserNotFound)
+	}
+}
+
+func TestMemoryUserRepositoryGetAll(t *testing.T) {
+	inactive := false
+	after := time.Date(2024, 1, 1, 0, 0, 30, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		query         interfaces.UserQuery
+		limit, offset int
+		want          []uint
+	}{
+		{name: "created_at DESC by default", limit: 10, want: []uint{4, 3, 2, 1}},
+		{name: "limit and offset", limit: 2, offset: 1, want: []uint{3, 2}},
+		{name: "offset past the end", limit: 2, offset: 9, want: []uint{}},
+		{
+			name:  "sort by name ascending",
+			query: interfaces.UserQuery{Sort: []interfaces.SortField{{Field: interfaces.UserSortName}}},
+			limit: 10,
+			want:  []uint{4, 1, 2, 3},
+		},
+		{
+			name:  "sort by email descending",
+			query: interfaces.UserQuery{Sort: []interfaces.SortField{{Field: interfaces.UserSortEmail, Desc: true}}},
+			limit: 10,
+			want:  []uint{3, 2, 1, 4},
+		},
+		{name: "active filter", query: interfaces.UserQuery{Filter: interfaces.UserFilter{Active: &inactive}}, limit: 10, want: []uint{3}},
+		{name: "email domain", query: interfaces.UserQuery{Filter: interfaces.UserFilter{EmailDomain: "Example.org"}}, limit: 10, want: []uint{3, 2}},
+		{name: "search is case insensitive", query: interfaces.UserQuery{Filter: interfaces.UserFilter{Search: "AL"}}, limit: 10, want: []uint{4, 1}},
+		{name: "created after", query: interfaces.UserQuery{Filter: interfaces.UserFilter{CreatedAfter: &after}}, limit: 10, want: []uint{4, 3, 2}},
+	}
+
+	ctx := context.Background()
+	repo := NewMemoryUserRepository()
+	seedUsers(t, repo,
+		&entity.User{Name: "Alice", Email: "alice@example.com"},
+		&entity.User{Name: "Bob", Email: "bob@example.org"},
+		&entity.User{Name: "Carol", Email: "carol@example.org"},
+		&entity.User{Name: "Al", Email: "al@example.com"},
+	)
+	carol, err := repo.GetByID(ctx, 3)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	carol.Deactivate()
+	if err := repo.Update(ctx, carol); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users, err := repo.GetAll(ctx, tt.query, tt.limit, tt.offset)
+			if err != nil {
+				t.Fatalf("GetAll() error = %v", err)
+			}
+			if got := userIDs(users); !slices.Equal(got, tt.want) {
+				t.Errorf("GetAll() ids = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryUserRepositoryGetAllByCursor(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryUserRepository()
+	seedUsers(t, repo,
+		&entity.User{Name: "A", Email: "a@example.com"},
+		&entity.User{Name: "B", Email: "b@example.com"},
+		&entity.User{Name: "C", Email: "c@example.com"},
+		&entity.User{Name: "D", Email: "d@example.com"},
+		&entity.User{Name: "E", Email: "e@example.com"},
+	)
+	at := func(id uint) *interfaces.UserCursor {
+		user, err := repo.GetByID(ctx, id)
+		if err != nil {
+			t.Fatalf("GetByID(%d) error = %v", id, err)
+		}
+		return &interfaces.UserCursor{CreatedAt: user.CreatedAt, ID: user.ID}
+	}
+
+	tests := []struct {
+		name string
+		page interfaces.CursorPage
+		want []uint
+	}{
+		{name: "first page", page: interfaces.CursorPage{Limit: 2}, want: []uint{5, 4}},
+		{name: "forward from cursor", page: interfaces.CursorPage{Cursor: at(4), Limit: 2}, want: []uint{3, 2}},
+		{name: "forward to the end", page: interfaces.CursorPage{Cursor: at(2), Limit: 2}, want: []uint{1}},
+		{name: "backward from cursor", page: interfaces.CursorPage{Cursor: at(2), Backward: true, Limit: 2}, want: []uint{4, 3}},
+		{name: "backward to the start", page: interfaces.CursorPage{Cursor: at(4), Backward: true, Limit: 2}, want: []uint{5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users, err := repo.GetAllByCursor(ctx, interfaces.UserFilter{}, tt.page)
+			if err != nil {
+				t.Fatalf("GetAllByCursor() error = %v", err)
+			}
+			if got := userIDs(users); !slices.Equal(got, tt.want) {
+				t.Errorf("GetAllByCursor() ids = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryUserRepositoryErrors(t *testing.T) {
+	name := "Renamed"
+	taken := "bob@example.com"
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name    string
+		op      func(ctx context.Context, repo interfaces.UserRepository) error
+		wantErr error
+	}{
+		{
+			name: "get unknown id",
+			op: func(ctx context.Context, repo interfaces.UserRepository) error {
+				_, err := repo.GetByID(ctx, 42)
+				return err
+			},
+			wantErr: entity.ErrUserNotFound,
+		},
+		{
+			name: "get unknown email",
+			op: func(ctx context.Context, repo interfaces.UserRepository) error {
+				_, err := repo.GetByEmail(ctx, "nobody@example.com")
+				return err
+			},
+			wantErr: entity.ErrUserNotFound,
+		},
+		{
+			name: "update stale version",
+			op: func(ctx context.Context, repo interfaces.UserRepository) error {
+				return repo.Update(ctx, &entity.User{ID: 1, Name: "Alice", Email: "alice@example.com", Version: 7})
+			},
+			wantErr: entity.ErrVersionConflict,
+		},
+		{
+			name: "update to taken email",
+			op: func(ctx context.Context, repo interfaces.UserRepository) error {
+				return repo.Update(ctx, &entity.User{ID: 1, Name: "Alice", Email: taken, Version: 1})
+			},
+			wantErr: entity.ErrUserAlreadyExists,
+		},
+		{
+			name: "update unknown id",
+			op: func(ctx context.Context, repo interfaces.UserRepository) error {
+				return repo.Update(ctx, &entity.User{ID: 42, Name: "X", Email: "x@example.com", Version: 1})
+			},
+			wantErr: entity.ErrUserNotFound,
+		},
+		{
+			name: "patch stale version",
+			op: func(ctx context.Context, repo interfaces.UserRepository) error {
+				return repo.Patch(ctx, 1, 7, &entity.UserPatch{Name: &name})
+			},
+			wantErr: entity.ErrVersionConflict,
+		},
+		{
+			name: "patch to taken email",
+			op: func(ctx context.Context, repo interfaces.UserRepository) error {
+				return repo.Patch(ctx, 1, 1, &entity.UserPatch{Email: &taken})
+			},
+			wantErr: entity.ErrUserAlreadyExists,
+		},
+		{
+			name: "delete stale version",
+			op: func(ctx context.Context, repo interfaces.UserRepository) error {
+				return repo.Delete(ctx, 1, 7)
+			},
+			wantErr: entity.ErrVersionConflict,
+		},
+		{
+			name: "canceled context",
+			op: func(_ context.Context, repo interfaces.UserRepository) error {
+				_, err := repo.GetByID(canceled, 1)
+				return err
+			},
+			wantErr: context.Canceled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMemoryUserRepository()
+			seedUsers(t, repo,
+				&entity.User{Name: "Alice", Email: "alice@example.com"},
+				&entity.User{Name: "Bob", Email: "bob@example.com"},
+			)
+			if err := tt.op(context.Background(), repo); !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMemoryUserRepositoryUpdateBumpsVersion(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryUserRepository()
+	alice := &entity.User{Name: "Alice", Email: "alice@example.com"}
+	seedUsers(t, repo, alice)
+
+	name := "Alicia"
+	if err := repo.Patch(ctx, alice.ID, 1, &entity.UserPatch{Name: &name}); err != nil {
+		t.Fatalf("Patch() error = %v", err)
+	}
+	got, err := repo.GetByID(ctx, alice.ID)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if got.Name != name || got.Version != 2 {
+		t.Errorf("patched user = %q version %d, want %q version 2", got.Name, got.Version, name)
+	}
+	if !got.CreatedAt.Equal(alice.CreatedAt) {
+		t.Errorf("CreatedAt = %v, want %v", got.CreatedAt, alice.CreatedAt)
+	}
+}
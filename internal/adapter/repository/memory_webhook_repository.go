@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"context"
+	"go-clean-architecture/internal/entity"
+	"go-clean-architecture/internal/usecase/interfaces"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// memoryWebhookStore holds webhooks and their deliveries so deleting a
+// webhook can drop its delivery log
+type memoryWebhookStore struct {
+	mu             sync.RWMutex
+	webhooks       map[uint]*entity.Webhook
+	deliveries     map[uint64]*entity.WebhookDelivery
+	nextWebhookID  uint
+	nextDeliveryID uint64
+}
+
+// memoryWebhookRepository implements the WebhookRepository interface in memory
+type memoryWebhookRepository struct {
+	store *memoryWebhookStore
+}
+
+// memoryWebhookDeliveryRepository implements the WebhookDeliveryRepository
+// interface in memory
+type memoryWebhookDeliveryRepository struct {
+	store *memoryWebhookStore
+}
+
+// NewMemoryWebhookRepositories creates in-memory webhook and webhook
+// delivery repositories sharing one store
+func NewMemoryWebhookRepositories() (interfaces.WebhookRepository, interfaces.WebhookDeliveryRepository) {
+	store := &memoryWebhookStore{
+		webhooks:       make(map[uint]*entity.Webhook),
+		deliveries:     make(map[uint64]*entity.WebhookDelivery),
+		nextWebhookID:  1,
+		nextDeliveryID: 1,
+	}
+	return &memoryWebhookRepository{store: store}, &memoryWebhookDeliveryRepository{store: store}
+}
+
+// Create stores a new webhook
+func (r *memoryWebhookRepository) Create(ctx context.Context, webhook *entity.Webhook) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := time.Now()
+	webhook.ID = r.store.nextWebhookID
+	webhook.CreatedAt = now
+	webhook.UpdatedAt = now
+	r.store.webhooks[webhook.ID] = cloneWebhook(webhook)
+	r.store.nextWebhookID++
+	return nil
+}
+
+// GetByID retrieves a webhook by ID
+func (r *memoryWebhookRepository) GetByID(ctx context.Context, id uint) (*entity.Webhook, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	webhook, ok := r.store.webhooks[id]
+	if !ok {
+		return nil, entity.ErrWebhookNotFound
+	}
+	return cloneWebhook(webhook), nil
+}
+
+// GetAll retrieves every webhook ordered by ID
+func (r *memoryWebhookRepository) GetAll(ctx context.Context) ([]*entity.Webhook, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	webhooks := make([]*entity.Webhook, 0, len(r.store.webhooks))
+	for _, webhook := range r.store.webhooks {
+		webhooks = append(webhooks, cloneWebhook(webhook))
+	}
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].ID < webhooks[j].ID })
+	return webhooks, nil
+}
+
+// Update overwrites an existing webhook
+func (r *memoryWebhookRepository) Update(ctx context.Context, webhook *entity.Webhook) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.webhooks[webhook.ID]
+	if !ok {
+		return entity.ErrWebhookNotFound
+	}
+	webhook.CreatedAt = existing.CreatedAt
+	webhook.UpdatedAt = time.Now()
+	r.store.webhooks[webhook.ID] = cloneWebhook(webhook)
+	return nil
+}
+
+// Delete removes a webhook and its deliveries
+func (r *memoryWebhookRepository) Delete(ctx context.Context, id uint) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.webhooks[id]; !ok {
+		return entity.ErrWebhookNotFound
+	}
+	delete(r.store.webhooks, id)
+	for deliveryID, delivery := range r.store.deliveries {
+		if delivery.WebhookID == id {
+			delete(r.store.deliveries, deliveryID)
+		}
+	}
+	return nil
+}
+
+// Create stores new deliveries
+func (r *memoryWebhookDeliveryRepository) Create(ctx context.Context, deliveries ...*entity.WebhookDelivery) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := time.Now()
+	for _, delivery := range deliveries {
+		delivery.ID = r.store.nextDeliveryID
+		delivery.CreatedAt = now
+		delivery.UpdatedAt = now
+		r.store.deliveries[delivery.ID] = cloneDelivery(delivery)
+		r.store.nextDeliveryID++
+	}
+	return nil
+}
+
+// Claim retrieves pending deliveries whose next attempt is due and leases
+// them
+func (r *memoryWebhookDeliveryRepository) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*entity.WebhookDelivery, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var deliveries []*entity.WebhookDelivery
+	for _, delivery := range r.store.deliveries {
+		if delivery.Status == entity.DeliveryPending && !delivery.NextAttemptAt.After(now) {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool {
+		if !deliveries[i].NextAttemptAt.Equal(deliveries[j].NextAttemptAt) {
+			return deliveries[i].NextAttemptAt.Before(deliveries[j].NextAttemptAt)
+		}
+		return deliveries[i].ID < deliveries[j].ID
+	})
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+
+	claimed := make([]*entity.WebhookDelivery, len(deliveries))
+	for i, delivery := range deliveries {
+		claimed[i] = cloneDelivery(delivery)
+		delivery.NextAttemptAt = now.Add(lease)
+	}
+	return claimed, nil
+}
+
+// Update overwrites the delivery state
+func (r *memoryWebhookDeliveryRepository) Update(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// The webhook may have been deleted while the delivery was in flight
+	if _, ok := r.store.deliveries[delivery.ID]; ok {
+		r.store.deliveries[delivery.ID] = cloneDelivery(delivery)
+	}
+	return nil
+}
+
+// GetByWebhook retrieves the deliveries of a webhook, newest first
+func (r *memoryWebhookDeliveryRepository) GetByWebhook(ctx context.Context, webhookID uint, limit, offset int) ([]*entity.WebhookDelivery, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var deliveries []*entity.WebhookDelivery
+	for _, delivery := range r.store.deliveries {
+		if delivery.WebhookID == webhookID {
+			deliveries = append(deliveries, cloneDelivery(delivery))
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID > deliveries[j].ID })
+	return paginate(deliveries, limit, offset), nil
+}
+
+// CountByWebhook counts the deliveries of a webhook
+func (r *memoryWebhookDeliveryRepository) CountByWebhook(ctx context.Context, webhookID uint) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var count int64
+	for _, delivery := range r.store.deliveries {
+		if delivery.WebhookID == webhookID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// cloneWebhook returns a copy so callers cannot mutate stored state
+func cloneWebhook(webhook *entity.Webhook) *entity.Webhook {
+	clone := *webhook
+	clone.Events = slices.Clone(webhook.Events)
+	return &clone
+}
+
+// cloneDelivery returns a copy so callers cannot mutate stored state
+func cloneDelivery(delivery *entity.WebhookDelivery) *entity.WebhookDelivery {
+	clone := *delivery
+	return &clone
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go-clean-architecture/internal/entity"
+	"go-clean-architecture/internal/usecase/interfaces"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// outboxRecord is a row of the outbox table
+type outboxRecord struct {
+	ID            uint64 `gorm:"primaryKey"`
+	EventType     string
+	AggregateID   uint
+	Payload       string
+	OccurredAt    time.Time
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     *string
+	PublishedAt   *time.Time
+}
+
+// TableName overrides the table name used by GORM
+func (outboxRecord) TableName() string {
+	return "outbox"
+}
+
+// outboxRepository implements the OutboxRepository interface
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+// NewOutboxRepository creates a new outbox repository instance
+func NewOutboxRepository(db *gorm.DB) interfaces.OutboxRepository {
+	return &outboxRepository{
+		db: db,
+	}
+}
+
+// conn returns the transaction running in ctx or the shared connection pool
+func (r *outboxRepository) conn(ctx context.Context) *gorm.DB {
+	return withTx(ctx, r.db)
+}
+
+// Add stores events in the outbox
+func (r *outboxRepository) Add(ctx context.Context, events ...entity.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	records := make([]*outboxRecord, 0, len(events))
+	for _, event := range events {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s event: %w", event.EventType(), err)
+		}
+		records = append(records, &outboxRecord{
+			EventType:     event.EventType(),
+			AggregateID:   event.AggregateID(),
+			Payload:       string(payload),
+			OccurredAt:    now,
+			NextAttemptAt: now,
+		})
+	}
+
+	if err := r.conn(ctx).Create(&records).Error; err != nil {
+		return translateError(err)
+	}
+	return nil
+}
+
+// Pending returns the oldest due message of each aggregate. Rows are locked
+// so concurrent relays on Postgres skip aggregates another relay is handling.
+func (r *outboxRepository) Pending(ctx context.Context, now time.Time, limit int) ([]*interfaces.OutboxMessage, error) {
+	var records []*outboxRecord
+	result := r.conn(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("published_at IS NULL AND next_attempt_at <= ?", now).
+		Where("id = (SELECT MIN(o.id) FROM outbox o WHERE o.aggregate_id = outbox.aggregate_id AND o.published_at IS NULL)").
+		Order("id").
+		Limit(limit).
+		Find(&records)
+	if result.Error != nil {
+		return nil, translateError(result.Error)
+	}
+
+	messages := make([]*interfaces.OutboxMessage, len(records))
+	for i, record := range records {
+		messages[i] = &interfaces.OutboxMessage{
+			ID:          record.ID,
+			EventType:   record.EventType,
+			AggregateID: record.AggregateID,
+			Payload:     []byte(record.Payload),
+			OccurredAt:  record.OccurredAt,
+			Attempts:    record.Attempts,
+		}
+	}
+	return messages, nil
+}
+
+// MarkPublished records that a message was delivered
+func (r *outboxRepository) MarkPublished(ctx context.Context, id uint64) error {
+	result := r.conn(ctx).
+		Model(&outboxRecord{}).
+		Where("id = ?", id).
+		Update("published_at", time.Now())
+	return translateError(result.Error)
+}
+
+// MarkFailed records a failed attempt and schedules the next one
+func (r *outboxRepository) MarkFailed(ctx context.Context, id uint64, next time.Time, reason string) error {
+	result := r.conn(ctx).
+		Model(&outboxRecord{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"attempts":        gorm.Expr("attempts + 1"),
+			"next_attempt_at": next,
+			"last_error":      reason,
+		})
+	return translateError(result.Error)
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+// ReadReplicas routes read-only queries to replicas of the primary database
+type ReadReplicas interface {
+	// Pick returns a healthy replica, or nil when there is none
+	Pick() *gorm.DB
+	// MarkFailed takes a replica out of rotation after a connectivity error
+	MarkFailed(db *gorm.DB)
+}
+
+// sessionKey is the context key for the read-your-writes session
+type sessionKey struct{}
+
+// session remembers whether a write was made through a context
+type session struct {
+	wrote atomic.Bool
+}
+
+// WithSession starts a read-your-writes session, typically one per request.
+// Once a repository writes through the returned context, later reads
+// through it go to the primary so they do not miss the write on a lagging
+// replica.
+func WithSession(ctx context.Context) context.Context {
+	return context.WithValue(ctx, sessionKey{}, &session{})
+}
+
+// markWrite records a write in the session of ctx, if any
+func markWrite(ctx context.Context) {
+	if s, ok := ctx.Value(sessionKey{}).(*session); ok {
+		s.wrote.Store(true)
+	}
+}
+
+// readsPrimary reports whether reads through ctx must see the primary,
+// because they run in a transaction or follow a write in the same session
+func readsPrimary(ctx context.Context) bool {
+	if state, ok := txFromContext(ctx); ok && state.db != nil {
+		return true
+	}
+	s, ok := ctx.Value(sessionKey{}).(*session)
+	return ok && s.wrote.Load()
+}
+
+// readFrom runs a read-only query on a replica when ctx allows it, and on
+// primary otherwise. A query failing because the replica is unreachable is
+// retried on primary.
+func readFrom(ctx context.Context, primary *gorm.DB, replicas ReadReplicas, query func(db *gorm.DB) error) error {
+	if replicas != nil && !readsPrimary(ctx) {
+		if replica := replicas.Pick(); replica != nil {
+			err := query(replica.WithContext(ctx))
+			if err == nil || !isUnavailable(err) || ctx.Err() != nil {
+				return err
+			}
+			replicas.MarkFailed(replica)
+			slog.WarnContext(ctx, "Read replica unavailable, reading from primary", "error", err)
+		}
+	}
+	return query(withTx(ctx, primary))
+}
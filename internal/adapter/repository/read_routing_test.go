@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"go-clean-architecture/internal/entity"
+	"go-clean-architecture/internal/usecase/interfaces"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// fakeReplicas serves a single replica until it is marked failed
+type fakeReplicas struct {
+	db     *gorm.DB
+	failed bool
+}
+
+func (r *fakeReplicas) Pick() *gorm.DB {
+	if r.failed {
+		return nil
+	}
+	return r.db
+}
+
+func (r *fakeReplicas) MarkFailed(db *gorm.DB) {
+	if db == r.db {
+		r.failed = true
+	}
+}
+
+// routingFixture holds a user stored under the same ID on a primary and a
+// replica database with different names, which tell where a read went
+type routingFixture struct {
+	repo     interfaces.UserRepository
+	replicas *fakeReplicas
+	primary  *gorm.DB
+	replica  *gorm.DB
+	id       uint
+}
+
+func newRoutingFixture(t *testing.T) *routingFixture {
+	t.Helper()
+	f := &routingFixture{primary: newSQLiteDB(t), replica: newSQLiteDB(t)}
+	for db, name := range map[*gorm.DB]string{f.primary: "primary", f.replica: "replica"} {
+		user := &entity.User{Name: name, Email: "alice@example.com"}
+		seedUsers(t, NewUserRepository(db, nil), user)
+		f.id = user.ID
+	}
+	f.replicas = &fakeReplicas{db: f.replica}
+	f.repo = NewUserRepository(f.primary, f.replicas)
+	return f
+}
+
+// readFrom returns which database served a lookup of the user through ctx
+func (f *routingFixture) readFrom(t *testing.T, ctx context.Context) string {
+	t.Helper()
+	user, err := f.repo.GetByID(ctx, f.id)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	return user.Name
+}
+
+func TestReadRoutingReplica(t *testing.T) {
+	f := newRoutingFixture(t)
+	if got := f.readFrom(t, context.Background()); got != "replica" {
+		t.Errorf("read went to %s, want replica", got)
+	}
+	if got := f.readFrom(t, WithSession(context.Background())); got != "replica" {
+		t.Errorf("read in a session without writes went to %s, want replica", got)
+	}
+
+	// Without a healthy replica reads go to the primary
+	f.replicas.failed = true
+	if got := f.readFrom(t, context.Background()); got != "primary" {
+		t.Errorf("read without replicas went to %s, want primary", got)
+	}
+}
+
+func TestReadRoutingTransactionUsesPrimary(t *testing.T) {
+	f := newRoutingFixture(t)
+	txManager, err := NewTxManager(f.primary, interfaces.TxOptions{})
+	if err != nil {
+		t.Fatalf("NewTxManager() error = %v", err)
+	}
+
+	err = txManager.WithinTx(context.Background(), func(ctx context.Context) error {
+		if got := f.readFrom(t, ctx); got != "primary" {
+			t.Errorf("read in a transaction went to %s, want primary", got)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithinTx() error = %v", err)
+	}
+}
+
+func TestReadRoutingReadYourWrites(t *testing.T) {
+	f := newRoutingFixture(t)
+	ctx := WithSession(context.Background())
+	other := WithSession(context.Background())
+
+	user, err := f.repo.GetByID(ctx, f.id)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	user.Name = "primary updated"
+	if err := f.repo.Update(ctx, user); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	if got := f.readFrom(t, ctx); got != "primary updated" {
+		t.Errorf("read after a write went to %s, want primary", got)
+	}
+	if got := f.readFrom(t, other); got != "replica" {
+		t.Errorf("read in another session went to %s, want replica", got)
+	}
+	if got := f.readFrom(t, context.Background()); got != "replica" {
+		t.Errorf("read without a session went to %s, want replica", got)
+	}
+}
+
+func TestReadRoutingReplicaFailure(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantRead   string
+		wantErr    string
+		wantFailed bool
+	}{
+		{name: "unreachable replica", err: driver.ErrBadConn, wantRead: "primary", wantFailed: true},
+		{name: "query error", err: errors.New("syntax error"), wantErr: "syntax error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newRoutingFixture(t)
+			// Fail every query on the replica before it runs
+			err := f.replica.Callback().Query().Before("gorm:query").Register("test:fail", func(db *gorm.DB) {
+				db.AddError(tt.err)
+			})
+			if err != nil {
+				t.Fatalf("Register() error = %v", err)
+			}
+
+			user, err := f.repo.GetByID(context.Background(), f.id)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("GetByID() error = %v, want %v", err, tt.wantErr)
+				}
+			} else if err != nil || user.Name != tt.wantRead {
+				t.Errorf("GetByID() = %v, %v, want the %s user", user, err, tt.wantRead)
+			}
+			if f.replicas.failed != tt.wantFailed {
+				t.Errorf("replica marked failed = %v, want %v", f.replicas.failed, tt.wantFailed)
+			}
+		})
+	}
+}
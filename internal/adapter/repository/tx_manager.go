@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-clean-architecture/internal/entity"
+	"go-clean-architecture/internal/usecase/interfaces"
+	"log/slog"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// txKey is the context key for the running transaction
+type txKey struct{}
+
+// txState is the transaction a context belongs to. db is nil for
+// transactions of the in-memory repository.
+type txState struct {
+	db          *gorm.DB
+	afterCommit []func()
+}
+
+// txFromContext returns the transaction running in ctx, if any
+func txFromContext(ctx context.Context) (*txState, bool) {
+	state, ok := ctx.Value(txKey{}).(*txState)
+	return state, ok
+}
+
+// withTx returns the transaction running in ctx, or db when there is none
+func withTx(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if state, ok := txFromContext(ctx); ok && state.db != nil {
+		return state.db.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
+
+// afterCommit runs fn once the transaction in ctx commits, or immediately
+// when ctx is not in a transaction. fn is dropped on rollback.
+func afterCommit(ctx context.Context, fn func()) {
+	if state, ok := txFromContext(ctx); ok {
+		state.afterCommit = append(state.afterCommit, fn)
+		return
+	}
+	fn()
+}
+
+// runAfterCommit runs the hooks registered during a committed transaction
+func (s *txState) runAfterCommit() {
+	for _, fn := range s.afterCommit {
+		fn()
+	}
+}
+
+// txManager implements the TxManager interface with GORM transactions
+type txManager struct {
+	db       *gorm.DB
+	options  *sql.TxOptions
+	attempts int
+}
+
+// NewTxManager creates a new GORM transaction manager instance
+func NewTxManager(db *gorm.DB, options interfaces.TxOptions) (interfaces.TxManager, error) {
+	isolation, err := sqlIsolation(options.Isolation)
+	if err != nil {
+		return nil, err
+	}
+	return &txManager{
+		db:       db,
+		options:  &sql.TxOptions{Isolation: isolation},
+		attempts: max(options.MaxAttempts, 1),
+	}, nil
+}
+
+// WithinTx runs fn in a transaction, retrying serialization failures
+func (m *txManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	// Nested units of work join the outer transaction
+	if _, ok := txFromContext(ctx); ok {
+		return fn(ctx)
+	}
+
+	var err error
+	for attempt := 1; attempt <= m.attempts; attempt++ {
+		state := &txState{}
+		err = m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			state.db = tx
+			return fn(context.WithValue(ctx, txKey{}, state))
+		}, m.options)
+		if err == nil {
+			state.runAfterCommit()
+			return nil
+		}
+		if !isSerializationFailure(err) {
+			return err
+		}
+
+		slog.WarnContext(ctx, "Transaction conflict, retrying", "attempt", attempt, "error", err)
+		// Back off a little longer on every attempt so conflicting
+		// transactions do not collide again immediately
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt) * 10 * time.Millisecond):
+		}
+	}
+	return entity.ErrTransactionConflict.Wrap(err)
+}
+
+// sqlIsolation maps an isolation level to its database/sql equivalent
+func sqlIsolation(level interfaces.IsolationLevel) (sql.IsolationLevel, error) {
+	switch level {
+	case interfaces.IsolationDefault:
+		return sql.LevelDefault, nil
+	case interfaces.IsolationReadCommitted:
+		return sql.LevelReadCommitted, nil
+	case interfaces.IsolationRepeatableRead:
+		return sql.LevelRepeatableRead, nil
+	case interfaces.IsolationSerializable:
+		return sql.LevelSerializable, nil
+	default:
+		return 0, fmt.Errorf("unsupported isolation level %q", level)
+	}
+}
+
+// isSerializationFailure reports whether err is a Postgres serialization
+// failure or deadlock, which succeed when the transaction is retried
+func isSerializationFailure(err error) bool {
+	var sqlErr interface{ SQLState() string }
+	if !errors.As(err, &sqlErr) {
+		return false
+	}
+	switch sqlErr.SQLState() {
+	case "40001", "40P01":
+		return true
+	default:
+		return false
+	}
+}
+
+// memoryTxManager implements the TxManager interface for the in-memory
+// repository by running one unit of work at a time. There is no rollback,
+// so it only provides isolation between units of work.
+type memoryTxManager struct {
+	mu sync.Mutex
+}
+
+// NewMemoryTxManager creates a new in-memory transaction manager instance
+func NewMemoryTxManager() interfaces.TxManager {
+	return &memoryTxManager{}
+}
+
+// WithinTx runs fn while holding the unit of work lock. Writes made before
+// a failure are kept, so the after commit hooks run either way.
+func (m *memoryTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := txFromContext(ctx); ok {
+		return fn(ctx)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state := &txState{}
+	defer state.runAfterCommit()
+	return fn(context.WithValue(ctx, txKey{}, state))
+}
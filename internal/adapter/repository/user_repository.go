@@ -2,34 +2,55 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"go-clean-architecture/internal/entity"
 	"go-clean-architecture/internal/usecase/interfaces"
+	"net"
+	"slices"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // userRepository implements the UserRepository interface
 type userRepository struct {
-	db *gorm.DB
+	db       *gorm.DB
+	replicas ReadReplicas
 }
 
-// NewUserRepository creates a new user repository instance
-func NewUserRepository(db *gorm.DB) interfaces.UserRepository {
+// NewUserRepository creates a new user repository instance. Lookups and
+// listings go to replicas when replicas is not nil.
+func NewUserRepository(db *gorm.DB, replicas ReadReplicas) interfaces.UserRepository {
 	return &userRepository{
-		db: db,
+		db:       db,
+		replicas: replicas,
 	}
 }
 
+// conn returns the transaction running in ctx or the shared connection pool
+func (r *userRepository) conn(ctx context.Context) *gorm.DB {
+	return withTx(ctx, r.db)
+}
+
+// read runs a read-only query on a replica when possible
+func (r *userRepository) read(ctx context.Context, query func(db *gorm.DB) error) error {
+	return readFrom(ctx, r.db, r.replicas, query)
+}
+
 // Create creates a new user in the database
 func (r *userRepository) Create(ctx context.Context, user *entity.User) error {
-	result := r.db.WithContext(ctx).Create(user)
+	markWrite(ctx)
+	result := r.conn(ctx).Create(user)
 	if result.Error != nil {
 		// Handle duplicate email error
 		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
 			return entity.ErrUserAlreadyExists
 		}
-		return result.Error
+		return translateError(result.Error)
 	}
 	return nil
 }
@@ -37,12 +58,14 @@ func (r *userRepository) Create(ctx context.Context, user *entity.User) error {
 // GetByID retrieves a user by ID
 func (r *userRepository) GetByID(ctx context.Context, id uint) (*entity.User, error) {
 	var user entity.User
-	result := r.db.WithContext(ctx).First(&user, id)
-	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+	err := r.read(ctx, func(db *gorm.DB) error {
+		return db.First(&user, id).Error
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, entity.ErrUserNotFound
 		}
-		return nil, result.Error
+		return nil, translateError(err)
 	}
 	return &user, nil
 }
@@ -50,61 +73,230 @@ func (r *userRepository) GetByID(ctx context.Context, id uint) (*entity.User, er
 // GetByEmail retrieves a user by email
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
 	var user entity.User
-	result := r.db.WithContext(ctx).Where("email = ?", email).First(&user)
-	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+	err := r.read(ctx, func(db *gorm.DB) error {
+		return db.Where("email = ?", email).First(&user).Error
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, entity.ErrUserNotFound
 		}
-		return nil, result.Error
+		return nil, translateError(err)
 	}
 	return &user, nil
 }
 
-// GetAll retrieves all users with pagination
-func (r *userRepository) GetAll(ctx context.Context, limit, offset int) ([]*entity.User, error) {
+// GetAll retrieves users matching the query with pagination
+func (r *userRepository) GetAll(ctx context.Context, query interfaces.UserQuery, limit, offset int) ([]*entity.User, error) {
+	var users []*entity.User
+	err := r.read(ctx, func(db *gorm.DB) error {
+		return applyUserSort(applyUserFilter(db, query.Filter), query.Sort).
+			Limit(limit).
+			Offset(offset).
+			Find(&users).Error
+	})
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return users, nil
+}
+
+// GetAllByCursor retrieves users adjacent to a keyset cursor, newest first
+func (r *userRepository) GetAllByCursor(ctx context.Context, filter interfaces.UserFilter, page interfaces.CursorPage) ([]*entity.User, error) {
+	// Walking backward scans in ascending order and flips the result afterwards
+	op, order := "<", "created_at DESC, id DESC"
+	if page.Backward {
+		op, order = ">", "created_at ASC, id ASC"
+	}
+
 	var users []*entity.User
-	result := r.db.WithContext(ctx).
-		Limit(limit).
-		Offset(offset).
-		Order("created_at DESC").
-		Find(&users)
+	err := r.read(ctx, func(db *gorm.DB) error {
+		db = applyUserFilter(db, filter)
+		if page.Cursor != nil {
+			db = db.Where(
+				"created_at "+op+" ? OR (created_at = ? AND id "+op+" ?)",
+				page.Cursor.CreatedAt, page.Cursor.CreatedAt, page.Cursor.ID,
+			)
+		}
+		return db.Order(order).Limit(page.Limit).Find(&users).Error
+	})
+	if err != nil {
+		return nil, translateError(err)
+	}
 
-	if result.Error != nil {
-		return nil, result.Error
+	if page.Backward {
+		slices.Reverse(users)
 	}
 	return users, nil
 }
 
-// Update updates an existing user
+// Update overwrites an existing user if its version still matches
 func (r *userRepository) Update(ctx context.Context, user *entity.User) error {
-	result := r.db.WithContext(ctx).Save(user)
+	markWrite(ctx)
+	expected := user.Version
+	user.Version++
+
+	result := r.conn(ctx).
+		Model(user).
+		Where("version = ?", expected).
+		Select("*").
+		Omit("id", "created_at", "deleted_at").
+		Updates(user)
 	if result.Error != nil {
+		user.Version = expected
 		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
 			return entity.ErrUserAlreadyExists
 		}
-		return result.Error
+		return translateError(result.Error)
 	}
 	if result.RowsAffected == 0 {
-		return entity.ErrUserNotFound
+		user.Version = expected
+		return r.missingOrConflict(ctx, user.ID)
 	}
 	return nil
 }
 
-// Delete soft deletes a user by ID
-func (r *userRepository) Delete(ctx context.Context, id uint) error {
-	result := r.db.WithContext(ctx).Delete(&entity.User{}, id)
+// Patch updates only the columns set in the patch if the version still matches
+func (r *userRepository) Patch(ctx context.Context, id, version uint, patch *entity.UserPatch) error {
+	markWrite(ctx)
+
+	// A map is used so zero values such as active=false are written too
+	columns := map[string]interface{}{
+		"version": gorm.Expr("version + 1"),
+	}
+	if patch.Name != nil {
+		columns["name"] = *patch.Name
+	}
+	if patch.Email != nil {
+		columns["email"] = *patch.Email
+	}
+	if patch.Phone != nil {
+		columns["phone"] = *patch.Phone
+	}
+	if patch.Active != nil {
+		columns["active"] = *patch.Active
+	}
+	if patch.Role != nil {
+		columns["role"] = *patch.Role
+	}
+	if patch.PasswordHash != nil {
+		columns["password_hash"] = *patch.PasswordHash
+	}
+
+	result := r.conn(ctx).
+		Model(&entity.User{}).
+		Where("id = ? AND version = ?", id, version).
+		Updates(columns)
 	if result.Error != nil {
-		return result.Error
+		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+			return entity.ErrUserAlreadyExists
+		}
+		return translateError(result.Error)
 	}
 	if result.RowsAffected == 0 {
-		return entity.ErrUserNotFound
+		return r.missingOrConflict(ctx, id)
+	}
+	return nil
+}
+
+// Delete soft deletes a user by ID if its version still matches. The
+// version is bumped like any other write, so a concurrent update holding the
+// old version cannot match the deleted row.
+func (r *userRepository) Delete(ctx context.Context, id, version uint) error {
+	markWrite(ctx)
+	result := r.conn(ctx).
+		Model(&entity.User{}).
+		Where("id = ? AND version = ?", id, version).
+		Updates(map[string]interface{}{
+			"deleted_at": time.Now(),
+			"version":    gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return r.missingOrConflict(ctx, id)
 	}
 	return nil
 }
 
-// Count returns the total number of users
-func (r *userRepository) Count(ctx context.Context) (int64, error) {
+// missingOrConflict explains why a conditional write matched no rows
+func (r *userRepository) missingOrConflict(ctx context.Context, id uint) error {
+	var count int64
+	result := r.conn(ctx).Model(&entity.User{}).Where("id = ?", id).Count(&count)
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if count == 0 {
+		return entity.ErrUserNotFound
+	}
+	return entity.ErrVersionConflict
+}
+
+// Count returns the number of users matching the filter
+func (r *userRepository) Count(ctx context.Context, filter interfaces.UserFilter) (int64, error) {
 	var count int64
-	result := r.db.WithContext(ctx).Model(&entity.User{}).Count(&count)
-	return count, result.Error
+	err := r.read(ctx, func(db *gorm.DB) error {
+		return applyUserFilter(db.Model(&entity.User{}), filter).Count(&count).Error
+	})
+	return count, translateError(err)
+}
+
+// applyUserFilter adds WHERE conditions for the given filter
+func applyUserFilter(db *gorm.DB, filter interfaces.UserFilter) *gorm.DB {
+	if filter.Active != nil {
+		db = db.Where("active = ?", *filter.Active)
+	}
+	if filter.EmailDomain != "" {
+		db = db.Where("LOWER(email) LIKE ? ESCAPE '\\'", "%@"+escapeLike(strings.ToLower(filter.EmailDomain)))
+	}
+	if filter.CreatedAfter != nil {
+		db = db.Where("created_at > ?", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		db = db.Where("created_at < ?", *filter.CreatedBefore)
+	}
+	if filter.Search != "" {
+		pattern := "%" + escapeLike(strings.ToLower(filter.Search)) + "%"
+		db = db.Where(
+			"LOWER(name) LIKE ? ESCAPE '\\' OR LOWER(email) LIKE ? ESCAPE '\\' OR LOWER(phone) LIKE ? ESCAPE '\\'",
+			pattern, pattern, pattern,
+		)
+	}
+	return db
+}
+
+// applyUserSort adds ORDER BY clauses, defaulting to newest first
+func applyUserSort(db *gorm.DB, sort []interfaces.SortField) *gorm.DB {
+	if len(sort) == 0 {
+		sort = []interfaces.SortField{{Field: interfaces.UserSortCreatedAt, Desc: true}}
+	}
+
+	columns := make([]clause.OrderByColumn, 0, len(sort))
+	for _, field := range sort {
+		columns = append(columns, clause.OrderByColumn{
+			Column: clause.Column{Name: field.Field},
+			Desc:   field.Desc,
+		})
+	}
+	return db.Order(clause.OrderBy{Columns: columns})
+}
+
+// escapeLike escapes LIKE wildcards in user supplied input
+func escapeLike(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_").Replace(s)
+}
+
+// translateError maps connectivity failures to entity.ErrUnavailable and
+// passes every other error through
+func translateError(err error) error {
+	if isUnavailable(err) {
+		return entity.ErrUnavailable.Wrap(err)
+	}
+	return err
+}
+
+// isUnavailable reports whether err means the database could not be reached
+func isUnavailable(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) || errors.As(err, &netErr)
 }
@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"go-clean-architecture/internal/entity"
+	"go-clean-architecture/internal/infrastructure/database"
+	"go-clean-architecture/internal/usecase/interfaces"
+	"slices"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// newSQLiteDB returns a migrated in-memory SQLite database
+func newSQLiteDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := database.Open(&database.Config{Driver: database.DriverSQLite, DSN: ":memory:"})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		t.Fatalf("NewMigrator() error = %v", err)
+	}
+	if err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	return db
+}
+
+// walkCursor pages through users limit at a time starting after from, or
+// from the newest user when from is nil, and returns the IDs in listing
+// order. Walking backward follows prev_cursor, the first user of each page.
+func walkCursor(t *testing.T, repo interfaces.UserRepository, filter interfaces.UserFilter, from *entity.User, backward bool, limit int) []uint {
+	t.Helper()
+	page := interfaces.CursorPage{Backward: backward, Limit: limit}
+	if from != nil {
+		page.Cursor = &interfaces.UserCursor{CreatedAt: from.CreatedAt, ID: from.ID}
+	}
+
+	var ids []uint
+	for pages := 0; ; pages++ {
+		if pages > 100 {
+			t.Fatalf("walkCursor() did not finish, ids so far %v", ids)
+		}
+		users, err := repo.GetAllByCursor(context.Background(), filter, page)
+		if err != nil {
+			t.Fatalf("GetAllByCursor() error = %v", err)
+		}
+		if len(users) == 0 {
+			return ids
+		}
+
+		next := users[len(users)-1]
+		if backward {
+			next = users[0]
+			ids = append(userIDs(users), ids...)
+		} else {
+			ids = append(ids, userIDs(users)...)
+		}
+		page.Cursor = &interfaces.UserCursor{CreatedAt: next.CreatedAt, ID: next.ID}
+	}
+}
+
+// userRepositories create the GORM repository over SQLite and the
+// in-memory repository, which must behave the same
+var userRepositories = map[string]func(t *testing.T) interfaces.UserRepository{
+	"gorm":   func(t *testing.T) interfaces.UserRepository { return NewUserRepository(newSQLiteDB(t), nil) },
+	"memory": func(t *testing.T) interfaces.UserRepository { return NewMemoryUserRepository() },
+}
+
+func TestUserRepositoryKeysetPagination(t *testing.T) {
+	for name, newRepo := range userRepositories {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t)
+
+			// Several users share a creation time, so pages must break ties by ID
+			base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			offsets := []int{0, 0, 1, 1, 1, 2, 3, 3}
+			users := make([]*entity.User, len(offsets))
+			for i, offset := range offsets {
+				users[i] = &entity.User{
+					Name:      string(rune('A' + i)),
+					Email:     string(rune('a'+i)) + "@example.com",
+					CreatedAt: base.Add(time.Duration(offset) * time.Minute),
+				}
+			}
+			seedUsers(t, repo, users...)
+			inactive := users[3]
+			inactive.Active = false
+			if err := repo.Update(context.Background(), inactive); err != nil {
+				t.Fatalf("Update() error = %v", err)
+			}
+
+			// Newest first, ties by descending ID
+			var all, active []uint
+			for i := len(users) - 1; i >= 0; i-- {
+				all = append(all, users[i].ID)
+				if users[i] != inactive {
+					active = append(active, users[i].ID)
+				}
+			}
+			last := func(ids []uint) *entity.User {
+				for _, user := range users {
+					if user.ID == ids[len(ids)-1] {
+						return user
+					}
+				}
+				return nil
+			}
+			activeFilter := interfaces.UserFilter{Active: ptrTo(true)}
+
+			for _, limit := range []int{1, 2, 3, len(users)} {
+				if got := walkCursor(t, repo, interfaces.UserFilter{}, nil, false, limit); !slices.Equal(got, all) {
+					t.Errorf("limit %d forward = %v, want %v", limit, got, all)
+				}
+				// Walking back from the oldest user returns every other user
+				if got := walkCursor(t, repo, interfaces.UserFilter{}, last(all), true, limit); !slices.Equal(got, all[:len(all)-1]) {
+					t.Errorf("limit %d backward = %v, want %v", limit, got, all[:len(all)-1])
+				}
+				// The keyset condition must not escape the filter
+				if got := walkCursor(t, repo, activeFilter, nil, false, limit); !slices.Equal(got, active) {
+					t.Errorf("limit %d forward active = %v, want %v", limit, got, active)
+				}
+				if got := walkCursor(t, repo, activeFilter, last(active), true, limit); !slices.Equal(got, active[:len(active)-1]) {
+					t.Errorf("limit %d backward active = %v, want %v", limit, got, active[:len(active)-1])
+				}
+			}
+		})
+	}
+}
+
+func TestUserRepositoryUpdateDeleteRace(t *testing.T) {
+	for name, newRepo := range userRepositories {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			// Both clients read version 1, the update commits first
+			repo := newRepo(t)
+			user := &entity.User{Name: "Alice", Email: "alice@example.com"}
+			seedUsers(t, repo, user)
+			updated := *user
+			updated.Name = "Alice B"
+			if err := repo.Update(ctx, &updated); err != nil {
+				t.Fatalf("Update() error = %v", err)
+			}
+			if err := repo.Delete(ctx, user.ID, user.Version); !errors.Is(err, entity.ErrVersionConflict) {
+				t.Errorf("Delete() after Update() error = %v, want %v", err, entity.ErrVersionConflict)
+			}
+			if got, err := repo.GetByID(ctx, user.ID); err != nil || got.Name != "Alice B" {
+				t.Errorf("GetByID() = %+v, %v, want the updated user", got, err)
+			}
+
+			// Both clients read version 1, the delete commits first
+			repo = newRepo(t)
+			user = &entity.User{Name: "Alice", Email: "alice@example.com"}
+			seedUsers(t, repo, user)
+			updated = *user
+			updated.Name = "Alice B"
+			if err := repo.Delete(ctx, user.ID, user.Version); err != nil {
+				t.Fatalf("Delete() error = %v", err)
+			}
+			if err := repo.Update(ctx, &updated); err == nil {
+				t.Error("Update() after Delete() error = nil")
+			}
+			if err := repo.Patch(ctx, user.ID, user.Version, &entity.UserPatch{Name: ptrTo("Alice C")}); err == nil {
+				t.Error("Patch() after Delete() error = nil")
+			}
+			if _, err := repo.GetByID(ctx, user.ID); !errors.Is(err, entity.ErrUserNotFound) {
+				t.Errorf("GetByID() after Delete() error = %v, want %v", err, entity.ErrUserNotFound)
+			}
+		})
+	}
+}
+
+// ptrTo returns a pointer to v
+func ptrTo[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"go-clean-architecture/internal/entity"
+	"go-clean-architecture/internal/usecase/interfaces"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// webhookRepository implements the WebhookRepository interface
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookRepository creates a new webhook repository instance
+func NewWebhookRepository(db *gorm.DB) interfaces.WebhookRepository {
+	return &webhookRepository{
+		db: db,
+	}
+}
+
+// conn returns the transaction running in ctx or the shared connection pool
+func (r *webhookRepository) conn(ctx context.Context) *gorm.DB {
+	return withTx(ctx, r.db)
+}
+
+// Create stores a new webhook
+func (r *webhookRepository) Create(ctx context.Context, webhook *entity.Webhook) error {
+	return translateError(r.conn(ctx).Create(webhook).Error)
+}
+
+// GetByID retrieves a webhook by ID
+func (r *webhookRepository) GetByID(ctx context.Context, id uint) (*entity.Webhook, error) {
+	var webhook entity.Webhook
+	result := r.conn(ctx).First(&webhook, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, entity.ErrWebhookNotFound
+		}
+		return nil, translateError(result.Error)
+	}
+	return &webhook, nil
+}
+
+// GetAll retrieves every webhook ordered by ID
+func (r *webhookRepository) GetAll(ctx context.Context) ([]*entity.Webhook, error) {
+	var webhooks []*entity.Webhook
+	if err := r.conn(ctx).Order("id").Find(&webhooks).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return webhooks, nil
+}
+
+// Update overwrites an existing webhook
+func (r *webhookRepository) Update(ctx context.Context, webhook *entity.Webhook) error {
+	result := r.conn(ctx).
+		Model(webhook).
+		Select("*").
+		Omit("id", "created_at").
+		Updates(webhook)
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return entity.ErrWebhookNotFound
+	}
+	return nil
+}
+
+// Delete removes a webhook and its deliveries
+func (r *webhookRepository) Delete(ctx context.Context, id uint) error {
+	// SQLite does not enforce the cascading foreign key, so delete explicitly
+	err := r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", id).Delete(&entity.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&entity.Webhook{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return entity.ErrWebhookNotFound
+		}
+		return nil
+	})
+	return translateError(err)
+}
+
+// webhookDeliveryRepository implements the WebhookDeliveryRepository interface
+type webhookDeliveryRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookDeliveryRepository creates a new webhook delivery repository instance
+func NewWebhookDeliveryRepository(db *gorm.DB) interfaces.WebhookDeliveryRepository {
+	return &webhookDeliveryRepository{
+		db: db,
+	}
+}
+
+// conn returns the transaction running in ctx or the shared connection pool
+func (r *webhookDeliveryRepository) conn(ctx context.Context) *gorm.DB {
+	return withTx(ctx, r.db)
+}
+
+// Create stores new deliveries
+func (r *webhookDeliveryRepository) Create(ctx context.Context, deliveries ...*entity.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return translateError(r.conn(ctx).Create(deliveries).Error)
+}
+
+// Claim retrieves pending deliveries whose next attempt is due and leases
+// them. Rows are locked while they are leased so concurrent dispatchers on
+// Postgres skip them instead of waiting.
+func (r *webhookDeliveryRepository) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*entity.WebhookDelivery, error) {
+	var deliveries []*entity.WebhookDelivery
+	err := r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", entity.DeliveryPending, now).
+			Order("next_attempt_at, id").
+			Limit(limit).
+			Find(&deliveries)
+		if result.Error != nil || len(deliveries) == 0 {
+			return result.Error
+		}
+
+		ids := make([]uint64, len(deliveries))
+		for i, delivery := range deliveries {
+			ids[i] = delivery.ID
+		}
+		return tx.Model(&entity.WebhookDelivery{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return deliveries, nil
+}
+
+// Update overwrites the delivery state
+func (r *webhookDeliveryRepository) Update(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	result := r.conn(ctx).
+		Model(delivery).
+		Select("status", "attempts", "next_attempt_at", "last_error", "response_status", "delivered_at", "updated_at").
+		Updates(delivery)
+	return translateError(result.Error)
+}
+
+// GetByWebhook retrieves the deliveries of a webhook, newest first
+func (r *webhookDeliveryRepository) GetByWebhook(ctx context.Context, webhookID uint, limit, offset int) ([]*entity.WebhookDelivery, error) {
+	var deliveries []*entity.WebhookDelivery
+	result := r.conn(ctx).
+		Where("webhook_id = ?", webhookID).
+		Order("id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&deliveries)
+	if result.Error != nil {
+		return nil, translateError(result.Error)
+	}
+	return deliveries, nil
+}
+
+// CountByWebhook counts the deliveries of a webhook
+func (r *webhookDeliveryRepository) CountByWebhook(ctx context.Context, webhookID uint) (int64, error) {
+	var count int64
+	result := r.conn(ctx).Model(&entity.WebhookDelivery{}).Where("webhook_id = ?", webhookID).Count(&count)
+	return count, translateError(result.Error)
+}
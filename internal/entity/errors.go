@@ -2,10 +2,109 @@ package entity
 
 import "errors"
 
+// ErrorKind classifies domain errors so adapters can map them without
+// knowing every individual error
+type ErrorKind int
+
+// Error kinds. KindInternal is the zero value and covers every error that
+// is not a domain error.
+const (
+	KindInternal ErrorKind = iota
+	KindNotFound
+	KindConflict
+	KindInvalid
+	KindUnauthorized
+	KindForbidden
+	KindUnavailable
+)
+
+// String returns the kind name used in logs and metrics
+func (k ErrorKind) String() string {
+	switch k {
+	case KindNotFound:
+		return "not_found"
+	case KindConflict:
+		return "conflict"
+	case KindInvalid:
+		return "invalid"
+	case KindUnauthorized:
+		return "unauthorized"
+	case KindForbidden:
+		return "forbidden"
+	case KindUnavailable:
+		return "unavailable"
+	default:
+		return "internal"
+	}
+}
+
+// Error is a domain error with a kind and a stable machine readable code
+type Error struct {
+	Kind    ErrorKind
+	Code    string
+	Message string
+	// Err is the underlying cause, if any
+	Err error
+}
+
+// NewError creates a new domain error
+func NewError(kind ErrorKind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+// Error returns the message followed by the cause
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+// Unwrap returns the cause
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches any domain error with the same code, so wrapped copies still
+// compare equal to the catalog entry
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Wrap returns a copy of the error with the given cause
+func (e *Error) Wrap(err error) *Error {
+	clone := *e
+	clone.Err = err
+	return &clone
+}
+
+// KindOf returns the kind of the first domain error in err's chain
+func KindOf(err error) ErrorKind {
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return domainErr.Kind
+	}
+	return KindInternal
+}
+
+// Error catalog. Codes are part of the API and must not change.
 var (
-	ErrUserNotFound      = errors.New("user not found")
-	ErrInvalidUserName   = errors.New("invalid user name")
-	ErrInvalidUserEmail  = errors.New("invalid user email")
-	ErrUserAlreadyExists = errors.New("user already exists")
-	ErrInvalidUserID     = errors.New("invalid user ID")
+	ErrUserNotFound        = NewError(KindNotFound, "user_not_found", "user not found")
+	ErrInvalidUserName     = NewError(KindInvalid, "invalid_user_name", "invalid user name")
+	ErrInvalidUserEmail    = NewError(KindInvalid, "invalid_user_email", "invalid user email")
+	ErrUserAlreadyExists   = NewError(KindConflict, "user_already_exists", "user with this email already exists")
+	ErrInvalidUserID       = NewError(KindInvalid, "invalid_user_id", "invalid user ID")
+	ErrInvalidUserQuery    = NewError(KindInvalid, "invalid_user_query", "invalid user query")
+	ErrVersionConflict     = NewError(KindConflict, "version_conflict", "user was modified concurrently")
+	ErrInvalidUserRole     = NewError(KindInvalid, "invalid_user_role", "invalid user role")
+	ErrForbidden           = NewError(KindForbidden, "forbidden", "not allowed to perform this action")
+	ErrInvalidCredentials  = NewError(KindUnauthorized, "invalid_credentials", "invalid email or password")
+	ErrInvalidToken        = NewError(KindUnauthorized, "invalid_token", "invalid or expired token")
+	ErrUnavailable         = NewError(KindUnavailable, "unavailable", "service temporarily unavailable")
+	ErrTransactionConflict = NewError(KindConflict, "transaction_conflict", "request conflicted with a concurrent update, please retry")
+	ErrWebhookNotFound     = NewError(KindNotFound, "webhook_not_found", "webhook not found")
+	ErrInvalidWebhookID    = NewError(KindInvalid, "invalid_webhook_id", "invalid webhook ID")
+	ErrInvalidWebhookURL   = NewError(KindInvalid, "invalid_webhook_url", "webhook URL must be an absolute http or https URL of a non-local host")
+	ErrInvalidWebhookEvent = NewError(KindInvalid, "invalid_webhook_event", "unknown webhook event type")
 )
@@ -0,0 +1,77 @@
+package entity
+
+// Event types published to downstream services. They are part of the API
+// and must not change.
+const (
+	EventUserCreated     = "user.created"
+	EventUserUpdated     = "user.updated"
+	EventUserActivated   = "user.activated"
+	EventUserDeactivated = "user.deactivated"
+	EventUserDeleted     = "user.deleted"
+)
+
+// Event is a domain event raised by a change to an aggregate
+type Event interface {
+	// EventType returns the stable name of the event
+	EventType() string
+	// AggregateID returns the ID of the changed aggregate. Events of the
+	// same aggregate are delivered in the order they were raised.
+	AggregateID() uint
+}
+
+// UserCreated is raised when a user is created
+type UserCreated struct {
+	User User `json:"user"`
+}
+
+// EventType returns the event name
+func (e UserCreated) EventType() string { return EventUserCreated }
+
+// AggregateID returns the user ID
+func (e UserCreated) AggregateID() uint { return e.User.ID }
+
+// UserUpdated is raised when any field of a user changes
+type UserUpdated struct {
+	User User `json:"user"`
+}
+
+// EventType returns the event name
+func (e UserUpdated) EventType() string { return EventUserUpdated }
+
+// AggregateID returns the user ID
+func (e UserUpdated) AggregateID() uint { return e.User.ID }
+
+// UserActivated is raised when an inactive user becomes active
+type UserActivated struct {
+	UserID  uint `json:"user_id"`
+	Version uint `json:"version"`
+}
+
+// EventType returns the event name
+func (e UserActivated) EventType() string { return EventUserActivated }
+
+// AggregateID returns the user ID
+func (e UserActivated) AggregateID() uint { return e.UserID }
+
+// UserDeactivated is raised when an active user becomes inactive
+type UserDeactivated struct {
+	UserID  uint `json:"user_id"`
+	Version uint `json:"version"`
+}
+
+// EventType returns the event name
+func (e UserDeactivated) EventType() string { return EventUserDeactivated }
+
+// AggregateID returns the user ID
+func (e UserDeactivated) AggregateID() uint { return e.UserID }
+
+// UserDeleted is raised when a user is deleted
+type UserDeleted struct {
+	UserID uint `json:"user_id"`
+}
+
+// EventType returns the event name
+func (e UserDeleted) EventType() string { return EventUserDeleted }
+
+// AggregateID returns the user ID
+func (e UserDeleted) AggregateID() uint { return e.UserID }
@@ -6,16 +6,28 @@ import (
 	"gorm.io/gorm"
 )
 
-// User represents the user entity with business rules
+// User roles
+const (
+	RoleAdmin    = "admin"
+	RoleOperator = "operator"
+	RoleSelf     = "self"
+)
+
+// User represents the user entity with business rules.
+// Password is write-only input that the use case hashes into PasswordHash.
 type User struct {
-	ID        uint           `json:"id" gorm:"primaryKey"`
-	Name      string         `json:"name" gorm:"not null;size:100" binding:"required"`
-	Email     string         `json:"email" gorm:"uniqueIndex;not null;size:100" binding:"required,email"`
-	Phone     string         `json:"phone" gorm:"size:20"`
-	Active    bool           `json:"active" gorm:"default:true"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	ID           uint           `json:"id" gorm:"primaryKey"`
+	Name         string         `json:"name" gorm:"not null;size:100" binding:"required"`
+	Email        string         `json:"email" gorm:"uniqueIndex;not null;size:100" binding:"required,email"`
+	Phone        string         `json:"phone" gorm:"size:20"`
+	Active       bool           `json:"active" gorm:"default:true"`
+	Role         string         `json:"role" gorm:"not null;size:20;default:self" binding:"omitempty,oneof=admin operator self"`
+	Version      uint           `json:"version" gorm:"not null;default:1"`
+	Password     string         `json:"password,omitempty" gorm:"-" binding:"omitempty,min=8,max=72"`
+	PasswordHash string         `json:"-" gorm:"size:255"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
 // BeforeCreate hook to validate business rules
@@ -26,6 +38,14 @@ func (u *User) BeforeCreate(tx *gorm.DB) error {
 	if u.Email == "" {
 		return ErrInvalidUserEmail
 	}
+	if u.Role == "" {
+		u.Role = RoleSelf
+	}
+	if !IsValidRole(u.Role) {
+		return ErrInvalidUserRole
+	}
+	// Every user starts at the first version
+	u.Version = 1
 	return nil
 }
 
@@ -43,3 +63,63 @@ func (u *User) Activate() {
 func (u *User) Deactivate() {
 	u.Active = false
 }
+
+// UserPatch describes a partial update of a user.
+// Nil fields are left untouched.
+type UserPatch struct {
+	Name   *string
+	Email  *string
+	Phone  *string
+	Active *bool
+	Role   *string
+	// Password is plaintext input that the use case turns into PasswordHash
+	Password     *string
+	PasswordHash *string
+}
+
+// IsEmpty reports whether the patch changes nothing
+func (p *UserPatch) IsEmpty() bool {
+	return p.Name == nil && p.Email == nil && p.Phone == nil && p.Active == nil &&
+		p.Role == nil && p.Password == nil && p.PasswordHash == nil
+}
+
+// Apply copies the patched fields onto a user
+func (p *UserPatch) Apply(u *User) {
+	if p.Name != nil {
+		u.Name = *p.Name
+	}
+	if p.Email != nil {
+		u.Email = *p.Email
+	}
+	if p.Phone != nil {
+		u.Phone = *p.Phone
+	}
+	if p.Active != nil {
+		u.Active = *p.Active
+	}
+	if p.Role != nil {
+		u.Role = *p.Role
+	}
+	if p.PasswordHash != nil {
+		u.PasswordHash = *p.PasswordHash
+	}
+}
+
+// IsValidRole reports whether role is a known user role
+func IsValidRole(role string) bool {
+	switch role {
+	case RoleAdmin, RoleOperator, RoleSelf:
+		return true
+	}
+	return false
+}
+
+// HasRole reports whether the user has the given role
+func (u *User) HasRole(role string) bool {
+	return u.Role == role
+}
+
+// HasPassword reports whether the user can log in with a password
+func (u *User) HasPassword() bool {
+	return u.PasswordHash != ""
+}
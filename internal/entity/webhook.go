@@ -0,0 +1,95 @@
+package entity
+
+import (
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// EventAll subscribes a webhook to every event type
+const EventAll = "*"
+
+// Webhook delivery states
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryDead      = "dead"
+)
+
+// EventTypes lists every event type a webhook may subscribe to
+var EventTypes = []string{
+	EventUserCreated,
+	EventUserUpdated,
+	EventUserActivated,
+	EventUserDeactivated,
+	EventUserDeleted,
+}
+
+// Webhook is a partner subscription to domain events delivered over HTTP.
+// Secret signs every payload and is only returned when the webhook is created.
+type Webhook struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	URL       string    `json:"url" gorm:"not null;size:2048"`
+	Events    []string  `json:"events" gorm:"not null;serializer:json"`
+	Secret    string    `json:"secret,omitempty" gorm:"not null;size:255"`
+	Active    bool      `json:"active" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate checks the URL and event filter
+func (w *Webhook) Validate() error {
+	target, err := url.Parse(w.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Hostname() == "" || isLocalHost(target.Hostname()) {
+		return ErrInvalidWebhookURL
+	}
+	if len(w.Events) == 0 {
+		return ErrInvalidWebhookEvent
+	}
+	for _, event := range w.Events {
+		if event != EventAll && !slices.Contains(EventTypes, event) {
+			return ErrInvalidWebhookEvent
+		}
+	}
+	return nil
+}
+
+// isLocalHost reports whether host names this machine or its local link,
+// which webhooks must not be able to reach. Host names are not resolved.
+func isLocalHost(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	return addr.IsLoopback() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsUnspecified()
+}
+
+// Subscribes reports whether the webhook wants events of the given type
+func (w *Webhook) Subscribes(eventType string) bool {
+	return w.Active && (slices.Contains(w.Events, EventAll) || slices.Contains(w.Events, eventType))
+}
+
+// WebhookDelivery is one event sent to one webhook, kept as a delivery log
+type WebhookDelivery struct {
+	ID        uint64 `json:"id" gorm:"primaryKey"`
+	WebhookID uint   `json:"webhook_id" gorm:"not null;index"`
+	EventID   uint64 `json:"event_id" gorm:"not null"`
+	EventType string `json:"event_type" gorm:"not null;size:100"`
+	// Payload is the exact request body that was signed
+	Payload        string     `json:"payload" gorm:"not null"`
+	Status         string     `json:"status" gorm:"not null;size:20"`
+	Attempts       int        `json:"attempts" gorm:"not null"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at"`
+	LastError      string     `json:"last_error,omitempty"`
+	ResponseStatus int        `json:"response_status,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,189 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"go-clean-architecture/internal/entity"
+	"go-clean-architecture/internal/usecase/interfaces"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Supported signing algorithms
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+)
+
+// MinSecretLength is the shortest HS256 secret accepted, in bytes
+const MinSecretLength = 32
+
+// Token types carried in the token_use claim
+const (
+	tokenUseAccess  = "access"
+	tokenUseRefresh = "refresh"
+)
+
+// Config holds JWT configuration
+type Config struct {
+	Algorithm string
+	// Secret signs HS256 tokens
+	Secret string
+	// PrivateKeyFile and PublicKeyFile are PEM encoded RSA keys for RS256.
+	// The public key is derived from the private key when not set.
+	PrivateKeyFile string
+	PublicKeyFile  string
+	Issuer         string
+	AccessTTL      time.Duration
+	RefreshTTL     time.Duration
+}
+
+// claims are the JWT claims issued by jwtService
+type claims struct {
+	TokenUse string `json:"token_use"`
+	jwt.RegisteredClaims
+}
+
+// jwtService implements the TokenService interface with signed JWTs
+type jwtService struct {
+	method     jwt.SigningMethod
+	signKey    interface{}
+	verifyKey  interface{}
+	issuer     string
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+// NewJWTService creates a new token service from config
+func NewJWTService(config *Config) (interfaces.TokenService, error) {
+	service := &jwtService{
+		issuer:     config.Issuer,
+		accessTTL:  config.AccessTTL,
+		refreshTTL: config.RefreshTTL,
+	}
+
+	switch config.Algorithm {
+	case AlgorithmHS256:
+		secret := []byte(config.Secret)
+		if len(secret) < MinSecretLength {
+			return nil, fmt.Errorf("JWT_SECRET must be at least %d bytes for HS256", MinSecretLength)
+		}
+		service.method = jwt.SigningMethodHS256
+		service.signKey = secret
+		service.verifyKey = secret
+	case AlgorithmRS256:
+		privateKey, publicKey, err := loadRSAKeys(config.PrivateKeyFile, config.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		service.method = jwt.SigningMethodRS256
+		service.signKey = privateKey
+		service.verifyKey = publicKey
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm %q", config.Algorithm)
+	}
+
+	return service, nil
+}
+
+// IssueAccessToken issues a short-lived token for API calls
+func (s *jwtService) IssueAccessToken(user *entity.User) (string, time.Time, error) {
+	return s.issue(user, tokenUseAccess, s.accessTTL)
+}
+
+// IssueRefreshToken issues a long-lived token for obtaining new access tokens
+func (s *jwtService) IssueRefreshToken(user *entity.User) (string, time.Time, error) {
+	return s.issue(user, tokenUseRefresh, s.refreshTTL)
+}
+
+// ParseAccessToken verifies an access token
+func (s *jwtService) ParseAccessToken(token string) (*interfaces.TokenClaims, error) {
+	return s.parse(token, tokenUseAccess)
+}
+
+// ParseRefreshToken verifies a refresh token
+func (s *jwtService) ParseRefreshToken(token string) (*interfaces.TokenClaims, error) {
+	return s.parse(token, tokenUseRefresh)
+}
+
+// issue signs a token of the given type for a user
+func (s *jwtService) issue(user *entity.User, tokenUse string, ttl time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+
+	token := jwt.NewWithClaims(s.method, claims{
+		TokenUse: tokenUse,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    s.issuer,
+			Subject:   strconv.FormatUint(uint64(user.ID), 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	})
+
+	signed, err := token.SignedString(s.signKey)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to sign token: %w", err)
+	}
+	return signed, expiresAt, nil
+}
+
+// parse verifies signature, standard claims and token type
+func (s *jwtService) parse(token, tokenUse string) (*interfaces.TokenClaims, error) {
+	var parsed claims
+	_, err := jwt.ParseWithClaims(token, &parsed, func(*jwt.Token) (interface{}, error) {
+		return s.verifyKey, nil
+	},
+		jwt.WithValidMethods([]string{s.method.Alg()}),
+		jwt.WithIssuer(s.issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil || parsed.TokenUse != tokenUse {
+		return nil, entity.ErrInvalidToken
+	}
+
+	userID, err := strconv.ParseUint(parsed.Subject, 10, 32)
+	if err != nil || userID == 0 {
+		return nil, entity.ErrInvalidToken
+	}
+
+	return &interfaces.TokenClaims{
+		UserID:    uint(userID),
+		ExpiresAt: parsed.ExpiresAt.Time,
+	}, nil
+}
+
+// loadRSAKeys reads the RS256 key pair from PEM files
+func loadRSAKeys(privateKeyFile, publicKeyFile string) (*rsa.PrivateKey, *rsa.PublicKey, error) {
+	if privateKeyFile == "" {
+		return nil, nil, errors.New("JWT_PRIVATE_KEY_FILE is required for RS256")
+	}
+
+	privatePEM, err := os.ReadFile(privateKeyFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read JWT private key: %w", err)
+	}
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(privatePEM)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse JWT private key: %w", err)
+	}
+
+	if publicKeyFile == "" {
+		return privateKey, &privateKey.PublicKey, nil
+	}
+
+	publicPEM, err := os.ReadFile(publicKeyFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read JWT public key: %w", err)
+	}
+	publicKey, err := jwt.ParseRSAPublicKeyFromPEM(publicPEM)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse JWT public key: %w", err)
+	}
+	return privateKey, publicKey, nil
+}
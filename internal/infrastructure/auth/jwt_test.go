@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"go-clean-architecture/internal/entity"
+	"go-clean-architecture/internal/usecase/interfaces"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+// newTestService creates a token service from config, failing the test on error
+func newTestService(t *testing.T, config Config) interfaces.TokenService {
+	t.Helper()
+	if config.Issuer == "" {
+		config.Issuer = "test"
+	}
+	if config.AccessTTL == 0 {
+		config.AccessTTL = time.Minute
+	}
+	if config.RefreshTTL == 0 {
+		config.RefreshTTL = time.Hour
+	}
+	service, err := NewJWTService(&config)
+	if err != nil {
+		t.Fatalf("NewJWTService() error = %v", err)
+	}
+	return service
+}
+
+// writeRSAKey writes a new PEM encoded RSA private key and returns its path
+func writeRSAKey(t *testing.T) string {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "private.pem")
+	block := &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	return path
+}
+
+func TestNewJWTServiceRejectsShortSecret(t *testing.T) {
+	for _, secret := range []string{"", "short", testSecret[:MinSecretLength-1]} {
+		_, err := NewJWTService(&Config{Algorithm: AlgorithmHS256, Secret: secret})
+		if err == nil {
+			t.Errorf("NewJWTService(%d byte secret) error = nil", len(secret))
+		}
+	}
+}
+
+func TestJWTServiceTokens(t *testing.T) {
+	user := &entity.User{ID: 42}
+	hs256 := newTestService(t, Config{Algorithm: AlgorithmHS256, Secret: testSecret})
+	rs256 := newTestService(t, Config{Algorithm: AlgorithmRS256, PrivateKeyFile: writeRSAKey(t)})
+	otherIssuer := newTestService(t, Config{Algorithm: AlgorithmHS256, Secret: testSecret, Issuer: "other"})
+	otherSecret := newTestService(t, Config{Algorithm: AlgorithmHS256, Secret: strings.ToUpper(testSecret)})
+	expired := newTestService(t, Config{Algorithm: AlgorithmHS256, Secret: testSecret, AccessTTL: -time.Minute, RefreshTTL: -time.Minute})
+
+	issue := func(service interfaces.TokenService, refresh bool) string {
+		t.Helper()
+		issueToken := service.IssueAccessToken
+		if refresh {
+			issueToken = service.IssueRefreshToken
+		}
+		token, _, err := issueToken(user)
+		if err != nil {
+			t.Fatalf("issue token error = %v", err)
+		}
+		return token
+	}
+
+	tests := []struct {
+		name    string
+		service interfaces.TokenService
+		token   string
+		refresh bool
+		wantErr bool
+	}{
+		{name: "HS256 access token", service: hs256, token: issue(hs256, false)},
+		{name: "HS256 refresh token", service: hs256, token: issue(hs256, true), refresh: true},
+		{name: "RS256 access token", service: rs256, token: issue(rs256, false)},
+		{name: "RS256 refresh token", service: rs256, token: issue(rs256, true), refresh: true},
+		{name: "refresh token used as access token", service: hs256, token: issue(hs256, true), wantErr: true},
+		{name: "access token used as refresh token", service: hs256, token: issue(hs256, false), refresh: true, wantErr: true},
+		{name: "expired access token", service: hs256, token: issue(expired, false), wantErr: true},
+		{name: "expired refresh token", service: hs256, token: issue(expired, true), refresh: true, wantErr: true},
+		{name: "other issuer", service: hs256, token: issue(otherIssuer, false), wantErr: true},
+		{name: "other secret", service: hs256, token: issue(otherSecret, false), wantErr: true},
+		{name: "RS256 token on HS256 service", service: hs256, token: issue(rs256, false), wantErr: true},
+		{name: "HS256 token on RS256 service", service: rs256, token: issue(hs256, false), wantErr: true},
+		{name: "tampered token", service: hs256, token: issue(hs256, false) + "x", wantErr: true},
+		{name: "garbage", service: hs256, token: "not-a-token", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parse := tt.service.ParseAccessToken
+			if tt.refresh {
+				parse = tt.service.ParseRefreshToken
+			}
+			claims, err := parse(tt.token)
+			if tt.wantErr {
+				if !errors.Is(err, entity.ErrInvalidToken) {
+					t.Errorf("parse error = %v, want %v", err, entity.ErrInvalidToken)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse error = %v", err)
+			}
+			if claims.UserID != user.ID {
+				t.Errorf("UserID = %d, want %d", claims.UserID, user.ID)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"errors"
+	"go-clean-architecture/internal/entity"
+	"go-clean-architecture/internal/usecase/interfaces"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// bcryptHasher implements the PasswordHasher interface with bcrypt
+type bcryptHasher struct {
+	cost int
+}
+
+// NewBcryptHasher creates a new bcrypt password hasher.
+// A cost of 0 uses bcrypt.DefaultCost.
+func NewBcryptHasher(cost int) interfaces.PasswordHasher {
+	if cost == 0 {
+		cost = bcrypt.DefaultCost
+	}
+	return &bcryptHasher{
+		cost: cost,
+	}
+}
+
+// Hash returns the bcrypt hash of a password
+func (h *bcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// Compare checks a password against a bcrypt hash
+func (h *bcryptHasher) Compare(hash, password string) error {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return entity.ErrInvalidCredentials
+	}
+	return err
+}
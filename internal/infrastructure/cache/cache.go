@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"fmt"
+	"go-clean-architecture/internal/usecase/interfaces"
+	"time"
+)
+
+// Supported cache drivers
+const (
+	DriverNone   = "none"
+	DriverMemory = "memory"
+	DriverRedis  = "redis"
+)
+
+// Config holds cache configuration
+type Config struct {
+	// Driver is one of "none", "memory" or "redis"
+	Driver string
+	// TTL bounds how long a cached user may be served
+	TTL time.Duration
+	// NegativeTTL bounds how long a not-found result may be served
+	NegativeTTL time.Duration
+	// Size is the maximum number of entries of the in-memory cache
+	Size int
+	// RedisURL is a redis:// URL used by the redis driver
+	RedisURL string
+}
+
+// New creates the cache backend for the configured driver.
+// It returns nil for the "none" driver.
+func New(config *Config) (interfaces.Cache, error) {
+	switch config.Driver {
+	case DriverNone, "":
+		return nil, nil
+	case DriverMemory:
+		return NewLRUCache(config.Size)
+	case DriverRedis:
+		return NewRedisCacheFromURL(config.RedisURL)
+	default:
+		return nil, fmt.Errorf("unsupported CACHE_DRIVER %q", config.Driver)
+	}
+}
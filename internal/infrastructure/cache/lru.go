@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"context"
+	"go-clean-architecture/internal/usecase/interfaces"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+// lruEntry is a cached value with its own expiry
+type lruEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// lruCache is an in-process cache evicting the least recently used entries
+type lruCache struct {
+	entries *lru.Cache[string, lruEntry]
+}
+
+// NewLRUCache creates an in-process cache holding at most size entries
+func NewLRUCache(size int) (interfaces.Cache, error) {
+	entries, err := lru.New[string, lruEntry](size)
+	if err != nil {
+		return nil, err
+	}
+	return &lruCache{entries: entries}, nil
+}
+
+// Get returns an unexpired value
+func (c *lruCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	entry, ok := c.entries.Get(key)
+	if !ok {
+		return nil, false, nil
+	}
+	if time.Now().After(entry.expiresAt) {
+		c.entries.Remove(key)
+		return nil, false, nil
+	}
+	return entry.value, true, nil
+}
+
+// Set stores a value until ttl elapses
+func (c *lruCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	c.entries.Add(key, lruEntry{value: value, expiresAt: time.Now().Add(ttl)})
+	return nil
+}
+
+// Delete removes the keys
+func (c *lruCache) Delete(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		c.entries.Remove(key)
+	}
+	return nil
+}
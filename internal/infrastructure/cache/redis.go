@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-clean-architecture/internal/usecase/interfaces"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisCache stores values in Redis so every instance shares them
+type redisCache struct {
+	client redis.UniversalClient
+}
+
+// NewRedisCache creates a cache backed by the given Redis client
+func NewRedisCache(client redis.UniversalClient) interfaces.Cache {
+	return &redisCache{client: client}
+}
+
+// NewRedisCacheFromURL creates a cache connected to a redis:// URL
+func NewRedisCacheFromURL(url string) (interfaces.Cache, error) {
+	options, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid REDIS_URL: %w", err)
+	}
+	return NewRedisCache(redis.NewClient(options)), nil
+}
+
+// Get returns a value, treating redis.Nil as a miss
+func (c *redisCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := c.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// Set stores a value until ttl elapses
+func (c *redisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.client.Set(ctx, key, value, ttl).Err()
+}
+
+// Delete removes the keys
+func (c *redisCache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return c.client.Del(ctx, keys...).Err()
+}
+
+// Close releases the Redis connections
+func (c *redisCache) Close() error {
+	return c.client.Close()
+}
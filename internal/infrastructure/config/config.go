@@ -0,0 +1,279 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"go-clean-architecture/internal/infrastructure/auth"
+	"go-clean-architecture/internal/infrastructure/cache"
+	"go-clean-architecture/internal/infrastructure/cors"
+	"go-clean-architecture/internal/infrastructure/database"
+	"go-clean-architecture/internal/infrastructure/idempotency"
+	"go-clean-architecture/internal/infrastructure/logger"
+	"go-clean-architecture/internal/infrastructure/outbox"
+	"go-clean-architecture/internal/infrastructure/ratelimit"
+	"go-clean-architecture/internal/infrastructure/server"
+	"go-clean-architecture/internal/infrastructure/tracing"
+	"go-clean-architecture/internal/infrastructure/webhook"
+	"go-clean-architecture/pkg/response"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Sources a setting can come from, in increasing precedence
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+)
+
+// Supported repository drivers
+const (
+	RepositoryGorm   = "gorm"
+	RepositoryMemory = "memory"
+)
+
+// Config holds the configuration of every component of the server
+type Config struct {
+	Server server.Config
+	// Repository selects the storage, "gorm" for the database or "memory"
+	Repository string
+	Database   database.Config
+	JWT        auth.Config
+	Admin      Admin
+	Log        logger.Config
+	Tracing    tracing.Config
+	// RedisURL is shared by every component using the redis driver
+	RedisURL    string
+	Cache       cache.Config
+	Idempotency idempotency.Config
+	RateLimit   ratelimit.Config
+	CORS        cors.Config
+	Outbox      outbox.Config
+	Webhook     webhook.Config
+	// ErrorFormat is "problem" or "envelope", see response.SetErrorFormat
+	ErrorFormat string
+	// CursorSecret signs pagination cursors
+	CursorSecret string
+	// DevMode allows running without JWT_SECRET by signing with a random
+	// key, which must never be used in production
+	DevMode bool
+
+	// sources records where each setting was read from, by key
+	sources map[string]string
+}
+
+// Admin is the account created on startup when Email is set
+type Admin struct {
+	Email    string
+	Name     string
+	Password string
+}
+
+// Default returns the configuration used when nothing is set
+func Default() *Config {
+	return &Config{
+		Server: server.Config{
+			Port:            "8080",
+			Mode:            "release",
+			RequestTimeout:  30 * time.Second,
+			ReadTimeout:     15 * time.Second,
+			WriteTimeout:    15 * time.Second,
+			IdleTimeout:     60 * time.Second,
+			ShutdownTimeout: 30 * time.Second,
+		},
+		Repository: RepositoryGorm,
+		Database: database.Config{
+			Driver:        database.DriverPostgres,
+			Host:          "localhost",
+			Port:          "5432",
+			User:          "postgres",
+			Password:      "password",
+			DBName:        "userservice",
+			SSLMode:       "disable",
+			TimeZone:      "UTC",
+			TxMaxAttempts: 3,
+
+			ConnectTimeout:    time.Minute,
+			ConnectMinBackoff: 500 * time.Millisecond,
+			ConnectMaxBackoff: 10 * time.Second,
+
+			MaxOpenConns:         25,
+			MaxIdleConns:         10,
+			ConnMaxLifetime:      30 * time.Minute,
+			ConnMaxIdleTime:      5 * time.Minute,
+			ReplicaCheckInterval: 5 * time.Second,
+		},
+		JWT: auth.Config{
+			Algorithm:  auth.AlgorithmHS256,
+			Issuer:     "go-clean-architecture",
+			AccessTTL:  15 * time.Minute,
+			RefreshTTL: 7 * 24 * time.Hour,
+		},
+		Admin: Admin{Name: "Administrator"},
+		Log: logger.Config{
+			Level:  "info",
+			Format: logger.FormatJSON,
+		},
+		Tracing: tracing.Config{
+			Exporter:    tracing.ExporterNone,
+			ServiceName: "go-clean-architecture",
+		},
+		RedisURL: "redis://localhost:6379/0",
+		Cache: cache.Config{
+			Driver:      cache.DriverNone,
+			TTL:         5 * time.Minute,
+			NegativeTTL: 30 * time.Second,
+			Size:        10000,
+		},
+		Idempotency: idempotency.Config{
+			Driver:  idempotency.DriverMemory,
+			TTL:     24 * time.Hour,
+			LockTTL: time.Minute,
+		},
+		RateLimit: ratelimit.Config{
+			Driver: ratelimit.DriverMemory,
+			Limits: map[string]ratelimit.Limit{
+				ratelimit.GroupAuth:         {Burst: 10, Period: time.Minute},
+				ratelimit.GroupRegistration: {Burst: 5, Period: time.Minute},
+				ratelimit.GroupUsers:        {Burst: 300, Period: time.Minute},
+				ratelimit.GroupWebhooks:     {Burst: 60, Period: time.Minute},
+			},
+		},
+		CORS: cors.Config{
+			AllowedMethods: []string{
+				http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+			},
+			AllowedHeaders: []string{
+				"Accept", "Authorization", "Content-Type", "Idempotency-Key", "If-Match", "If-None-Match",
+				"X-API-Key", "X-Request-ID", "X-Requested-With", "traceparent", "tracestate",
+			},
+			ExposedHeaders: []string{
+				"ETag", "Idempotent-Replayed", "RateLimit-Limit", "RateLimit-Remaining", "Retry-After", "X-Request-ID",
+			},
+			MaxAge: 10 * time.Minute,
+		},
+		Outbox: outbox.Config{
+			PollInterval: time.Second,
+			BatchSize:    100,
+			MinBackoff:   time.Second,
+			MaxBackoff:   5 * time.Minute,
+		},
+		Webhook: webhook.Config{
+			PollInterval: time.Second,
+			BatchSize:    50,
+			Timeout:      10 * time.Second,
+			MaxAttempts:  8,
+			MinBackoff:   5 * time.Second,
+			MaxBackoff:   time.Hour,
+		},
+		ErrorFormat: response.FormatEnvelope,
+	}
+}
+
+// Load reads the configuration from, in increasing precedence, defaults,
+// the YAML or TOML file named by --config or CONFIG_FILE, environment
+// variables and command-line flags, then validates it. It returns the
+// arguments left after the flags.
+//
+// Every invalid setting is reported in the returned error. The config is
+// returned even then, holding everything that could be read, so it can
+// still be printed.
+func Load(args []string) (*Config, []string, error) {
+	config := Default()
+	config.sources = make(map[string]string)
+	options := config.options()
+
+	// Flags are recorded first and applied last so they win over the file
+	flags := flag.NewFlagSet("server", flag.ContinueOnError)
+	file := flags.String("config", os.Getenv("CONFIG_FILE"), "YAML or TOML configuration `file` ($CONFIG_FILE)")
+	flagValues := make(map[string]string)
+	for _, o := range options {
+		flags.Var(&flagValue{option: o, values: flagValues}, o.key, o.usage+" ($"+o.env+")")
+	}
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: server [flags] [migrate|config] ...\n\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	var errs []error
+	if *file != "" {
+		settings, err := readFile(*file)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		for _, o := range options {
+			if value, ok := settings[o.key]; ok {
+				errs = append(errs, config.apply(o, value, SourceFile, o.key+" in "+*file))
+				delete(settings, o.key)
+			}
+		}
+		unknown := make([]string, 0, len(settings))
+		for key := range settings {
+			unknown = append(unknown, key)
+		}
+		sort.Strings(unknown)
+		for _, key := range unknown {
+			errs = append(errs, fmt.Errorf("%s: unknown setting %q", *file, key))
+		}
+	}
+	for _, o := range options {
+		if value := os.Getenv(o.env); value != "" {
+			errs = append(errs, config.apply(o, value, SourceEnv, o.env))
+		}
+	}
+	for _, o := range options {
+		if value, ok := flagValues[o.key]; ok {
+			errs = append(errs, config.apply(o, value, SourceFlag, "--"+o.key))
+		}
+	}
+
+	config.resolve()
+	errs = append(errs, config.Validate())
+	return config, flags.Args(), errors.Join(errs...)
+}
+
+// apply sets an option and records its source
+func (c *Config) apply(o *option, value, source, name string) error {
+	if err := o.set(value); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	c.sources[o.key] = source
+	return nil
+}
+
+// resolve normalizes settings and copies shared ones into the components
+// using them
+func (c *Config) resolve() {
+	c.Tracing.Exporter = strings.ToLower(c.Tracing.Exporter)
+	c.Cache.RedisURL = c.RedisURL
+	c.Idempotency.RedisURL = c.RedisURL
+	c.RateLimit.RedisURL = c.RedisURL
+}
+
+// Print writes every setting with its source as a table, redacting secrets
+func (c *Config) Print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tENV\tSOURCE\tVALUE")
+	for _, o := range c.options() {
+		value := o.get()
+		if o.secret && value != "" {
+			value = "[redacted]"
+		}
+		source := c.sources[o.key]
+		if source == "" {
+			source = SourceDefault
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", o.key, o.env, source, value)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,211 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// Secrets long enough to pass validation
+const (
+	testJWTSecret    = "jwt-secret-0123456789abcdef012345"
+	testCursorSecret = "cursor-secret-0123456789abcdef012"
+)
+
+// isolateEnv clears every variable Load reads for the rest of the test
+func isolateEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("CONFIG_FILE", "")
+	for _, o := range Default().options() {
+		t.Setenv(o.env, "")
+	}
+}
+
+// writeFile writes a config file into a temporary directory
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	return path
+}
+
+// setting returns the printed value of key and where it came from
+func setting(t *testing.T, c *Config, key string) (string, string) {
+	t.Helper()
+	for _, o := range c.options() {
+		if o.key == key {
+			source := c.sources[key]
+			if source == "" {
+				source = SourceDefault
+			}
+			return o.get(), source
+		}
+	}
+	t.Fatalf("unknown setting %q", key)
+	return "", ""
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	files := map[string]string{
+		"config.yaml": `
+server:
+  port: 7000
+  cursor_secret: ` + testCursorSecret + `
+database:
+  host: file-host
+  replica_dsns: [host=a, host=b]
+jwt:
+  secret: ` + testJWTSecret + `
+log:
+  level: debug
+  format: text
+`,
+		"config.toml": `
+[server]
+port = 7000
+cursor_secret = "` + testCursorSecret + `"
+
+[database]
+host = "file-host"
+replica_dsns = ["host=a", "host=b"]
+
+[jwt]
+secret = "` + testJWTSecret + `"
+
+[log]
+level = "debug"
+format = "text"
+`,
+	}
+
+	tests := []struct {
+		key        string
+		wantValue  string
+		wantSource string
+	}{
+		{key: "server.port", wantValue: "7002", wantSource: SourceFlag},
+		{key: "log.level", wantValue: "warn", wantSource: SourceEnv},
+		{key: "log.format", wantValue: "text", wantSource: SourceFile},
+		{key: "database.host", wantValue: "file-host", wantSource: SourceFile},
+		{key: "database.replica_dsns", wantValue: "host=a,host=b", wantSource: SourceFile},
+		{key: "database.name", wantValue: "userservice", wantSource: SourceDefault},
+		{key: "rate_limit.auth", wantValue: "3/1m0s", wantSource: SourceEnv},
+	}
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			isolateEnv(t)
+			t.Setenv("PORT", "7001")
+			t.Setenv("LOG_LEVEL", "warn")
+			t.Setenv("RATE_LIMIT_AUTH", "3/m")
+			t.Setenv("CONFIG_FILE", writeFile(t, name, content))
+
+			config, args, err := Load([]string{"--server.port", "7002", "migrate", "up"})
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if strings.Join(args, " ") != "migrate up" {
+				t.Errorf("Load() args = %q, want %q", args, "migrate up")
+			}
+			for _, tt := range tests {
+				value, source := setting(t, config, tt.key)
+				if value != tt.wantValue || source != tt.wantSource {
+					t.Errorf("%s = %q from %s, want %q from %s", tt.key, value, source, tt.wantValue, tt.wantSource)
+				}
+			}
+			if config.Cache.RedisURL != config.RedisURL {
+				t.Errorf("Cache.RedisURL = %q, want the shared %q", config.Cache.RedisURL, config.RedisURL)
+			}
+		})
+	}
+}
+
+func TestLoadReportsEveryError(t *testing.T) {
+	isolateEnv(t)
+	t.Setenv("PORT", "http")
+	t.Setenv("LOG_LEVEL", "loud")
+	t.Setenv("DB_TX_MAX_ATTEMPTS", "three")
+	file := writeFile(t, "config.yaml", `
+server:
+  request_timeout: soon
+  colour: blue
+`)
+
+	config, _, err := Load([]string{"--config", file, "--cache.driver", "disk"})
+	if err == nil {
+		t.Fatal("Load() error = nil")
+	}
+	if config == nil {
+		t.Fatal("Load() config = nil, want the settings read so far")
+	}
+	for _, want := range []string{
+		"server.request_timeout in " + file,
+		`unknown setting "server.colour"`,
+		"DB_TX_MAX_ATTEMPTS",
+		"server.port (PORT): must be a port number",
+		"log.level (LOG_LEVEL)",
+		"cache.driver (CACHE_DRIVER)",
+		"jwt.secret (JWT_SECRET): must be at least 32 bytes",
+		"server.cursor_secret (CURSOR_SECRET): must be at least 32 bytes",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Load() error is missing %q:\n%v", want, err)
+		}
+	}
+}
+
+func TestPrintRedactsSecrets(t *testing.T) {
+	isolateEnv(t)
+	secrets := map[string]string{
+		"JWT_SECRET":      testJWTSecret,
+		"CURSOR_SECRET":   testCursorSecret,
+		"DB_PASSWORD":     "db-password",
+		"DB_DSN":          "host=db password=dsn-password",
+		"DB_REPLICA_DSNS": "host=replica password=replica-password",
+		"REDIS_URL":       "redis://:redis-password@localhost:6379/0",
+	}
+	for env, value := range secrets {
+		t.Setenv(env, value)
+	}
+	t.Setenv("DB_HOST", "db.internal")
+
+	config, _, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	var out strings.Builder
+	if err := config.Print(&out); err != nil {
+		t.Fatalf("Print() error = %v", err)
+	}
+
+	for env, value := range secrets {
+		if strings.Contains(out.String(), value) {
+			t.Errorf("Print() shows %s", env)
+		}
+	}
+	// Rows are key, variable, source and value, which is empty when unset
+	rows := make(map[string][]string)
+	for _, line := range strings.Split(out.String(), "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			rows[fields[0]] = fields[2:]
+		}
+	}
+	tests := []struct {
+		key  string
+		want []string
+	}{
+		{key: "jwt.secret", want: []string{SourceEnv, "[redacted]"}},
+		{key: "database.replica_dsns", want: []string{SourceEnv, "[redacted]"}},
+		{key: "database.host", want: []string{SourceEnv, "db.internal"}},
+		{key: "admin.password", want: []string{SourceDefault}},
+	}
+	for _, tt := range tests {
+		if got := rows[tt.key]; !slices.Equal(got, tt.want) {
+			t.Errorf("Print() %s = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
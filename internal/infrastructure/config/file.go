@@ -0,0 +1,63 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// readFile reads a YAML or TOML file, chosen by extension, into settings
+// keyed like options, such as "database.host"
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var tree map[string]any
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &tree)
+	case ".toml":
+		err = toml.Unmarshal(data, &tree)
+	default:
+		return nil, fmt.Errorf("unsupported config file extension %q, expected .yaml, .yml or .toml", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	settings := make(map[string]string)
+	flatten(settings, "", tree)
+	return settings, nil
+}
+
+// flatten joins nested tables into dotted keys. Lists become the comma
+// separated form accepted from environment variables.
+func flatten(settings map[string]string, prefix string, tree map[string]any) {
+	for name, value := range tree {
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+
+		switch value := value.(type) {
+		case map[string]any:
+			flatten(settings, key, value)
+		case []any:
+			items := make([]string, 0, len(value))
+			for _, item := range value {
+				items = append(items, fmt.Sprint(item))
+			}
+			settings[key] = strings.Join(items, ",")
+		case nil:
+			settings[key] = ""
+		default:
+			settings[key] = fmt.Sprint(value)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package config
+
+import (
+	"fmt"
+	"go-clean-architecture/internal/infrastructure/ratelimit"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// option binds a setting to its file key, which is also its flag name, and
+// its environment variable
+type option struct {
+	key    string
+	env    string
+	usage  string
+	secret bool
+	isBool bool
+	set    func(string) error
+	get    func() string
+}
+
+// options lists every setting bound to the fields of c
+func (c *Config) options() []*option {
+	options := []*option{
+		stringOption("server.port", "PORT", &c.Server.Port, "HTTP listen port"),
+		stringOption("server.mode", "GIN_MODE", &c.Server.Mode, "gin mode, debug or release"),
+		durationOption("server.request_timeout", "SERVER_REQUEST_TIMEOUT", &c.Server.RequestTimeout, "deadline of every request"),
+		durationOption("server.read_timeout", "SERVER_READ_TIMEOUT", &c.Server.ReadTimeout, "timeout for reading a request"),
+		durationOption("server.write_timeout", "SERVER_WRITE_TIMEOUT", &c.Server.WriteTimeout, "timeout for writing a response"),
+		durationOption("server.idle_timeout", "SERVER_IDLE_TIMEOUT", &c.Server.IdleTimeout, "keep-alive idle timeout"),
+		durationOption("server.shutdown_timeout", "SERVER_SHUTDOWN_TIMEOUT", &c.Server.ShutdownTimeout, "time given to in-flight requests on shutdown"),
+		listOption("server.trusted_proxies", "SERVER_TRUSTED_PROXIES", &c.Server.TrustedProxies, "proxy IPs or CIDRs allowed to set X-Forwarded-For"),
+		stringOption("server.error_format", "ERROR_FORMAT", &c.ErrorFormat, "default error body format, envelope or problem"),
+		secretOption("server.cursor_secret", "CURSOR_SECRET", &c.CursorSecret, "key signing pagination cursors"),
+		boolOption("server.dev_mode", "DEV_MODE", &c.DevMode, "use a random JWT secret when none is set, never in production"),
+
+		stringOption("repository.driver", "REPOSITORY_DRIVER", &c.Repository, "storage, gorm or memory"),
+
+		stringOption("database.driver", "DB_DRIVER", &c.Database.Driver, "database driver, postgres or sqlite"),
+		secretOption("database.dsn", "DB_DSN", &c.Database.DSN, "DSN used verbatim instead of the fields below"),
+		stringOption("database.host", "DB_HOST", &c.Database.Host, "database host"),
+		stringOption("database.port", "DB_PORT", &c.Database.Port, "database port"),
+		stringOption("database.user", "DB_USER", &c.Database.User, "database user"),
+		secretOption("database.password", "DB_PASSWORD", &c.Database.Password, "database password"),
+		stringOption("database.name", "DB_NAME", &c.Database.DBName, "database name"),
+		stringOption("database.ssl_mode", "DB_SSL_MODE", &c.Database.SSLMode, "postgres sslmode"),
+		stringOption("database.timezone", "DB_TIMEZONE", &c.Database.TimeZone, "postgres session time zone"),
+		stringOption("database.tx_isolation", "DB_TX_ISOLATION", &c.Database.TxIsolation, "transaction isolation level"),
+		intOption("database.tx_max_attempts", "DB_TX_MAX_ATTEMPTS", &c.Database.TxMaxAttempts, "attempts on serialization failures"),
+		boolOption("database.migrate_on_start", "DB_MIGRATE_ON_START", &c.Database.MigrateOnStart, "apply pending migrations on startup"),
+		durationOption("database.connect_timeout", "DB_CONNECT_TIMEOUT", &c.Database.ConnectTimeout, "how long startup waits for the database before exiting"),
+		durationOption("database.connect_min_backoff", "DB_CONNECT_MIN_BACKOFF", &c.Database.ConnectMinBackoff, "first delay between connection attempts"),
+		durationOption("database.connect_max_backoff", "DB_CONNECT_MAX_BACKOFF", &c.Database.ConnectMaxBackoff, "maximum delay between connection attempts"),
+		intOption("database.max_open_conns", "DB_MAX_OPEN_CONNS", &c.Database.MaxOpenConns, "maximum open connections per pool, 0 for no limit"),
+		intOption("database.max_idle_conns", "DB_MAX_IDLE_CONNS", &c.Database.MaxIdleConns, "maximum idle connections per pool"),
+		durationOption("database.conn_max_lifetime", "DB_CONN_MAX_LIFETIME", &c.Database.ConnMaxLifetime, "maximum age of a connection, 0 for no limit"),
+		durationOption("database.conn_max_idle_time", "DB_CONN_MAX_IDLE_TIME", &c.Database.ConnMaxIdleTime, "maximum idle time of a connection, 0 for no limit"),
+		secretListOption("database.replica_dsns", "DB_REPLICA_DSNS", &c.Database.ReplicaDSNs, "DSNs of read replicas"),
+		durationOption("database.replica_check_interval", "DB_REPLICA_CHECK_INTERVAL", &c.Database.ReplicaCheckInterval, "how often replicas are pinged"),
+
+		stringOption("jwt.algorithm", "JWT_ALGORITHM", &c.JWT.Algorithm, "signing algorithm, HS256 or RS256"),
+		secretOption("jwt.secret", "JWT_SECRET", &c.JWT.Secret, "HS256 signing key"),
+		stringOption("jwt.private_key_file", "JWT_PRIVATE_KEY_FILE", &c.JWT.PrivateKeyFile, "RS256 private key PEM file"),
+		stringOption("jwt.public_key_file", "JWT_PUBLIC_KEY_FILE", &c.JWT.PublicKeyFile, "RS256 public key PEM file"),
+		stringOption("jwt.issuer", "JWT_ISSUER", &c.JWT.Issuer, "token issuer"),
+		durationOption("jwt.access_ttl", "JWT_ACCESS_TTL", &c.JWT.AccessTTL, "access token lifetime"),
+		durationOption("jwt.refresh_ttl", "JWT_REFRESH_TTL", &c.JWT.RefreshTTL, "refresh token lifetime"),
+
+		stringOption("admin.email", "ADMIN_EMAIL", &c.Admin.Email, "email of the admin created on startup"),
+		stringOption("admin.name", "ADMIN_NAME", &c.Admin.Name, "name of the admin created on startup"),
+		secretOption("admin.password", "ADMIN_PASSWORD", &c.Admin.Password, "password of the admin created on startup"),
+
+		stringOption("log.level", "LOG_LEVEL", &c.Log.Level, "debug, info, warn or error"),
+		stringOption("log.format", "LOG_FORMAT", &c.Log.Format, "json or text"),
+
+		stringOption("tracing.exporter", "OTEL_TRACES_EXPORTER", &c.Tracing.Exporter, "span exporter, none, stdout or otlp"),
+		stringOption("tracing.service_name", "OTEL_SERVICE_NAME", &c.Tracing.ServiceName, "service name on spans"),
+
+		secretOption("redis.url", "REDIS_URL", &c.RedisURL, "redis:// URL shared by redis drivers"),
+
+		stringOption("cache.driver", "CACHE_DRIVER", &c.Cache.Driver, "user cache, none, memory or redis"),
+		durationOption("cache.ttl", "CACHE_TTL", &c.Cache.TTL, "lifetime of cached users"),
+		durationOption("cache.negative_ttl", "CACHE_NEGATIVE_TTL", &c.Cache.NegativeTTL, "lifetime of cached misses"),
+		intOption("cache.size", "CACHE_SIZE", &c.Cache.Size, "entries of the memory cache"),
+
+		stringOption("idempotency.store", "IDEMPOTENCY_STORE", &c.Idempotency.Driver, "memory or redis"),
+		durationOption("idempotency.ttl", "IDEMPOTENCY_TTL", &c.Idempotency.TTL, "how long responses are replayed"),
+		durationOption("idempotency.lock_ttl", "IDEMPOTENCY_LOCK_TTL", &c.Idempotency.LockTTL, "how long an unfinished request holds its key"),
+
+		stringOption("rate_limit.store", "RATE_LIMIT_STORE", &c.RateLimit.Driver, "memory or redis"),
+		boolOption("rate_limit.trust_api_key", "RATE_LIMIT_TRUST_API_KEY", &c.RateLimit.TrustAPIKey, "key anonymous clients by X-API-Key"),
+	}
+	for _, group := range []string{ratelimit.GroupAuth, ratelimit.GroupRegistration, ratelimit.GroupUsers, ratelimit.GroupWebhooks} {
+		options = append(options, limitOption("rate_limit."+group, "RATE_LIMIT_"+strings.ToUpper(group), c.RateLimit.Limits, group))
+	}
+
+	return append(options,
+		listOption("cors.allowed_origins", "CORS_ALLOWED_ORIGINS", &c.CORS.AllowedOrigins, "origins allowed to call the API"),
+		listOption("cors.allowed_methods", "CORS_ALLOWED_METHODS", &c.CORS.AllowedMethods, "methods allowed in preflights"),
+		listOption("cors.allowed_headers", "CORS_ALLOWED_HEADERS", &c.CORS.AllowedHeaders, "request headers allowed in preflights"),
+		listOption("cors.exposed_headers", "CORS_EXPOSED_HEADERS", &c.CORS.ExposedHeaders, "response headers readable by scripts"),
+		boolOption("cors.allow_credentials", "CORS_ALLOW_CREDENTIALS", &c.CORS.AllowCredentials, "allow cookies and Authorization"),
+		durationOption("cors.max_age", "CORS_MAX_AGE", &c.CORS.MaxAge, "preflight cache lifetime"),
+
+		durationOption("outbox.poll_interval", "OUTBOX_POLL_INTERVAL", &c.Outbox.PollInterval, "relay sleep when idle"),
+		intOption("outbox.batch_size", "OUTBOX_BATCH_SIZE", &c.Outbox.BatchSize, "messages published per poll"),
+		durationOption("outbox.min_backoff", "OUTBOX_MIN_BACKOFF", &c.Outbox.MinBackoff, "delay after the first failure"),
+		durationOption("outbox.max_backoff", "OUTBOX_MAX_BACKOFF", &c.Outbox.MaxBackoff, "maximum delay between attempts"),
+
+		durationOption("webhook.poll_interval", "WEBHOOK_POLL_INTERVAL", &c.Webhook.PollInterval, "dispatcher sleep when idle"),
+		intOption("webhook.batch_size", "WEBHOOK_BATCH_SIZE", &c.Webhook.BatchSize, "deliveries sent per poll"),
+		durationOption("webhook.timeout", "WEBHOOK_TIMEOUT", &c.Webhook.Timeout, "timeout of a delivery request"),
+		intOption("webhook.max_attempts", "WEBHOOK_MAX_ATTEMPTS", &c.Webhook.MaxAttempts, "attempts before a delivery is dead"),
+		durationOption("webhook.min_backoff", "WEBHOOK_MIN_BACKOFF", &c.Webhook.MinBackoff, "delay after the first failure"),
+		durationOption("webhook.max_backoff", "WEBHOOK_MAX_BACKOFF", &c.Webhook.MaxBackoff, "maximum delay between attempts"),
+	)
+}
+
+// stringOption binds a string setting
+func stringOption(key, env string, p *string, usage string) *option {
+	return &option{
+		key: key, env: env, usage: usage,
+		set: func(value string) error { *p = value; return nil },
+		get: func() string { return *p },
+	}
+}
+
+// secretOption binds a string setting that is redacted when printed
+func secretOption(key, env string, p *string, usage string) *option {
+	o := stringOption(key, env, p, usage)
+	o.secret = true
+	return o
+}
+
+// intOption binds an integer setting
+func intOption(key, env string, p *int, usage string) *option {
+	return &option{
+		key: key, env: env, usage: usage,
+		set: func(value string) error {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("invalid integer %q", value)
+			}
+			*p = parsed
+			return nil
+		},
+		get: func() string { return strconv.Itoa(*p) },
+	}
+}
+
+// boolOption binds a boolean setting
+func boolOption(key, env string, p *bool, usage string) *option {
+	return &option{
+		key: key, env: env, usage: usage, isBool: true,
+		set: func(value string) error {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid boolean %q", value)
+			}
+			*p = parsed
+			return nil
+		},
+		get: func() string { return strconv.FormatBool(*p) },
+	}
+}
+
+// durationOption binds a duration setting such as "30s"
+func durationOption(key, env string, p *time.Duration, usage string) *option {
+	return &option{
+		key: key, env: env, usage: usage,
+		set: func(value string) error {
+			parsed, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("invalid duration %q", value)
+			}
+			*p = parsed
+			return nil
+		},
+		get: func() string { return p.String() },
+	}
+}
+
+// listOption binds a comma separated list setting
+func listOption(key, env string, p *[]string, usage string) *option {
+	return &option{
+		key: key, env: env, usage: usage,
+		set: func(value string) error {
+			var values []string
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					values = append(values, item)
+				}
+			}
+			*p = values
+			return nil
+		},
+		get: func() string { return strings.Join(*p, ",") },
+	}
+}
+
+// secretListOption binds a comma separated list setting that is redacted
+// when printed
+func secretListOption(key, env string, p *[]string, usage string) *option {
+	o := listOption(key, env, p, usage)
+	o.secret = true
+	return o
+}
+
+// limitOption binds the rate limit of a route group, see ratelimit.ParseLimit
+func limitOption(key, env string, limits map[string]ratelimit.Limit, group string) *option {
+	return &option{
+		key: key, env: env, usage: "rate limit of the " + group + " routes, <requests>/<period> or off",
+		set: func(value string) error {
+			limit, err := ratelimit.ParseLimit(value)
+			if err != nil {
+				return err
+			}
+			limits[group] = limit
+			return nil
+		},
+		get: func() string { return limits[group].String() },
+	}
+}
+
+// flagValue records a flag so it can be applied after the file and the
+// environment
+type flagValue struct {
+	option *option
+	values map[string]string
+}
+
+// String returns the current value, which flag shows as the default
+func (f *flagValue) String() string {
+	if f == nil || f.option == nil {
+		return ""
+	}
+	if f.option.secret {
+		return ""
+	}
+	return f.option.get()
+}
+
+// Set records the raw flag value
+func (f *flagValue) Set(value string) error {
+	f.values[f.option.key] = value
+	return nil
+}
+
+// IsBoolFlag lets boolean flags be given without a value
+func (f *flagValue) IsBoolFlag() bool {
+	return f.option.isBool
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"go-clean-architecture/internal/infrastructure/auth"
+	"go-clean-architecture/internal/infrastructure/cache"
+	"go-clean-architecture/internal/infrastructure/database"
+	"go-clean-architecture/internal/infrastructure/idempotency"
+	"go-clean-architecture/internal/infrastructure/logger"
+	"go-clean-architecture/internal/infrastructure/ratelimit"
+	"go-clean-architecture/internal/infrastructure/tracing"
+	"go-clean-architecture/internal/usecase/interfaces"
+	"go-clean-architecture/pkg/cursor"
+	"go-clean-architecture/pkg/response"
+	"log/slog"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Validate checks every setting and reports all problems at once
+func (c *Config) Validate() error {
+	v := &validation{envs: make(map[string]string)}
+	for _, o := range c.options() {
+		v.envs[o.key] = o.env
+	}
+
+	port, err := strconv.Atoi(c.Server.Port)
+	v.check("server.port", err == nil && port > 0 && port < 65536, "must be a port number, got %q", c.Server.Port)
+	v.positive("server.request_timeout", c.Server.RequestTimeout)
+	v.positive("server.read_timeout", c.Server.ReadTimeout)
+	v.positive("server.write_timeout", c.Server.WriteTimeout)
+	v.positive("server.idle_timeout", c.Server.IdleTimeout)
+	v.positive("server.shutdown_timeout", c.Server.ShutdownTimeout)
+	for _, proxy := range c.Server.TrustedProxies {
+		_, _, cidrErr := net.ParseCIDR(proxy)
+		v.check("server.trusted_proxies", cidrErr == nil || net.ParseIP(proxy) != nil, "must be IPs or CIDRs, got %q", proxy)
+	}
+	v.oneOf("server.error_format", c.ErrorFormat, response.FormatProblem, response.FormatEnvelope)
+	v.check("server.cursor_secret", len(c.CursorSecret) >= cursor.MinSecretLength,
+		"must be at least %d bytes, got %d", cursor.MinSecretLength, len(c.CursorSecret))
+
+	v.oneOf("repository.driver", c.Repository, RepositoryGorm, RepositoryMemory)
+	if c.Repository == RepositoryGorm {
+		v.oneOf("database.driver", c.Database.Driver, database.DriverPostgres, database.DriverSQLite)
+		v.oneOf("database.tx_isolation", c.Database.TxIsolation,
+			string(interfaces.IsolationDefault), string(interfaces.IsolationReadCommitted),
+			string(interfaces.IsolationRepeatableRead), string(interfaces.IsolationSerializable))
+		v.atLeast("database.tx_max_attempts", c.Database.TxMaxAttempts, 1)
+		v.positive("database.connect_timeout", c.Database.ConnectTimeout)
+		v.positive("database.connect_min_backoff", c.Database.ConnectMinBackoff)
+		v.check("database.connect_max_backoff", c.Database.ConnectMaxBackoff >= c.Database.ConnectMinBackoff,
+			"must not be less than database.connect_min_backoff")
+		v.atLeast("database.max_open_conns", c.Database.MaxOpenConns, 0)
+		v.atLeast("database.max_idle_conns", c.Database.MaxIdleConns, 0)
+		v.check("database.conn_max_lifetime", c.Database.ConnMaxLifetime >= 0, "must not be negative")
+		v.check("database.conn_max_idle_time", c.Database.ConnMaxIdleTime >= 0, "must not be negative")
+		if len(c.Database.ReplicaDSNs) > 0 {
+			v.check("database.replica_dsns", c.Database.Driver == database.DriverPostgres, "are only supported with the postgres driver")
+			v.positive("database.replica_check_interval", c.Database.ReplicaCheckInterval)
+		}
+	}
+
+	v.oneOf("jwt.algorithm", c.JWT.Algorithm, auth.AlgorithmHS256, auth.AlgorithmRS256)
+	switch c.JWT.Algorithm {
+	case auth.AlgorithmHS256:
+		// Dev mode signs with a random key when no secret is set
+		if c.JWT.Secret != "" || !c.DevMode {
+			v.check("jwt.secret", len(c.JWT.Secret) >= auth.MinSecretLength,
+				"must be at least %d bytes for HS256, got %d", auth.MinSecretLength, len(c.JWT.Secret))
+		}
+	case auth.AlgorithmRS256:
+		v.check("jwt.private_key_file", c.JWT.PrivateKeyFile != "", "is required for RS256")
+	}
+	v.positive("jwt.access_ttl", c.JWT.AccessTTL)
+	v.positive("jwt.refresh_ttl", c.JWT.RefreshTTL)
+
+	if c.Admin.Email != "" {
+		v.check("admin.password", c.Admin.Password != "", "is required when admin.email is set")
+	}
+
+	var level slog.Level
+	v.check("log.level", level.UnmarshalText([]byte(c.Log.Level)) == nil, "must be debug, info, warn or error, got %q", c.Log.Level)
+	v.oneOf("log.format", strings.ToLower(c.Log.Format), logger.FormatJSON, logger.FormatText)
+	v.oneOf("tracing.exporter", strings.ToLower(c.Tracing.Exporter), tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterOTLP)
+
+	v.oneOf("cache.driver", c.Cache.Driver, cache.DriverNone, cache.DriverMemory, cache.DriverRedis)
+	if c.Cache.Driver != cache.DriverNone {
+		v.positive("cache.ttl", c.Cache.TTL)
+		v.positive("cache.negative_ttl", c.Cache.NegativeTTL)
+		v.atLeast("cache.size", c.Cache.Size, 1)
+	}
+
+	v.oneOf("idempotency.store", c.Idempotency.Driver, idempotency.DriverMemory, idempotency.DriverRedis)
+	v.positive("idempotency.ttl", c.Idempotency.TTL)
+	v.positive("idempotency.lock_ttl", c.Idempotency.LockTTL)
+	v.oneOf("rate_limit.store", c.RateLimit.Driver, ratelimit.DriverMemory, ratelimit.DriverRedis)
+
+	if err := c.CORS.Validate(); err != nil {
+		v.errs = append(v.errs, err)
+	}
+	v.check("cors.max_age", c.CORS.MaxAge >= 0, "must not be negative")
+
+	v.positive("outbox.poll_interval", c.Outbox.PollInterval)
+	v.atLeast("outbox.batch_size", c.Outbox.BatchSize, 1)
+	v.backoff("outbox", c.Outbox.MinBackoff, c.Outbox.MaxBackoff)
+
+	v.positive("webhook.poll_interval", c.Webhook.PollInterval)
+	v.atLeast("webhook.batch_size", c.Webhook.BatchSize, 1)
+	v.positive("webhook.timeout", c.Webhook.Timeout)
+	v.atLeast("webhook.max_attempts", c.Webhook.MaxAttempts, 1)
+	v.backoff("webhook", c.Webhook.MinBackoff, c.Webhook.MaxBackoff)
+
+	return errors.Join(v.errs...)
+}
+
+// validation collects problems naming each setting by key and variable
+type validation struct {
+	envs map[string]string
+	errs []error
+}
+
+// check records a problem with key unless ok
+func (v *validation) check(key string, ok bool, format string, args ...any) {
+	if !ok {
+		v.errs = append(v.errs, fmt.Errorf("%s (%s): %s", key, v.envs[key], fmt.Sprintf(format, args...)))
+	}
+}
+
+// oneOf checks that value is one of allowed
+func (v *validation) oneOf(key, value string, allowed ...string) {
+	v.check(key, slices.Contains(allowed, value), "must be one of %q, got %q", allowed, value)
+}
+
+// positive checks that a duration is greater than zero
+func (v *validation) positive(key string, value time.Duration) {
+	v.check(key, value > 0, "must be positive, got %s", value)
+}
+
+// atLeast checks that an integer is at least minimum
+func (v *validation) atLeast(key string, value, minimum int) {
+	v.check(key, value >= minimum, "must be at least %d, got %d", minimum, value)
+}
+
+// backoff checks the bounds of a retry backoff
+func (v *validation) backoff(prefix string, minDelay, maxDelay time.Duration) {
+	v.positive(prefix+".min_backoff", minDelay)
+	v.check(prefix+".max_backoff", maxDelay >= minDelay, "must not be less than %s.min_backoff", prefix)
+}
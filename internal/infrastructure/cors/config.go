@@ -0,0 +1,41 @@
+package cors
+
+import (
+	"fmt"
+	"time"
+)
+
+// Config holds CORS configuration
+type Config struct {
+	// AllowedOrigins lists origins such as "https://app.example.com".
+	// "https://*.example.com" matches any subdomain of example.com and "*"
+	// matches every origin. An empty list disables cross-origin requests.
+	AllowedOrigins []string
+	// AllowedMethods lists the methods a preflight may ask for
+	AllowedMethods []string
+	// AllowedHeaders lists the request headers a preflight may ask for
+	AllowedHeaders []string
+	// ExposedHeaders lists the response headers scripts may read
+	ExposedHeaders []string
+	// AllowCredentials lets browsers send cookies and Authorization
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache a preflight response
+	MaxAge time.Duration
+}
+
+// Validate checks that every origin pattern is well formed
+func (c *Config) Validate() error {
+	for _, origin := range c.AllowedOrigins {
+		if origin == "*" {
+			// Browsers refuse credentialed responses for a wildcard origin
+			if c.AllowCredentials {
+				return fmt.Errorf("CORS_ALLOWED_ORIGINS=* cannot be combined with CORS_ALLOW_CREDENTIALS=true")
+			}
+			continue
+		}
+		if _, err := parsePattern(origin); err != nil {
+			return fmt.Errorf("CORS_ALLOWED_ORIGINS: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package cors
+
+import (
+	"go-clean-architecture/pkg/response"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Policy applies a cross-origin resource sharing policy
+type Policy struct {
+	config         *Config
+	anyOrigin      bool
+	origins        []pattern
+	methods        map[string]bool
+	headers        map[string]bool
+	allowMethods   string
+	exposedHeaders string
+	maxAge         string
+}
+
+// NewPolicy creates a new CORS policy instance
+func NewPolicy(config *Config) (*Policy, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	policy := &Policy{
+		config:         config,
+		methods:        make(map[string]bool, len(config.AllowedMethods)),
+		headers:        make(map[string]bool, len(config.AllowedHeaders)),
+		allowMethods:   strings.Join(config.AllowedMethods, ", "),
+		exposedHeaders: strings.Join(config.ExposedHeaders, ", "),
+		maxAge:         strconv.Itoa(int(config.MaxAge.Seconds())),
+	}
+	for _, origin := range config.AllowedOrigins {
+		if origin == "*" {
+			policy.anyOrigin = true
+			continue
+		}
+		p, _ := parsePattern(origin)
+		policy.origins = append(policy.origins, p)
+	}
+	for _, method := range config.AllowedMethods {
+		policy.methods[strings.ToUpper(method)] = true
+	}
+	for _, header := range config.AllowedHeaders {
+		policy.headers[http.CanonicalHeaderKey(header)] = true
+	}
+	return policy, nil
+}
+
+// Middleware adds CORS headers for allowed origins and answers preflight
+// requests. Requests from other origins are served without CORS headers so
+// browsers block them, while preflights asking for an origin, method or
+// header outside the policy are rejected with 403.
+func (p *Policy) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Responses differ by origin, shared caches must key on it
+		c.Writer.Header().Add("Vary", "Origin")
+
+		origin := c.GetHeader("Origin")
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		if preflight {
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+		}
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		allowed := p.allowOrigin(origin)
+		if !preflight {
+			if allowed {
+				p.setOriginHeaders(c, origin)
+				if p.exposedHeaders != "" {
+					c.Header("Access-Control-Expose-Headers", p.exposedHeaders)
+				}
+			}
+			c.Next()
+			return
+		}
+
+		switch {
+		case !allowed:
+			p.reject(c, "Origin is not allowed")
+		case !p.methods[strings.ToUpper(c.GetHeader("Access-Control-Request-Method"))]:
+			p.reject(c, "Method is not allowed")
+		case !p.allowHeaders(c.GetHeader("Access-Control-Request-Headers")):
+			p.reject(c, "Request headers are not allowed")
+		default:
+			p.setOriginHeaders(c, origin)
+			c.Header("Access-Control-Allow-Methods", p.allowMethods)
+			if requested := c.GetHeader("Access-Control-Request-Headers"); requested != "" {
+				c.Header("Access-Control-Allow-Headers", requested)
+			}
+			c.Header("Access-Control-Max-Age", p.maxAge)
+			c.AbortWithStatus(http.StatusNoContent)
+		}
+	}
+}
+
+// allowOrigin reports whether the origin matches the policy
+func (p *Policy) allowOrigin(origin string) bool {
+	if p.anyOrigin {
+		return true
+	}
+	for _, pattern := range p.origins {
+		if pattern.matches(origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowHeaders reports whether every header in a comma separated
+// Access-Control-Request-Headers value is allowed
+func (p *Policy) allowHeaders(requested string) bool {
+	for _, header := range strings.Split(requested, ",") {
+		header = strings.TrimSpace(header)
+		if header != "" && !p.headers[http.CanonicalHeaderKey(header)] {
+			return false
+		}
+	}
+	return true
+}
+
+// setOriginHeaders allows the origin, echoing it unless every origin is
+// allowed without credentials
+func (p *Policy) setOriginHeaders(c *gin.Context, origin string) {
+	if p.anyOrigin && !p.config.AllowCredentials {
+		c.Header("Access-Control-Allow-Origin", "*")
+	} else {
+		c.Header("Access-Control-Allow-Origin", origin)
+	}
+	if p.config.AllowCredentials {
+		c.Header("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// reject answers a preflight the policy does not allow
+func (p *Policy) reject(c *gin.Context, message string) {
+	response.Error(c, http.StatusForbidden, "cors_rejected", message, nil)
+	c.Abort()
+}
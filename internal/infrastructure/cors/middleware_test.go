@@ -0,0 +1,207 @@
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// testConfig allows one origin and every subdomain of example.com
+func testConfig() *Config {
+	return &Config{
+		AllowedOrigins: []string{"https://app.test", "https://*.example.com"},
+		AllowedMethods: []string{"GET", "POST", "DELETE"},
+		AllowedHeaders: []string{"Authorization", "Content-Type"},
+		ExposedHeaders: []string{"ETag", "X-Request-ID"},
+		MaxAge:         10 * time.Minute,
+	}
+}
+
+// newRouter serves GET and DELETE /users behind the policy of config
+func newRouter(t *testing.T, config *Config) *gin.Engine {
+	t.Helper()
+	policy, err := NewPolicy(config)
+	if err != nil {
+		t.Fatalf("NewPolicy() error = %v", err)
+	}
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(policy.Middleware())
+	handler := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/users", handler)
+	router.DELETE("/users", handler)
+	router.OPTIONS("/users", handler)
+	return router
+}
+
+// send serves a request with the given headers
+func send(router *gin.Engine, method string, headers map[string]string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, "/users", nil)
+	for name, value := range headers {
+		r.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	return w
+}
+
+func TestMiddlewareSimpleRequests(t *testing.T) {
+	tests := []struct {
+		name        string
+		origin      string
+		wantAllowed bool
+	}{
+		{name: "same origin"},
+		{name: "allowed origin", origin: "https://app.test", wantAllowed: true},
+		{name: "allowed subdomain", origin: "https://api.example.com", wantAllowed: true},
+		{name: "apex of wildcard", origin: "https://example.com"},
+		{name: "lookalike", origin: "https://example.com.evil.com"},
+		{name: "other port", origin: "https://app.test:8443"},
+		{name: "other scheme", origin: "http://app.test"},
+	}
+
+	router := newRouter(t, testConfig())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := send(router, http.MethodGet, map[string]string{"Origin": tt.origin})
+
+			// Disallowed origins are still served, browsers block the response
+			if w.Code != http.StatusOK {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+			}
+			if got := w.Header().Values("Vary"); !slices.Equal(got, []string{"Origin"}) {
+				t.Errorf("Vary = %q, want Origin", got)
+			}
+
+			allowOrigin := w.Header().Get("Access-Control-Allow-Origin")
+			if !tt.wantAllowed {
+				if allowOrigin != "" || w.Header().Get("Access-Control-Expose-Headers") != "" {
+					t.Errorf("CORS headers = %v, want none", w.Header())
+				}
+				return
+			}
+			if allowOrigin != tt.origin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", allowOrigin, tt.origin)
+			}
+			if got := w.Header().Get("Access-Control-Expose-Headers"); got != "ETag, X-Request-ID" {
+				t.Errorf("Access-Control-Expose-Headers = %q", got)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+				t.Errorf("Access-Control-Allow-Credentials = %q without credentials", got)
+			}
+		})
+	}
+}
+
+func TestMiddlewarePreflight(t *testing.T) {
+	tests := []struct {
+		name       string
+		origin     string
+		method     string
+		headers    string
+		wantStatus int
+	}{
+		{name: "allowed", origin: "https://app.test", method: "DELETE", headers: "authorization, content-type", wantStatus: http.StatusNoContent},
+		{name: "allowed subdomain", origin: "https://api.example.com", method: "GET", wantStatus: http.StatusNoContent},
+		{name: "origin not allowed", origin: "https://evil.test", method: "GET", wantStatus: http.StatusForbidden},
+		{name: "port mismatch", origin: "https://app.test:8443", method: "GET", wantStatus: http.StatusForbidden},
+		{name: "apex of wildcard", origin: "https://example.com", method: "GET", wantStatus: http.StatusForbidden},
+		{name: "method not allowed", origin: "https://app.test", method: "PATCH", wantStatus: http.StatusForbidden},
+		{name: "header not allowed", origin: "https://app.test", method: "GET", headers: "Authorization, X-Debug", wantStatus: http.StatusForbidden},
+	}
+
+	router := newRouter(t, testConfig())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := map[string]string{"Origin": tt.origin, "Access-Control-Request-Method": tt.method}
+			if tt.headers != "" {
+				headers["Access-Control-Request-Headers"] = tt.headers
+			}
+			w := send(router, http.MethodOptions, headers)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			wantVary := []string{"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"}
+			if got := w.Header().Values("Vary"); !slices.Equal(got, wantVary) {
+				t.Errorf("Vary = %q, want %q", got, wantVary)
+			}
+
+			if tt.wantStatus == http.StatusForbidden {
+				if !strings.Contains(w.Body.String(), "cors_rejected") {
+					t.Errorf("body = %s, want cors_rejected", w.Body.String())
+				}
+				if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+					t.Errorf("Access-Control-Allow-Origin = %q on a rejected preflight", got)
+				}
+				return
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.origin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.origin)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Methods"); got != "GET, POST, DELETE" {
+				t.Errorf("Access-Control-Allow-Methods = %q", got)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Headers"); got != tt.headers {
+				t.Errorf("Access-Control-Allow-Headers = %q, want %q", got, tt.headers)
+			}
+			if got := w.Header().Get("Access-Control-Max-Age"); got != "600" {
+				t.Errorf("Access-Control-Max-Age = %q, want %q", got, "600")
+			}
+		})
+	}
+}
+
+func TestMiddlewareOptionsWithoutPreflight(t *testing.T) {
+	router := newRouter(t, testConfig())
+
+	// A plain OPTIONS request is not a preflight and reaches the handler
+	w := send(router, http.MethodOptions, map[string]string{"Origin": "https://evil.test"})
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestMiddlewareAnyOrigin(t *testing.T) {
+	tests := []struct {
+		name        string
+		credentials bool
+		wantOrigin  string
+	}{
+		{name: "without credentials", wantOrigin: "*"},
+		{name: "credentials echo the origin", credentials: true, wantOrigin: "https://anything.test"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testConfig()
+			config.AllowedOrigins = []string{"*"}
+			if tt.credentials {
+				// Validate refuses "*" with credentials, a pattern list must be used
+				config.AllowCredentials = true
+				if _, err := NewPolicy(config); err == nil {
+					t.Fatal("NewPolicy() error = nil for * with credentials")
+				}
+				config.AllowedOrigins = []string{"https://*.test"}
+			}
+			router := newRouter(t, config)
+
+			w := send(router, http.MethodGet, map[string]string{"Origin": "https://anything.test"})
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+			wantCredentials := ""
+			if tt.credentials {
+				wantCredentials = "true"
+			}
+			if got := w.Header().Get("Access-Control-Allow-Credentials"); got != wantCredentials {
+				t.Errorf("Access-Control-Allow-Credentials = %q, want %q", got, wantCredentials)
+			}
+		})
+	}
+}
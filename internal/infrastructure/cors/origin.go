@@ -0,0 +1,61 @@
+package cors
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// pattern is a parsed allowed origin. A pattern with a wildcard host
+// matches subdomains of host but not host itself.
+type pattern struct {
+	scheme   string
+	host     string
+	port     string
+	wildcard bool
+}
+
+// parsePattern parses "scheme://host[:port]" where host may start with "*."
+func parsePattern(origin string) (pattern, error) {
+	scheme, rest, ok := strings.Cut(strings.ToLower(origin), "://")
+	if !ok || scheme == "" || rest == "" || strings.ContainsAny(rest, "/?#@") {
+		return pattern{}, fmt.Errorf("invalid origin %q, expected scheme://host[:port]", origin)
+	}
+
+	p := pattern{scheme: scheme}
+	if strings.HasPrefix(rest, "*.") {
+		p.wildcard = true
+		rest = rest[2:]
+	}
+	p.host, p.port = splitHostPort(rest)
+	if p.host == "" || strings.Contains(p.host, "*") {
+		return pattern{}, fmt.Errorf("invalid origin %q, only a leading *. wildcard is supported", origin)
+	}
+	return p, nil
+}
+
+// matches reports whether a request Origin is allowed by the pattern
+func (p pattern) matches(origin string) bool {
+	u, err := url.Parse(strings.ToLower(origin))
+	if err != nil || u.Scheme != p.scheme || u.Host == "" || u.Path != "" {
+		return false
+	}
+
+	host, port := splitHostPort(u.Host)
+	if port != p.port {
+		return false
+	}
+	if p.wildcard {
+		return strings.HasSuffix(host, "."+p.host)
+	}
+	return host == p.host
+}
+
+// splitHostPort separates an optional port from a host, keeping IPv6
+// brackets in the host
+func splitHostPort(hostport string) (host, port string) {
+	if i := strings.LastIndexByte(hostport, ':'); i > strings.LastIndexByte(hostport, ']') {
+		return hostport[:i], hostport[i+1:]
+	}
+	return hostport, ""
+}
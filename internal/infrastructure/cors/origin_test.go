@@ -0,0 +1,93 @@
+package cors
+
+import "testing"
+
+func TestParsePattern(t *testing.T) {
+	tests := []struct {
+		origin  string
+		want    pattern
+		wantErr bool
+	}{
+		{origin: "https://example.com", want: pattern{scheme: "https", host: "example.com"}},
+		{origin: "HTTPS://Example.COM", want: pattern{scheme: "https", host: "example.com"}},
+		{origin: "http://localhost:3000", want: pattern{scheme: "http", host: "localhost", port: "3000"}},
+		{origin: "https://*.example.com", want: pattern{scheme: "https", host: "example.com", wildcard: true}},
+		{origin: "https://*.example.com:8443", want: pattern{scheme: "https", host: "example.com", port: "8443", wildcard: true}},
+		{origin: "http://[::1]:8080", want: pattern{scheme: "http", host: "[::1]", port: "8080"}},
+		{origin: "example.com", wantErr: true},
+		{origin: "://example.com", wantErr: true},
+		{origin: "https://", wantErr: true},
+		{origin: "https://example.com/", wantErr: true},
+		{origin: "https://example.com/app", wantErr: true},
+		{origin: "https://example.com?x=1", wantErr: true},
+		{origin: "https://user@example.com", wantErr: true},
+		{origin: "https://*", wantErr: true},
+		{origin: "https://*.", wantErr: true},
+		{origin: "https://app.*.example.com", wantErr: true},
+		{origin: "https://*example.com", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.origin, func(t *testing.T) {
+			got, err := parsePattern(tt.origin)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parsePattern() = %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parsePattern() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("parsePattern() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPatternMatches(t *testing.T) {
+	tests := []struct {
+		pattern string
+		origin  string
+		want    bool
+	}{
+		{pattern: "https://example.com", origin: "https://example.com", want: true},
+		{pattern: "https://example.com", origin: "https://EXAMPLE.com", want: true},
+		{pattern: "https://example.com", origin: "http://example.com"},
+		{pattern: "https://example.com", origin: "https://app.example.com"},
+		{pattern: "https://example.com", origin: "https://example.com.evil.com"},
+		{pattern: "https://example.com", origin: "https://example.com:8443"},
+		{pattern: "https://example.com", origin: "https://example.com/"},
+		{pattern: "https://example.com", origin: "null"},
+		{pattern: "https://example.com", origin: ""},
+		{pattern: "http://localhost:3000", origin: "http://localhost:3000", want: true},
+		{pattern: "http://localhost:3000", origin: "http://localhost:3001"},
+		{pattern: "http://localhost:3000", origin: "http://localhost"},
+		{pattern: "https://*.example.com", origin: "https://app.example.com", want: true},
+		{pattern: "https://*.example.com", origin: "https://a.b.example.com", want: true},
+		// A subdomain of example.com even though its labels read like another site
+		{pattern: "https://*.example.com", origin: "https://evil.com.example.com", want: true},
+		{pattern: "https://*.example.com", origin: "https://example.com"},
+		{pattern: "https://*.example.com", origin: "https://evilexample.com"},
+		{pattern: "https://*.example.com", origin: "https://app.example.com.evil.com"},
+		{pattern: "https://*.example.com", origin: "http://app.example.com"},
+		{pattern: "https://*.example.com", origin: "https://app.example.com:8443"},
+		{pattern: "https://*.example.com:8443", origin: "https://app.example.com:8443", want: true},
+		{pattern: "https://*.example.com:8443", origin: "https://app.example.com"},
+		{pattern: "http://[::1]:8080", origin: "http://[::1]:8080", want: true},
+		{pattern: "http://[::1]:8080", origin: "http://[::1]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.origin, func(t *testing.T) {
+			p, err := parsePattern(tt.pattern)
+			if err != nil {
+				t.Fatalf("parsePattern() error = %v", err)
+			}
+			if got := p.matches(tt.origin); got != tt.want {
+				t.Errorf("matches(%q) = %v, want %v", tt.origin, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,18 +1,32 @@
 package database
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
-	"go-clean-architecture/internal/entity"
-	"log"
-	"os"
+	"go-clean-architecture/pkg/backoff"
+	"log/slog"
+	"time"
 
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
+)
+
+// Supported database drivers
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
 )
 
 // Config holds database configuration
 type Config struct {
+	// Driver selects the database engine, either "postgres" or "sqlite"
+	Driver string
+	// DSN is used verbatim when set. For SQLite it is the database file path
+	// or ":memory:"; for Postgres it replaces the DSN built from the fields below.
+	DSN      string
 	Host     string
 	Port     string
 	User     string
@@ -20,66 +34,213 @@ type Config struct {
 	DBName   string
 	SSLMode  string
 	TimeZone string
-}
+	// TxIsolation is the isolation level of use case transactions:
+	// "read_committed", "repeatable_read", "serializable" or empty for the
+	// database default
+	TxIsolation string
+	// TxMaxAttempts bounds how often a transaction is run when it fails
+	// with a serialization failure
+	TxMaxAttempts int
+	// MigrateOnStart applies pending migrations when the server starts
+	MigrateOnStart bool
 
-// NewConfig creates database config from environment variables
-func NewConfig() *Config {
-	return &Config{
-		Host:     getEnv("DB_HOST", "localhost"),
-		Port:     getEnv("DB_PORT", "5432"),
-		User:     getEnv("DB_USER", "postgres"),
-		Password: getEnv("DB_PASSWORD", "password"),
-		DBName:   getEnv("DB_NAME", "userservice"),
-		SSLMode:  getEnv("DB_SSL_MODE", "disable"),
-		TimeZone: getEnv("DB_TIMEZONE", "UTC"),
-	}
+	// Connection pool limits, applied to the primary and every replica.
+	// Zero leaves the database/sql default.
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+
+	// ReplicaDSNs are Postgres DSNs of read replicas. Read-only queries go
+	// to a healthy replica, or to the primary when there is none.
+	ReplicaDSNs []string
+	// ReplicaCheckInterval is how often replicas are pinged
+	ReplicaCheckInterval time.Duration
+
+	// ConnectTimeout bounds how long startup waits for the database to
+	// answer. Attempts back off exponentially from ConnectMinBackoff up to
+	// ConnectMaxBackoff.
+	ConnectTimeout    time.Duration
+	ConnectMinBackoff time.Duration
+	ConnectMaxBackoff time.Duration
 }
 
-// Connect establishes database connection
-func Connect(config *Config) (*gorm.DB, error) {
-	dsn := fmt.Sprintf(
-		"host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=%s",
-		config.Host, config.User, config.Password, config.DBName, config.Port, config.SSLMode, config.TimeZone,
-	)
+// pingTimeout bounds a single connection attempt
+const pingTimeout = 5 * time.Second
 
-	// Set GORM logger level based on environment
-	logLevel := logger.Silent
-	if getEnv("GIN_MODE", "release") == "debug" {
-		logLevel = logger.Info
+// Connect opens the database and waits until it answers, see
+// WaitForConnection
+func Connect(ctx context.Context, config *Config) (*gorm.DB, error) {
+	db, err := Open(config)
+	if err != nil {
+		return nil, err
+	}
+	if err := WaitForConnection(ctx, db, config); err != nil {
+		closeAll([]*gorm.DB{db})
+		return nil, err
 	}
+	return db, nil
+}
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logLevel),
-	})
+// Open creates the connection pool without connecting, so it succeeds
+// while the database is still starting. Queries fail until it answers.
+func Open(config *Config) (*gorm.DB, error) {
+	dialector, err := config.dialector()
+	if err != nil {
+		return nil, err
+	}
 
+	db, err := open(dialector)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to database: %w", err)
+		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	log.Println("Database connection established successfully")
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure database: %w", err)
+	}
+	config.configurePool(sqlDB)
+	if config.Driver == DriverSQLite {
+		// SQLite serializes writes, and every connection to ":memory:" is a
+		// separate database, so a single connection keeps behavior consistent
+		sqlDB.SetMaxOpenConns(1)
+	}
 	return db, nil
 }
 
-// AutoMigrate runs database migrations
-func AutoMigrate(db *gorm.DB) error {
-	log.Println("Running database migrations...")
+// WaitForConnection pings db until it answers, retrying with exponential
+// backoff and jitter. It gives up once ConnectTimeout has passed or ctx is
+// cancelled, for example by SIGTERM.
+func WaitForConnection(ctx context.Context, db *gorm.DB, config *Config) error {
+	return waitForPing(ctx, func(ctx context.Context) error { return ping(ctx, db) }, config)
+}
+
+// waitForPing calls ping until it succeeds, see WaitForConnection
+func waitForPing(ctx context.Context, ping func(context.Context) error, config *Config) error {
+	deadline, cancel := context.WithTimeout(ctx, config.ConnectTimeout)
+	defer cancel()
+
+	for attempt := 1; ; attempt++ {
+		pingCtx, cancelPing := context.WithTimeout(deadline, pingTimeout)
+		err := ping(pingCtx)
+		cancelPing()
+		if err == nil {
+			slog.Info("Database connection established", "driver", config.Driver, "attempts", attempt)
+			return nil
+		}
+
+		delay := backoff.Exponential(attempt, config.ConnectMinBackoff, config.ConnectMaxBackoff)
+		slog.Warn("Database unavailable, retrying", "attempt", attempt, "retry_in", delay, "error", err)
+		select {
+		case <-deadline.Done():
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("database did not answer within %s: %w", config.ConnectTimeout, err)
+		case <-time.After(delay):
+		}
+	}
+}
+
+// ConnectReplicas opens a connection pool for every replica DSN. Replicas
+// that are down are still returned, they are taken into rotation once
+// they answer a ping.
+func ConnectReplicas(config *Config) ([]*gorm.DB, error) {
+	if len(config.ReplicaDSNs) > 0 && config.Driver != DriverPostgres {
+		return nil, fmt.Errorf("read replicas are only supported with the %q driver", DriverPostgres)
+	}
+
+	replicas := make([]*gorm.DB, 0, len(config.ReplicaDSNs))
+	for i, dsn := range config.ReplicaDSNs {
+		db, err := open(postgres.Open(dsn))
+		if err != nil {
+			closeAll(replicas)
+			return nil, fmt.Errorf("failed to open replica %d: %w", i, err)
+		}
+		sqlDB, err := db.DB()
+		if err != nil {
+			closeAll(replicas)
+			return nil, fmt.Errorf("failed to configure replica %d: %w", i, err)
+		}
+		config.configurePool(sqlDB)
+		replicas = append(replicas, db)
+	}
 
-	err := db.AutoMigrate(
-		&entity.User{},
-	)
+	if len(replicas) > 0 {
+		slog.Info("Read replicas configured", "count", len(replicas))
+	}
+	return replicas, nil
+}
 
+// open opens a GORM connection with the plugins every connection uses,
+// without waiting for the database to answer
+func open(dialector gorm.Dialector) (*gorm.DB, error) {
+	db, err := gorm.Open(dialector, &gorm.Config{
+		DisableAutomaticPing: true,
+		// Queries are logged at debug level through the default slog logger
+		Logger: newGormLogger(slog.Default()),
+		// Map driver specific errors such as unique violations to gorm.ErrDuplicatedKey
+		TranslateError: true,
+	})
 	if err != nil {
-		return fmt.Errorf("failed to run migrations: %w", err)
+		return nil, err
+	}
+
+	// Wrap every SQL statement in a span
+	if err := db.Use(newTracingPlugin()); err != nil {
+		return nil, fmt.Errorf("failed to register tracing plugin: %w", err)
+	}
+	return db, nil
+}
+
+// configurePool applies the configured pool limits
+func (c *Config) configurePool(sqlDB *sql.DB) {
+	if c.MaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(c.MaxOpenConns)
 	}
+	if c.MaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(c.MaxIdleConns)
+	}
+	if c.ConnMaxLifetime > 0 {
+		sqlDB.SetConnMaxLifetime(c.ConnMaxLifetime)
+	}
+	if c.ConnMaxIdleTime > 0 {
+		sqlDB.SetConnMaxIdleTime(c.ConnMaxIdleTime)
+	}
+}
 
-	log.Println("Database migrations completed successfully")
-	return nil
+// closeAll closes the connection pools of dbs
+func closeAll(dbs []*gorm.DB) error {
+	var errs []error
+	for _, db := range dbs {
+		sqlDB, err := db.DB()
+		if err == nil {
+			err = sqlDB.Close()
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
 }
 
-// getEnv gets environment variable with fallback
-func getEnv(key, fallback string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// dialector returns the GORM dialector for the configured driver
+func (c *Config) dialector() (gorm.Dialector, error) {
+	switch c.Driver {
+	case DriverPostgres:
+		dsn := c.DSN
+		if dsn == "" {
+			dsn = fmt.Sprintf(
+				"host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=%s",
+				c.Host, c.User, c.Password, c.DBName, c.Port, c.SSLMode, c.TimeZone,
+			)
+		}
+		return postgres.Open(dsn), nil
+	case DriverSQLite:
+		dsn := c.DSN
+		if dsn == "" {
+			dsn = c.DBName + ".db"
+		}
+		return sqlite.Open(dsn), nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", c.Driver)
 	}
-	return fallback
 }
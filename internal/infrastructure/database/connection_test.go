@@ -0,0 +1,89 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakePinger fails its first failures pings and records when each ping ran
+type fakePinger struct {
+	mu       sync.Mutex
+	failures int
+	calls    []time.Time
+}
+
+func (p *fakePinger) ping(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls = append(p.calls, time.Now())
+	if len(p.calls) <= p.failures {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+// testConnectConfig retries from minBackoff up to maxBackoff for at most timeout
+func testConnectConfig(timeout, minBackoff, maxBackoff time.Duration) *Config {
+	return &Config{
+		Driver:            DriverSQLite,
+		ConnectTimeout:    timeout,
+		ConnectMinBackoff: minBackoff,
+		ConnectMaxBackoff: maxBackoff,
+	}
+}
+
+func TestWaitForPingRetriesUntilAnswered(t *testing.T) {
+	pinger := &fakePinger{failures: 4}
+	config := testConnectConfig(5*time.Second, 10*time.Millisecond, 40*time.Millisecond)
+
+	if err := waitForPing(context.Background(), pinger.ping, config); err != nil {
+		t.Fatalf("waitForPing() error = %v", err)
+	}
+	if len(pinger.calls) != 5 {
+		t.Fatalf("pings = %d, want 5", len(pinger.calls))
+	}
+
+	// Delays double from the minimum up to the maximum, and jitter only
+	// ever lengthens them
+	for i, want := range []time.Duration{10, 20, 40, 40} {
+		want *= time.Millisecond
+		if gap := pinger.calls[i+1].Sub(pinger.calls[i]); gap < want {
+			t.Errorf("delay before attempt %d = %s, want at least %s", i+2, gap, want)
+		}
+	}
+}
+
+func TestWaitForPingGivesUpAtDeadline(t *testing.T) {
+	pinger := &fakePinger{failures: 1 << 30}
+	config := testConnectConfig(100*time.Millisecond, 10*time.Millisecond, 20*time.Millisecond)
+
+	start := time.Now()
+	err := waitForPing(context.Background(), pinger.ping, config)
+	elapsed := time.Since(start)
+
+	if err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Fatalf("waitForPing() error = %v, want the last ping error", err)
+	}
+	if elapsed < config.ConnectTimeout || elapsed > config.ConnectTimeout+time.Second {
+		t.Errorf("waitForPing() returned after %s, want about %s", elapsed, config.ConnectTimeout)
+	}
+	if len(pinger.calls) < 3 {
+		t.Errorf("pings = %d, want several before the deadline", len(pinger.calls))
+	}
+}
+
+func TestWaitForPingStopsWhenCancelled(t *testing.T) {
+	pinger := &fakePinger{failures: 1 << 30}
+	config := testConnectConfig(time.Minute, time.Second, time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	if err := waitForPing(ctx, pinger.ping, config); !errors.Is(err, context.Canceled) {
+		t.Errorf("waitForPing() error = %v, want %v", err, context.Canceled)
+	}
+}
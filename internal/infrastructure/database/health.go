@@ -0,0 +1,64 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"go-clean-architecture/internal/infrastructure/health"
+
+	"gorm.io/gorm"
+)
+
+// PingChecker verifies the database accepts connections
+func PingChecker(db *gorm.DB) health.Checker {
+	return health.CheckerFunc(func(ctx context.Context) error {
+		return ping(ctx, db)
+	})
+}
+
+// ping checks that db accepts connections
+func ping(ctx context.Context, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// PoolChecker fails when the share of open connections in use reaches
+// threshold (0..1), meaning new queries are about to wait for a connection.
+// Unbounded and single connection pools, such as SQLite's, are not checked
+// since one query in flight would already count as saturated.
+func PoolChecker(db *gorm.DB, threshold float64) health.Checker {
+	return health.CheckerFunc(func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+
+		stats := sqlDB.Stats()
+		if stats.MaxOpenConnections <= 1 {
+			return nil
+		}
+		usage := float64(stats.InUse) / float64(stats.MaxOpenConnections)
+		if usage >= threshold {
+			return fmt.Errorf("connection pool saturated: %d of %d connections in use, %d waits",
+				stats.InUse, stats.MaxOpenConnections, stats.WaitCount)
+		}
+		return nil
+	})
+}
+
+// MigrationChecker fails unless the applied schema version matches the
+// newest migration embedded in the binary
+func MigrationChecker(migrator *Migrator) health.Checker {
+	return health.CheckerFunc(func(ctx context.Context) error {
+		current, err := migrator.Version(ctx)
+		if err != nil {
+			return err
+		}
+		if expected := migrator.LatestVersion(); current != expected {
+			return fmt.Errorf("schema version is %d, expected %d", current, expected)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,85 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// slowQueryThreshold is the duration above which queries are logged as warnings
+const slowQueryThreshold = 200 * time.Millisecond
+
+// gormLogger sends GORM logs to slog, so queries carry the request ID of
+// the context they ran with. Queries are logged at debug level.
+type gormLogger struct {
+	log   *slog.Logger
+	level logger.LogLevel
+}
+
+// newGormLogger creates a GORM logger backed by log
+func newGormLogger(log *slog.Logger) logger.Interface {
+	return &gormLogger{log: log, level: logger.Info}
+}
+
+// LogMode returns a copy of the logger with the given GORM level
+func (l *gormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	clone := *l
+	clone.level = level
+	return &clone
+}
+
+// Info logs GORM informational messages
+func (l *gormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Info {
+		l.log.InfoContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+// Warn logs GORM warnings
+func (l *gormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Warn {
+		l.log.WarnContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+// Error logs GORM errors
+func (l *gormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Error {
+		l.log.ErrorContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+// Trace logs an executed query with its duration and affected rows
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	level := slog.LevelDebug
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= logger.Error:
+		level = slog.LevelError
+	case elapsed > slowQueryThreshold && l.level >= logger.Warn:
+		level = slog.LevelWarn
+	}
+	if !l.log.Enabled(ctx, level) {
+		return
+	}
+
+	sql, rows := fc()
+	attrs := []slog.Attr{
+		slog.String("sql", sql),
+		slog.Int64("rows", rows),
+		slog.Duration("elapsed", elapsed),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	l.log.LogAttrs(ctx, level, "database query", attrs...)
+}
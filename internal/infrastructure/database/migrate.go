@@ -0,0 +1,338 @@
+package database
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed migrations
+var migrationFiles embed.FS
+
+// migrationLockID is the Postgres advisory lock key guarding schema changes
+const migrationLockID int64 = 0x6d6967726174 // "migrat"
+
+// createSchemaMigrations creates the bookkeeping table in any supported dialect
+const createSchemaMigrations = `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version    BIGINT PRIMARY KEY,
+    name       VARCHAR(255) NOT NULL,
+    applied_at TIMESTAMP NOT NULL
+)`
+
+// ErrUnknownMigration is returned when a target version has no migration file
+var ErrUnknownMigration = errors.New("unknown migration version")
+
+// Migration is a single versioned schema change
+type Migration struct {
+	Version uint64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus reports whether a migration has been applied
+type MigrationStatus struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// schemaMigration is a row of the schema_migrations table
+type schemaMigration struct {
+	Version   uint64 `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+// TableName overrides the table name used by GORM
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Migrator applies the embedded SQL migrations for the connected dialect
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// NewMigrator creates a migrator with the migrations for the database dialect
+func NewMigrator(db *gorm.DB) (*Migrator, error) {
+	dir, err := fs.Sub(migrationFiles, path.Join("migrations", db.Dialector.Name()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open migrations: %w", err)
+	}
+
+	migrations, err := loadMigrations(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+	}, nil
+}
+
+// LatestVersion returns the version of the newest known migration
+func (m *Migrator) LatestVersion() uint64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Version returns the newest applied migration version without taking the lock
+func (m *Migrator) Version(ctx context.Context) (uint64, error) {
+	db := m.db.WithContext(ctx)
+	if !db.Migrator().HasTable(&schemaMigration{}) {
+		return 0, nil
+	}
+	return currentVersion(db)
+}
+
+// Up applies all pending migrations
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.To(ctx, m.LatestVersion())
+}
+
+// Down rolls back the most recently applied migration
+func (m *Migrator) Down(ctx context.Context) error {
+	return m.withLock(ctx, func(conn *gorm.DB) error {
+		applied, err := appliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			slog.InfoContext(ctx, "No migrations to roll back")
+			return nil
+		}
+
+		latest := applied[len(applied)-1].Version
+		migration, ok := m.find(latest)
+		if !ok {
+			return fmt.Errorf("%w: %d is applied but has no migration file", ErrUnknownMigration, latest)
+		}
+		return m.rollback(conn, migration)
+	})
+}
+
+// To migrates up or down until exactly the given version is applied.
+// Version 0 rolls back every migration.
+func (m *Migrator) To(ctx context.Context, target uint64) error {
+	if _, ok := m.find(target); target != 0 && !ok {
+		return fmt.Errorf("%w: %d", ErrUnknownMigration, target)
+	}
+
+	return m.withLock(ctx, func(conn *gorm.DB) error {
+		applied, err := appliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+
+		isApplied := make(map[uint64]bool, len(applied))
+		for _, row := range applied {
+			isApplied[row.Version] = true
+		}
+
+		// Roll back newest first everything above the target
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+			if migration.Version > target && isApplied[migration.Version] {
+				if err := m.rollback(conn, migration); err != nil {
+					return err
+				}
+			}
+		}
+
+		// Apply oldest first everything up to the target
+		for _, migration := range m.migrations {
+			if migration.Version <= target && !isApplied[migration.Version] {
+				if err := m.apply(conn, migration); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// Status lists every known migration and whether it has been applied
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var statuses []MigrationStatus
+	err := m.withLock(ctx, func(conn *gorm.DB) error {
+		applied, err := appliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+
+		appliedAt := make(map[uint64]time.Time, len(applied))
+		for _, row := range applied {
+			appliedAt[row.Version] = row.AppliedAt
+		}
+
+		statuses = make([]MigrationStatus, 0, len(m.migrations))
+		for _, migration := range m.migrations {
+			at, ok := appliedAt[migration.Version]
+			statuses = append(statuses, MigrationStatus{
+				Migration: migration,
+				Applied:   ok,
+				AppliedAt: at,
+			})
+		}
+		return nil
+	})
+	return statuses, err
+}
+
+// apply runs an up migration and records it in a single transaction
+func (m *Migrator) apply(conn *gorm.DB, migration Migration) error {
+	slog.Info("Applying migration", "version", migration.Version, "name", migration.Name)
+
+	return conn.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(migration.Up).Error; err != nil {
+			return fmt.Errorf("migration %d up failed: %w", migration.Version, err)
+		}
+		return tx.Create(&schemaMigration{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			AppliedAt: time.Now().UTC(),
+		}).Error
+	})
+}
+
+// rollback runs a down migration and forgets it in a single transaction
+func (m *Migrator) rollback(conn *gorm.DB, migration Migration) error {
+	if migration.Down == "" {
+		return fmt.Errorf("migration %d has no down script", migration.Version)
+	}
+
+	slog.Info("Rolling back migration", "version", migration.Version, "name", migration.Name)
+
+	return conn.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(migration.Down).Error; err != nil {
+			return fmt.Errorf("migration %d down failed: %w", migration.Version, err)
+		}
+		return tx.Delete(&schemaMigration{}, migration.Version).Error
+	})
+}
+
+// withLock runs fn on a single connection holding the migration lock, so
+// concurrent replicas apply migrations one at a time
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *gorm.DB) error) error {
+	return m.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		if conn.Dialector.Name() == "postgres" {
+			if err := conn.Exec("SELECT pg_advisory_lock(?)", migrationLockID).Error; err != nil {
+				return fmt.Errorf("failed to acquire migration lock: %w", err)
+			}
+			defer func() {
+				// Unlock even if the request context was cancelled
+				if err := conn.WithContext(context.Background()).Exec("SELECT pg_advisory_unlock(?)", migrationLockID).Error; err != nil {
+					slog.Error("Failed to release migration lock", "error", err)
+				}
+			}()
+		}
+
+		if err := conn.Exec(createSchemaMigrations).Error; err != nil {
+			return fmt.Errorf("failed to create schema_migrations table: %w", err)
+		}
+		return fn(conn)
+	})
+}
+
+// find looks up a known migration by version
+func (m *Migrator) find(version uint64) (Migration, bool) {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+	return Migration{}, false
+}
+
+// appliedMigrations returns the recorded migrations, oldest first
+func appliedMigrations(conn *gorm.DB) ([]schemaMigration, error) {
+	var applied []schemaMigration
+	if err := conn.Order("version ASC").Find(&applied).Error; err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	return applied, nil
+}
+
+// currentVersion returns the newest recorded migration version
+func currentVersion(conn *gorm.DB) (uint64, error) {
+	var version uint64
+	err := conn.Model(&schemaMigration{}).Select("COALESCE(MAX(version), 0)").Scan(&version).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	return version, nil
+}
+
+// loadMigrations parses files named <version>_<name>.<up|down>.sql
+func loadMigrations(dir fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(dir, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[uint64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+
+		base := strings.TrimSuffix(entry.Name(), ".sql")
+		direction := path.Ext(base)
+		base = strings.TrimSuffix(base, direction)
+		versionStr, name, ok := strings.Cut(base, "_")
+		if !ok || (direction != ".up" && direction != ".down") {
+			return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+
+		version, err := strconv.ParseUint(versionStr, 10, 64)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("invalid migration version in %q", entry.Name())
+		}
+
+		content, err := fs.ReadFile(dir, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %q: %w", entry.Name(), err)
+		}
+
+		migration, exists := byVersion[version]
+		if !exists {
+			migration = &Migration{Version: version, Name: name}
+			byVersion[version] = migration
+		} else if migration.Name != name {
+			return nil, fmt.Errorf("migration version %d used by %q and %q", version, migration.Name, name)
+		}
+
+		if direction == ".up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %d has no up script", migration.Version)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
@@ -0,0 +1,184 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"gorm.io/gorm"
+)
+
+// newTestMigrator returns a migrator over an empty in-memory SQLite database
+func newTestMigrator(t *testing.T) (*Migrator, *gorm.DB) {
+	t.Helper()
+	db, err := Open(&Config{Driver: DriverSQLite, DSN: ":memory:"})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		t.Fatalf("NewMigrator() error = %v", err)
+	}
+	return migrator, db
+}
+
+// assertSchema checks the version and which tables and columns exist
+func assertSchema(t *testing.T, m *Migrator, db *gorm.DB, version uint64) {
+	t.Helper()
+	got, err := m.Version(context.Background())
+	if err != nil {
+		t.Fatalf("Version() error = %v", err)
+	}
+	if got != version {
+		t.Errorf("Version() = %d, want %d", got, version)
+	}
+
+	// The version each table or column is added in
+	tables := map[string]uint64{"users": 1, "outbox": 5, "webhooks": 6, "webhook_deliveries": 6}
+	for table, since := range tables {
+		if got := db.Migrator().HasTable(table); got != (version >= since) {
+			t.Errorf("at %d HasTable(%s) = %v", version, table, got)
+		}
+	}
+	if version == 0 {
+		return
+	}
+	columns := map[string]uint64{"version": 2, "password_hash": 3, "role": 4}
+	for column, since := range columns {
+		if got := db.Migrator().HasColumn("users", column); got != (version >= since) {
+			t.Errorf("at %d HasColumn(users, %s) = %v", version, column, got)
+		}
+	}
+}
+
+func TestMigrator(t *testing.T) {
+	ctx := context.Background()
+	m, db := newTestMigrator(t)
+	latest := m.LatestVersion()
+	if latest != 6 {
+		t.Fatalf("LatestVersion() = %d, want 6", latest)
+	}
+	assertSchema(t, m, db, 0)
+
+	steps := []struct {
+		name    string
+		migrate func() error
+		want    uint64
+	}{
+		{name: "up", migrate: func() error { return m.Up(ctx) }, want: latest},
+		{name: "up again", migrate: func() error { return m.Up(ctx) }, want: latest},
+		{name: "down", migrate: func() error { return m.Down(ctx) }, want: latest - 1},
+		{name: "to 3", migrate: func() error { return m.To(ctx, 3) }, want: 3},
+		{name: "to 5", migrate: func() error { return m.To(ctx, 5) }, want: 5},
+		{name: "to 0", migrate: func() error { return m.To(ctx, 0) }, want: 0},
+		{name: "down with nothing applied", migrate: func() error { return m.Down(ctx) }, want: 0},
+		{name: "up from scratch", migrate: func() error { return m.Up(ctx) }, want: latest},
+	}
+	for _, step := range steps {
+		if err := step.migrate(); err != nil {
+			t.Fatalf("%s error = %v", step.name, err)
+		}
+		assertSchema(t, m, db, step.want)
+	}
+
+	if err := m.To(ctx, 42); !errors.Is(err, ErrUnknownMigration) {
+		t.Errorf("To(42) error = %v, want %v", err, ErrUnknownMigration)
+	}
+}
+
+func TestMigratorStatus(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newTestMigrator(t)
+	if err := m.To(ctx, 2); err != nil {
+		t.Fatalf("To(2) error = %v", err)
+	}
+
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if len(statuses) != int(m.LatestVersion()) {
+		t.Fatalf("Status() returned %d migrations, want %d", len(statuses), m.LatestVersion())
+	}
+	for i, status := range statuses {
+		if status.Version != uint64(i+1) {
+			t.Errorf("status %d version = %d, want %d", i, status.Version, i+1)
+		}
+		if wantApplied := status.Version <= 2; status.Applied != wantApplied {
+			t.Errorf("migration %d applied = %v, want %v", status.Version, status.Applied, wantApplied)
+		}
+		if status.Applied == status.AppliedAt.IsZero() {
+			t.Errorf("migration %d applied = %v with applied at %v", status.Version, status.Applied, status.AppliedAt)
+		}
+	}
+	if statuses[0].Name != "create_users" {
+		t.Errorf("first migration name = %q, want %q", statuses[0].Name, "create_users")
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	file := func(content string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(content)} }
+
+	tests := []struct {
+		name    string
+		files   fstest.MapFS
+		want    []uint64
+		wantErr bool
+	}{
+		{
+			name: "sorted by version",
+			files: fstest.MapFS{
+				"0010_later.up.sql":    file("SELECT 10"),
+				"0002_second.up.sql":   file("SELECT 2"),
+				"0002_second.down.sql": file("SELECT -2"),
+				"README.md":            file("ignored"),
+			},
+			want: []uint64{2, 10},
+		},
+		{name: "missing up", files: fstest.MapFS{"0001_users.down.sql": file("DROP")}, wantErr: true},
+		{name: "no name", files: fstest.MapFS{"0001.up.sql": file("SELECT 1")}, wantErr: true},
+		{name: "no direction", files: fstest.MapFS{"0001_users.sql": file("SELECT 1")}, wantErr: true},
+		{name: "version zero", files: fstest.MapFS{"0000_users.up.sql": file("SELECT 1")}, wantErr: true},
+		{
+			name: "version used twice",
+			files: fstest.MapFS{
+				"0001_users.up.sql":  file("SELECT 1"),
+				"0001_orders.up.sql": file("SELECT 1"),
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := loadMigrations(tt.files)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("loadMigrations() = %+v, want an error", migrations)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadMigrations() error = %v", err)
+			}
+			var got []uint64
+			for _, migration := range migrations {
+				got = append(got, migration.Version)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("versions = %v, want %v", got, tt.want)
+			}
+			if migrations[0].Down != "SELECT -2" {
+				t.Errorf("down script = %q, want %q", migrations[0].Down, "SELECT -2")
+			}
+		})
+	}
+}
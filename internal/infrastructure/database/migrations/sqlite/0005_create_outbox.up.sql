@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS outbox (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    event_type      VARCHAR(100) NOT NULL,
+    aggregate_id    INTEGER NOT NULL,
+    payload         TEXT NOT NULL,
+    occurred_at     DATETIME NOT NULL,
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME NOT NULL,
+    last_error      TEXT,
+    published_at    DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_pending ON outbox (aggregate_id, id) WHERE published_at IS NULL;
@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS webhooks (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    url        VARCHAR(2048) NOT NULL,
+    events     TEXT NOT NULL,
+    secret     VARCHAR(255) NOT NULL,
+    active     BOOLEAN NOT NULL DEFAULT true,
+    created_at DATETIME,
+    updated_at DATETIME
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    webhook_id      INTEGER NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+    event_id        INTEGER NOT NULL,
+    event_type      VARCHAR(100) NOT NULL,
+    payload         TEXT NOT NULL,
+    status          VARCHAR(20) NOT NULL,
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME NOT NULL,
+    last_error      TEXT,
+    response_status INTEGER,
+    delivered_at    DATETIME,
+    created_at      DATETIME,
+    updated_at      DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id ON webhook_deliveries (webhook_id, id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
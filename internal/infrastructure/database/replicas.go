@@ -0,0 +1,122 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"go-clean-architecture/internal/infrastructure/health"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// replica is a read replica with its last known health
+type replica struct {
+	db      *gorm.DB
+	healthy atomic.Bool
+}
+
+// ReplicaSet spreads reads over the healthy replicas in round robin.
+// Replicas are pinged every check interval; a replica failing a ping or
+// reported by MarkFailed is skipped until it answers a ping again.
+type ReplicaSet struct {
+	replicas []*replica
+	next     atomic.Uint64
+	interval time.Duration
+}
+
+// NewReplicaSet creates a new replica set instance. Replicas start out
+// unhealthy until their first ping succeeds.
+func NewReplicaSet(dbs []*gorm.DB, interval time.Duration) *ReplicaSet {
+	set := &ReplicaSet{interval: interval}
+	for _, db := range dbs {
+		set.replicas = append(set.replicas, &replica{db: db})
+	}
+	return set
+}
+
+// Pick returns a healthy replica, or nil when there is none
+func (s *ReplicaSet) Pick() *gorm.DB {
+	n := uint64(len(s.replicas))
+	start := s.next.Add(1)
+	for i := uint64(0); i < n; i++ {
+		if r := s.replicas[(start+i)%n]; r.healthy.Load() {
+			return r.db
+		}
+	}
+	return nil
+}
+
+// MarkFailed takes a replica out of rotation until its next successful ping
+func (s *ReplicaSet) MarkFailed(db *gorm.DB) {
+	for i, r := range s.replicas {
+		if r.db == db && r.healthy.Swap(false) {
+			slog.Warn("Read replica marked unhealthy", "replica", i)
+		}
+	}
+}
+
+// Run pings every replica until ctx is cancelled
+func (s *ReplicaSet) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.CheckOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckOnce pings every replica and updates its health
+func (s *ReplicaSet) CheckOnce(ctx context.Context) {
+	for i, r := range s.replicas {
+		pingCtx, cancel := context.WithTimeout(ctx, health.DefaultTimeout)
+		err := ping(pingCtx, r.db)
+		cancel()
+
+		healthy := err == nil
+		if r.healthy.Swap(healthy) != healthy {
+			if healthy {
+				slog.Info("Read replica healthy", "replica", i)
+			} else {
+				slog.Warn("Read replica unhealthy", "replica", i, "error", err)
+			}
+		}
+	}
+}
+
+// Checker reports the replica set as degraded while any replica is
+// unhealthy. Reads fall back to the primary, so it should not be critical.
+func (s *ReplicaSet) Checker() health.Checker {
+	return health.CheckerFunc(func(ctx context.Context) error {
+		down := 0
+		for _, r := range s.replicas {
+			if !r.healthy.Load() {
+				down++
+			}
+		}
+		if down > 0 {
+			return fmt.Errorf("%d of %d read replicas unhealthy", down, len(s.replicas))
+		}
+		return nil
+	})
+}
+
+// DBs returns the connection of every replica
+func (s *ReplicaSet) DBs() []*gorm.DB {
+	dbs := make([]*gorm.DB, 0, len(s.replicas))
+	for _, r := range s.replicas {
+		dbs = append(dbs, r.db)
+	}
+	return dbs
+}
+
+// Close closes the connection pool of every replica
+func (s *ReplicaSet) Close() error {
+	return closeAll(s.DBs())
+}
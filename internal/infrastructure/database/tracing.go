@@ -0,0 +1,103 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// parentContextKey stores the statement context from before the span started
+const parentContextKey = "otel:parent_context"
+
+// tracerName identifies the spans created around SQL statements
+const tracerName = "go-clean-architecture/internal/infrastructure/database"
+
+// tracingPlugin is a GORM plugin that wraps every SQL statement in a span,
+// a child of the span carried by the statement context
+type tracingPlugin struct {
+	tracer trace.Tracer
+}
+
+// newTracingPlugin creates a tracing plugin using the global tracer provider
+func newTracingPlugin() gorm.Plugin {
+	return &tracingPlugin{tracer: otel.Tracer(tracerName)}
+}
+
+// Name returns the plugin name
+func (p *tracingPlugin) Name() string {
+	return "otel-tracing"
+}
+
+// Initialize registers the span callbacks around each GORM operation
+func (p *tracingPlugin) Initialize(db *gorm.DB) error {
+	register := []struct {
+		operation string
+		before    func(name string, fn func(*gorm.DB)) error
+		after     func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", db.Callback().Create().Before("gorm:create").Register, db.Callback().Create().After("gorm:create").Register},
+		{"query", db.Callback().Query().Before("gorm:query").Register, db.Callback().Query().After("gorm:query").Register},
+		{"update", db.Callback().Update().Before("gorm:update").Register, db.Callback().Update().After("gorm:update").Register},
+		{"delete", db.Callback().Delete().Before("gorm:delete").Register, db.Callback().Delete().After("gorm:delete").Register},
+		{"row", db.Callback().Row().Before("gorm:row").Register, db.Callback().Row().After("gorm:row").Register},
+		{"raw", db.Callback().Raw().Before("gorm:raw").Register, db.Callback().Raw().After("gorm:raw").Register},
+	}
+
+	for _, r := range register {
+		if err := r.before("otel:before_"+r.operation, p.start(r.operation)); err != nil {
+			return err
+		}
+		if err := r.after("otel:after_"+r.operation, p.end); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// start opens a span for the statement and stores it in the statement context
+func (p *tracingPlugin) start(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		if db.Statement.Context == nil {
+			return
+		}
+		db.InstanceSet(parentContextKey, db.Statement.Context)
+		ctx, _ := p.tracer.Start(db.Statement.Context, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(attribute.String("db.system", db.Dialector.Name())),
+		)
+		db.Statement.Context = ctx
+	}
+}
+
+// end records the executed SQL and its outcome, then closes the span
+func (p *tracingPlugin) end(db *gorm.DB) {
+	if db.Statement.Context == nil {
+		return
+	}
+	span := trace.SpanFromContext(db.Statement.Context)
+
+	// Restore the parent so later statements on the same chain are not nested in this span
+	if parent, ok := db.InstanceGet(parentContextKey); ok {
+		db.Statement.Context = parent.(context.Context)
+	}
+
+	if !span.IsRecording() {
+		return
+	}
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("db.statement", db.Statement.SQL.String()),
+		attribute.String("db.sql.table", db.Statement.Table),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}
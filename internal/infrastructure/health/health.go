@@ -0,0 +1,171 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout bounds a check that does not set its own timeout
+const DefaultTimeout = 2 * time.Second
+
+// Overall and per-check statuses reported by a readiness probe
+const (
+	StatusOK       = "ok"
+	StatusDegraded = "degraded"
+	StatusDown     = "down"
+)
+
+// Checker verifies that a single dependency is usable
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc adapts a function to the Checker interface
+type CheckerFunc func(ctx context.Context) error
+
+// Check calls f(ctx)
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Check is a named checker registered with the readiness probe.
+// A failing critical check makes the service not ready; a failing
+// non-critical check only marks it as degraded.
+type Check struct {
+	Name     string
+	Checker  Checker
+	Critical bool
+	Timeout  time.Duration
+}
+
+// CheckResult is the outcome of a single check
+type CheckResult struct {
+	Status   string `json:"status"`
+	Critical bool   `json:"critical"`
+	Duration string `json:"duration"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Report is the outcome of a readiness probe
+type Report struct {
+	Status    string                 `json:"status"`
+	Version   string                 `json:"version"`
+	Commit    string                 `json:"commit"`
+	Timestamp string                 `json:"timestamp"`
+	Checks    map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// Ready reports whether every critical check passed
+func (r *Report) Ready() bool {
+	return r.Status != StatusDown
+}
+
+// Health runs the registered checks for the readiness probe
+type Health struct {
+	version string
+	commit  string
+	mu      sync.RWMutex
+	checks  []Check
+}
+
+// NewHealth creates a new health instance for the given build
+func NewHealth(version, commit string, checks ...Check) *Health {
+	return &Health{
+		version: version,
+		commit:  commit,
+		checks:  checks,
+	}
+}
+
+// Register adds checks to the readiness probe
+func (h *Health) Register(checks ...Check) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks = append(h.checks, checks...)
+}
+
+// Live reports the process status without touching any dependency
+func (h *Health) Live() *Report {
+	return h.report(StatusOK, nil)
+}
+
+// Ready runs every check concurrently, each bounded by its own timeout
+func (h *Health) Ready(ctx context.Context) *Report {
+	h.mu.RLock()
+	checks := make([]Check, len(h.checks))
+	copy(checks, h.checks)
+	h.mu.RUnlock()
+
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			results[i] = run(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	status := StatusOK
+	byName := make(map[string]CheckResult, len(checks))
+	for i, check := range checks {
+		result := results[i]
+		byName[check.Name] = result
+		if result.Status == StatusOK {
+			continue
+		}
+		if check.Critical {
+			status = StatusDown
+		} else if status == StatusOK {
+			status = StatusDegraded
+		}
+	}
+	return h.report(status, byName)
+}
+
+// report builds a report stamped with the build information
+func (h *Health) report(status string, checks map[string]CheckResult) *Report {
+	return &Report{
+		Status:    status,
+		Version:   h.version,
+		Commit:    h.commit,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Checks:    checks,
+	}
+}
+
+// run executes a single check, treating a timeout as a failure
+func run(ctx context.Context, check Check) CheckResult {
+	timeout := check.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- check.Checker.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := CheckResult{
+		Status:   StatusOK,
+		Critical: check.Critical,
+		Duration: time.Since(start).Round(time.Microsecond).String(),
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
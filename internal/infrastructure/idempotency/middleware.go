@@ -0,0 +1,218 @@
+package idempotency
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"go-clean-architecture/internal/usecase"
+	"go-clean-architecture/pkg/requestid"
+	"go-clean-architecture/pkg/response"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// Header is the request header carrying the client chosen key
+const Header = "Idempotency-Key"
+
+// ReplayedHeader marks responses replayed from a stored record
+const ReplayedHeader = "Idempotent-Replayed"
+
+// maxKeyLength bounds keys accepted from clients
+const maxKeyLength = 255
+
+// Supported store drivers
+const (
+	DriverMemory = "memory"
+	DriverRedis  = "redis"
+)
+
+// Config holds idempotency configuration
+type Config struct {
+	// Driver is either "memory" or "redis"
+	Driver string
+	// TTL is how long a completed response is replayed
+	TTL time.Duration
+	// LockTTL bounds how long an unfinished request holds its key, so a
+	// crashed request does not block retries forever
+	LockTTL time.Duration
+	// RedisURL is a redis:// URL used by the redis driver
+	RedisURL string
+}
+
+// NewStore creates the store for the configured driver
+func NewStore(config *Config) (Store, error) {
+	switch config.Driver {
+	case DriverMemory, "":
+		return NewMemoryStore(), nil
+	case DriverRedis:
+		options, err := redis.ParseURL(config.RedisURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid REDIS_URL: %w", err)
+		}
+		return NewRedisStore(redis.NewClient(options)), nil
+	default:
+		return nil, fmt.Errorf("unsupported IDEMPOTENCY_STORE %q", config.Driver)
+	}
+}
+
+// Idempotency replays the stored response of requests retried with the
+// same Idempotency-Key
+type Idempotency struct {
+	store  Store
+	config *Config
+}
+
+// NewIdempotency creates a new idempotency instance
+func NewIdempotency(store Store, config *Config) *Idempotency {
+	return &Idempotency{store: store, config: config}
+}
+
+// Middleware handles requests carrying an Idempotency-Key header:
+//   - the first request runs and its response is stored, unless it failed
+//     with a 5xx so the client can retry
+//   - a retry with the same payload gets the stored response replayed
+//   - a retry with a different payload gets 422
+//   - a retry while the first request is still running gets 409
+//
+// Requests without the header are not affected. Keys are scoped to the
+// route and the authenticated user.
+func (i *Idempotency) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(Header)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxKeyLength {
+			response.Error(c, http.StatusBadRequest, "invalid_idempotency_key", "Idempotency-Key must be at most 255 characters", nil)
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "bad_request", "Invalid request body", err)
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		key = scopedKey(c, key)
+		fingerprint := fingerprint(c, body)
+
+		record, reserved, err := i.store.Reserve(ctx, key, fingerprint, i.config.LockTTL)
+		if err != nil {
+			// Fail open, a missing replay is better than refusing every request
+			slog.ErrorContext(ctx, "Idempotency store unavailable", "error", err)
+			c.Next()
+			return
+		}
+
+		if !reserved {
+			switch {
+			case record.Fingerprint != fingerprint:
+				response.Error(c, http.StatusUnprocessableEntity, "idempotency_key_reused", "Idempotency-Key was already used with a different request", nil)
+			case !record.Completed:
+				response.Error(c, http.StatusConflict, "idempotency_key_in_use", "A request with this Idempotency-Key is still being processed", nil)
+			default:
+				replay(c, record)
+			}
+			c.Abort()
+			return
+		}
+
+		// A panicking handler must not hold the key until LockTTL expires
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				_ = i.store.Release(ctx, key)
+				panic(recovered)
+			}
+		}()
+
+		// Errors must be rendered by a handler after this middleware, such as
+		// controller.ErrorHandler, for their response to be stored
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		status := c.Writer.Status()
+		if status >= http.StatusInternalServerError {
+			if err := i.store.Release(ctx, key); err != nil {
+				slog.ErrorContext(ctx, "Failed to release idempotency key", "error", err)
+			}
+			return
+		}
+
+		completed := &Record{
+			Fingerprint: fingerprint,
+			Completed:   true,
+			Status:      status,
+			Header:      c.Writer.Header().Clone(),
+			Body:        recorder.body.Bytes(),
+		}
+		if err := i.store.Complete(ctx, key, completed, i.config.TTL); err != nil {
+			slog.ErrorContext(ctx, "Failed to store idempotent response", "error", err)
+		}
+	}
+}
+
+// responseRecorder copies the response body while it is written
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+// Write records and writes the body
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+// WriteString records and writes the body
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
+
+// replay writes a stored response. Headers set for this request, such as
+// its request ID, win over stored ones, and CORS headers always come from
+// this request since the retry may come from another origin.
+func replay(c *gin.Context, record *Record) {
+	header := c.Writer.Header()
+	for name, values := range record.Header {
+		if name == requestid.Header || strings.HasPrefix(name, "Access-Control-") || header.Get(name) != "" {
+			continue
+		}
+		header[name] = values
+	}
+	header.Set(ReplayedHeader, "true")
+	c.Status(record.Status)
+	_, _ = c.Writer.Write(record.Body)
+}
+
+// scopedKey namespaces a client key by route and user so clients cannot
+// replay each other's responses
+func scopedKey(c *gin.Context, key string) string {
+	user := "anonymous"
+	if actor, ok := usecase.UserFromContext(c.Request.Context()); ok {
+		user = strconv.FormatUint(uint64(actor.ID), 10)
+	}
+	return c.Request.Method + " " + c.FullPath() + ":" + user + ":" + key
+}
+
+// fingerprint hashes what makes two requests the same
+func fingerprint(c *gin.Context, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(c.Request.Method + " " + c.Request.URL.Path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
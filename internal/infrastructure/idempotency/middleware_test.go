@@ -0,0 +1,185 @@
+package idempotency
+
+import (
+	"go-clean-architecture/internal/testutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handler stands in for the user registration handler. It answers with
+// the next of its statuses, 201 once they run out, and can be held until
+// release is closed.
+type handler struct {
+	mu       sync.Mutex
+	calls    int
+	statuses []int
+	started  chan struct{}
+	release  chan struct{}
+}
+
+func (h *handler) serve(c *gin.Context) {
+	h.mu.Lock()
+	h.calls++
+	call := h.calls
+	status := http.StatusCreated
+	if call <= len(h.statuses) {
+		status = h.statuses[call-1]
+	}
+	h.mu.Unlock()
+
+	if h.release != nil {
+		close(h.started)
+		<-h.release
+	}
+	c.Header("Location", "/users/"+strconv.Itoa(call))
+	c.JSON(status, gin.H{"call": call})
+}
+
+// callCount returns how often the handler ran
+func (h *handler) callCount() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.calls
+}
+
+// newRouter serves POST /users through the middleware over store
+func newRouter(store Store, h *handler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	i := NewIdempotency(store, &Config{TTL: time.Hour, LockTTL: time.Minute})
+	router.POST("/users", i.Middleware(), h.serve)
+	return router
+}
+
+// post sends a registration with an optional idempotency key
+func post(router *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		r.Header.Set(Header, key)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	return w
+}
+
+func TestMiddlewareReplaysResponse(t *testing.T) {
+	testutil.ForEachBackend(t, storeBackends, func(t *testing.T, backend testutil.Backend[Store]) {
+		h := &handler{}
+		router := newRouter(backend.New(t), h)
+
+		first := post(router, "key-1", `{"name": "Alice"}`)
+		if first.Code != http.StatusCreated {
+			t.Fatalf("first status = %d, want %d", first.Code, http.StatusCreated)
+		}
+
+		retry := post(router, "key-1", `{"name": "Alice"}`)
+		if retry.Code != first.Code || retry.Body.String() != first.Body.String() {
+			t.Errorf("retry = %d %s, want %d %s", retry.Code, retry.Body.String(), first.Code, first.Body.String())
+		}
+		if got := retry.Header().Get("Location"); got != first.Header().Get("Location") {
+			t.Errorf("retry Location = %q, want %q", got, first.Header().Get("Location"))
+		}
+		if got := retry.Header().Get(ReplayedHeader); got != "true" {
+			t.Errorf("retry %s = %q, want %q", ReplayedHeader, got, "true")
+		}
+		if first.Header().Get(ReplayedHeader) != "" {
+			t.Errorf("first response has %s", ReplayedHeader)
+		}
+
+		// Other keys and requests without a key run the handler
+		post(router, "key-2", `{"name": "Alice"}`)
+		post(router, "", `{"name": "Alice"}`)
+		post(router, "", `{"name": "Alice"}`)
+		if got := h.callCount(); got != 4 {
+			t.Errorf("handler calls = %d, want 4", got)
+		}
+	})
+}
+
+func TestMiddlewareRejectsReusedKey(t *testing.T) {
+	testutil.ForEachBackend(t, storeBackends, func(t *testing.T, backend testutil.Backend[Store]) {
+		h := &handler{}
+		router := newRouter(backend.New(t), h)
+
+		post(router, "key-1", `{"name": "Alice"}`)
+		w := post(router, "key-1", `{"name": "Bob"}`)
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("status = %d, want %d", w.Code, http.StatusUnprocessableEntity)
+		}
+		if !strings.Contains(w.Body.String(), "idempotency_key_reused") {
+			t.Errorf("body = %s, want idempotency_key_reused", w.Body.String())
+		}
+		if got := h.callCount(); got != 1 {
+			t.Errorf("handler calls = %d, want 1", got)
+		}
+	})
+}
+
+func TestMiddlewareRejectsConcurrentRetry(t *testing.T) {
+	testutil.ForEachBackend(t, storeBackends, func(t *testing.T, backend testutil.Backend[Store]) {
+		h := &handler{started: make(chan struct{}), release: make(chan struct{})}
+		router := newRouter(backend.New(t), h)
+
+		done := make(chan *httptest.ResponseRecorder)
+		go func() { done <- post(router, "key-1", `{"name": "Alice"}`) }()
+		<-h.started
+
+		w := post(router, "key-1", `{"name": "Alice"}`)
+		if w.Code != http.StatusConflict {
+			t.Errorf("status while in flight = %d, want %d", w.Code, http.StatusConflict)
+		}
+		if !strings.Contains(w.Body.String(), "idempotency_key_in_use") {
+			t.Errorf("body = %s, want idempotency_key_in_use", w.Body.String())
+		}
+
+		close(h.release)
+		if first := <-done; first.Code != http.StatusCreated {
+			t.Errorf("first status = %d, want %d", first.Code, http.StatusCreated)
+		}
+		if w := post(router, "key-1", `{"name": "Alice"}`); w.Header().Get(ReplayedHeader) != "true" {
+			t.Errorf("retry after completion was not replayed: %d %s", w.Code, w.Body.String())
+		}
+	})
+}
+
+func TestMiddlewareReleasesKeyAfterServerError(t *testing.T) {
+	testutil.ForEachBackend(t, storeBackends, func(t *testing.T, backend testutil.Backend[Store]) {
+		h := &handler{statuses: []int{http.StatusServiceUnavailable}}
+		router := newRouter(backend.New(t), h)
+
+		if w := post(router, "key-1", `{"name": "Alice"}`); w.Code != http.StatusServiceUnavailable {
+			t.Fatalf("first status = %d, want %d", w.Code, http.StatusServiceUnavailable)
+		}
+		w := post(router, "key-1", `{"name": "Alice"}`)
+		if w.Code != http.StatusCreated || w.Header().Get(ReplayedHeader) != "" {
+			t.Errorf("retry = %d replayed %q, want a fresh %d", w.Code, w.Header().Get(ReplayedHeader), http.StatusCreated)
+		}
+		if got := h.callCount(); got != 2 {
+			t.Errorf("handler calls = %d, want 2", got)
+		}
+	})
+}
+
+func TestMiddlewareStoresClientErrors(t *testing.T) {
+	testutil.ForEachBackend(t, storeBackends, func(t *testing.T, backend testutil.Backend[Store]) {
+		h := &handler{statuses: []int{http.StatusConflict}}
+		router := newRouter(backend.New(t), h)
+
+		post(router, "key-1", `{"name": "Alice"}`)
+		w := post(router, "key-1", `{"name": "Alice"}`)
+		if w.Code != http.StatusConflict || w.Header().Get(ReplayedHeader) != "true" {
+			t.Errorf("retry = %d replayed %q, want the stored %d", w.Code, w.Header().Get(ReplayedHeader), http.StatusConflict)
+		}
+		if got := h.callCount(); got != 1 {
+			t.Errorf("handler calls = %d, want 1", got)
+		}
+	})
+}
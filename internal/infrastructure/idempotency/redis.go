@@ -0,0 +1,79 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisStore keeps records in Redis so every server instance shares them
+type redisStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisStore creates an idempotency store backed by the given Redis client
+func NewRedisStore(client redis.UniversalClient) Store {
+	return &redisStore{client: client, prefix: "idempotency:"}
+}
+
+// reserveAttempts bounds how often Reserve retries a key that expired
+// between SET NX and GET
+const reserveAttempts = 3
+
+// Reserve claims key with SET NX, reading the existing record on conflict
+func (s *redisStore) Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (*Record, bool, error) {
+	value, err := json.Marshal(Record{Fingerprint: fingerprint})
+	if err != nil {
+		return nil, false, err
+	}
+
+	for attempt := 0; attempt < reserveAttempts; attempt++ {
+		reserved, err := s.client.SetNX(ctx, s.prefix+key, value, ttl).Result()
+		if err != nil {
+			return nil, false, err
+		}
+		if reserved {
+			return nil, true, nil
+		}
+
+		data, err := s.client.Get(ctx, s.prefix+key).Bytes()
+		if errors.Is(err, redis.Nil) {
+			// Expired between SET NX and GET, so try again
+			continue
+		}
+		if err != nil {
+			return nil, false, err
+		}
+
+		var record Record
+		if err := json.Unmarshal(data, &record); err != nil {
+			return nil, false, err
+		}
+		return &record, false, nil
+	}
+	return nil, false, fmt.Errorf("idempotency key %q kept expiring during %d attempts to reserve it", key, reserveAttempts)
+}
+
+// Complete stores the response of key
+func (s *redisStore) Complete(ctx context.Context, key string, record *Record, ttl time.Duration) error {
+	value, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, s.prefix+key, value, ttl).Err()
+}
+
+// Release drops key
+func (s *redisStore) Release(ctx context.Context, key string) error {
+	return s.client.Del(ctx, s.prefix+key).Err()
+}
+
+// Close releases the Redis connections
+func (s *redisStore) Close() error {
+	return s.client.Close()
+}
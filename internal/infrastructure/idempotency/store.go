@@ -0,0 +1,101 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Record is what is remembered about a request with an idempotency key
+type Record struct {
+	// Fingerprint identifies the request payload the key was first used with
+	Fingerprint string `json:"fingerprint"`
+	// Completed is false while the first request is still being handled
+	Completed bool        `json:"completed"`
+	Status    int         `json:"status,omitempty"`
+	Header    http.Header `json:"header,omitempty"`
+	Body      []byte      `json:"body,omitempty"`
+}
+
+// Store keeps idempotency records
+type Store interface {
+	// Reserve claims key for a new request with the given fingerprint until
+	// ttl elapses. When the key is already taken it returns the existing
+	// record and false.
+	Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (*Record, bool, error)
+	// Complete stores the response of a reserved key until ttl elapses
+	Complete(ctx context.Context, key string, record *Record, ttl time.Duration) error
+	// Release drops a reservation so the request can be retried
+	Release(ctx context.Context, key string) error
+}
+
+// memoryEntry is a record with its expiry
+type memoryEntry struct {
+	record    Record
+	expiresAt time.Time
+}
+
+// memoryStore keeps records in process, so they are not shared between
+// server instances
+type memoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]*memoryEntry
+	lastSweep time.Time
+}
+
+// NewMemoryStore creates an in-process idempotency store
+func NewMemoryStore() Store {
+	return &memoryStore{entries: make(map[string]*memoryEntry)}
+}
+
+// Reserve claims key unless an unexpired record exists
+func (s *memoryStore) Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (*Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	if entry, ok := s.entries[key]; ok && now.Before(entry.expiresAt) {
+		record := entry.record
+		return &record, false, nil
+	}
+
+	s.entries[key] = &memoryEntry{
+		record:    Record{Fingerprint: fingerprint},
+		expiresAt: now.Add(ttl),
+	}
+	return nil, true, nil
+}
+
+// Complete stores the response of key
+func (s *memoryStore) Complete(ctx context.Context, key string, record *Record, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[key] = &memoryEntry{record: *record, expiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+// Release drops key
+func (s *memoryStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// sweep drops expired records at most once a minute
+func (s *memoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for key, entry := range s.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package idempotency
+
+import (
+	"context"
+	"go-clean-architecture/internal/testutil"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// storeBackends lists the in-process store and Redis, stood in for by miniredis
+var storeBackends = []testutil.Backend[Store]{
+	{Name: "memory", New: func(t *testing.T) Store { return NewMemoryStore() }},
+	{Name: "redis", New: func(t *testing.T) Store {
+		client, _ := testutil.NewRedis(t)
+		return NewRedisStore(client)
+	}},
+}
+
+func TestStoreLifecycle(t *testing.T) {
+	testutil.ForEachBackend(t, storeBackends, func(t *testing.T, backend testutil.Backend[Store]) {
+		ctx := context.Background()
+		store := backend.New(t)
+
+		if _, reserved, err := store.Reserve(ctx, "key", "a", time.Minute); err != nil || !reserved {
+			t.Fatalf("Reserve() = %v, %v, want a reservation", reserved, err)
+		}
+		record, reserved, err := store.Reserve(ctx, "key", "b", time.Minute)
+		if err != nil || reserved {
+			t.Fatalf("Reserve() again = %v, %v, want the existing record", reserved, err)
+		}
+		if record.Fingerprint != "a" || record.Completed {
+			t.Errorf("Reserve() record = %+v, want the pending reservation", record)
+		}
+
+		completed := &Record{Fingerprint: "a", Completed: true, Status: http.StatusCreated, Header: http.Header{"Etag": {`"1"`}}, Body: []byte("{}")}
+		if err := store.Complete(ctx, "key", completed, time.Minute); err != nil {
+			t.Fatalf("Complete() error = %v", err)
+		}
+		record, _, err = store.Reserve(ctx, "key", "a", time.Minute)
+		if err != nil || !record.Completed || record.Status != http.StatusCreated || string(record.Body) != "{}" || record.Header.Get("ETag") != `"1"` {
+			t.Errorf("Reserve() after Complete() = %+v, %v, want the stored response", record, err)
+		}
+
+		if err := store.Release(ctx, "key"); err != nil {
+			t.Fatalf("Release() error = %v", err)
+		}
+		if _, reserved, err := store.Reserve(ctx, "key", "b", time.Minute); err != nil || !reserved {
+			t.Errorf("Reserve() after Release() = %v, %v, want a reservation", reserved, err)
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"go-clean-architecture/pkg/requestid"
+	"io"
+	"log/slog"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Supported log formats
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// Config holds logger configuration
+type Config struct {
+	// Level is one of debug, info, warn or error
+	Level string
+	// Format is either "json" or "text"
+	Format string
+}
+
+// New creates a structured logger writing to w. Records logged with a
+// context carry the request ID stored in it.
+func New(config *Config, w io.Writer) (*slog.Logger, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(config.Level)); err != nil {
+		return nil, fmt.Errorf("invalid LOG_LEVEL %q: %w", config.Level, err)
+	}
+
+	options := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch strings.ToLower(config.Format) {
+	case FormatJSON:
+		handler = slog.NewJSONHandler(w, options)
+	case FormatText:
+		handler = slog.NewTextHandler(w, options)
+	default:
+		return nil, fmt.Errorf("invalid LOG_FORMAT %q, expected %q or %q", config.Format, FormatJSON, FormatText)
+	}
+
+	return slog.New(contextHandler{handler}), nil
+}
+
+// contextHandler adds the request ID and trace context carried by the
+// context to every record
+type contextHandler struct {
+	slog.Handler
+}
+
+// Handle adds the request ID before passing the record on
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := requestid.FromContext(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		record.AddAttrs(
+			slog.String("trace_id", span.TraceID().String()),
+			slog.String("span_id", span.SpanID().String()),
+		)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+// WithAttrs keeps the wrapper around the derived handler
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup keeps the wrapper around the derived handler
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
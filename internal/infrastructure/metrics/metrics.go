@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"database/sql"
+	"go-clean-architecture/internal/usecase/interfaces"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes every metric exported by the service
+const namespace = "cleanarch"
+
+// Metrics owns the Prometheus registry and the service's collectors
+type Metrics struct {
+	registry        *prometheus.Registry
+	httpRequests    *prometheus.CounterVec
+	httpDuration    *prometheus.HistogramVec
+	usecaseRequests *prometheus.CounterVec
+}
+
+// NewMetrics creates a registry with the HTTP, use case and runtime collectors
+func NewMetrics() *Metrics {
+	registry := prometheus.NewRegistry()
+
+	m := &Metrics{
+		registry: registry,
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Number of HTTP requests by route, method and status.",
+		}, []string{"route", "method", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latency by route, method and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		usecaseRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "usecase",
+			Name:      "operations_total",
+			Help:      "Number of use case operations by operation and outcome.",
+		}, []string{"operation", "outcome"}),
+	}
+
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.usecaseRequests,
+	)
+	return m
+}
+
+// RegisterDBStats exports the connection pool statistics of db
+func (m *Metrics) RegisterDBStats(db *sql.DB, name string) {
+	m.registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// CountOperation increments the counter for a use case operation
+func (m *Metrics) CountOperation(operation, outcome string) {
+	m.usecaseRequests.WithLabelValues(operation, outcome).Inc()
+}
+
+// Middleware records request count and latency labeled by route template
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		// Unmatched paths share one label so arbitrary URLs cannot explode cardinality
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+
+		m.httpRequests.WithLabelValues(route, c.Request.Method, status).Inc()
+		m.httpDuration.WithLabelValues(route, c.Request.Method, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+// Handler serves the registry in the Prometheus text format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Ensure Metrics satisfies the use case contract
+var _ interfaces.OperationMetrics = (*Metrics)(nil)
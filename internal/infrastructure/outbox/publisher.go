@@ -0,0 +1,53 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"go-clean-architecture/internal/usecase/interfaces"
+	"log/slog"
+)
+
+// logPublisher publishes events as structured log lines. It is the default
+// until a message broker is configured.
+type logPublisher struct {
+	logger *slog.Logger
+}
+
+// NewLogPublisher creates a publisher that writes every event to logger
+func NewLogPublisher(logger *slog.Logger) interfaces.EventPublisher {
+	return &logPublisher{logger: logger}
+}
+
+// Publish logs the event
+func (p *logPublisher) Publish(ctx context.Context, message *interfaces.OutboxMessage) error {
+	p.logger.InfoContext(ctx, "Domain event",
+		"event_id", message.ID,
+		"event_type", message.EventType,
+		"aggregate_id", message.AggregateID,
+		"occurred_at", message.OccurredAt,
+		"payload", json.RawMessage(message.Payload),
+	)
+	return nil
+}
+
+// multiPublisher publishes every event to several publishers
+type multiPublisher struct {
+	publishers []interfaces.EventPublisher
+}
+
+// NewMultiPublisher creates a publisher that publishes to each publisher in
+// turn. A failure retries the event on all of them, which at-least-once
+// delivery allows.
+func NewMultiPublisher(publishers ...interfaces.EventPublisher) interfaces.EventPublisher {
+	return &multiPublisher{publishers: publishers}
+}
+
+// Publish publishes the event to every publisher, stopping at the first error
+func (p *multiPublisher) Publish(ctx context.Context, message *interfaces.OutboxMessage) error {
+	for _, publisher := range p.publishers {
+		if err := publisher.Publish(ctx, message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
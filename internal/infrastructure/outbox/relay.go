@@ -0,0 +1,102 @@
+package outbox
+
+import (
+	"context"
+	"go-clean-architecture/internal/usecase/interfaces"
+	"go-clean-architecture/pkg/backoff"
+	"log/slog"
+	"time"
+)
+
+// Config holds outbox relay configuration
+type Config struct {
+	// PollInterval is how long the relay sleeps when there is nothing to publish
+	PollInterval time.Duration
+	// BatchSize bounds the messages published per poll
+	BatchSize int
+	// MinBackoff is the delay after the first failed attempt. It doubles on
+	// every further failure up to MaxBackoff.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// Relay publishes outbox messages until its context is cancelled.
+// Messages are marked published only after the publisher succeeded, so a
+// crash in between publishes them again.
+type Relay struct {
+	outbox    interfaces.OutboxRepository
+	tx        interfaces.TxManager
+	publisher interfaces.EventPublisher
+	config    *Config
+}
+
+// NewRelay creates a new outbox relay instance
+func NewRelay(outbox interfaces.OutboxRepository, tx interfaces.TxManager, publisher interfaces.EventPublisher, config *Config) *Relay {
+	return &Relay{
+		outbox:    outbox,
+		tx:        tx,
+		publisher: publisher,
+		config:    config,
+	}
+}
+
+// Run polls the outbox until ctx is cancelled
+func (r *Relay) Run(ctx context.Context) {
+	slog.Info("Outbox relay started", "poll_interval", r.config.PollInterval)
+	for {
+		published, err := r.RelayOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "Outbox relay failed", "error", err)
+		}
+
+		// Keep draining while there is work, otherwise wait for the next poll
+		if published > 0 && err == nil && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			slog.Info("Outbox relay stopped")
+			return
+		case <-time.After(r.config.PollInterval):
+		}
+	}
+}
+
+// RelayOnce publishes one batch of due messages and returns how many were
+// published. The batch holds at most one message per aggregate, so
+// publishing them one after the other keeps per-aggregate order.
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	published := 0
+	err := r.tx.WithinTx(ctx, func(ctx context.Context) error {
+		published = 0
+		messages, err := r.outbox.Pending(ctx, time.Now(), r.config.BatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, message := range messages {
+			if err := r.publisher.Publish(ctx, message); err != nil {
+				next := time.Now().Add(backoff.Exponential(message.Attempts+1, r.config.MinBackoff, r.config.MaxBackoff))
+				slog.WarnContext(ctx, "Failed to publish event",
+					"event_id", message.ID,
+					"event_type", message.EventType,
+					"attempt", message.Attempts+1,
+					"next_attempt_at", next,
+					"error", err,
+				)
+				if err := r.outbox.MarkFailed(ctx, message.ID, next, err.Error()); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := r.outbox.MarkPublished(ctx, message.ID); err != nil {
+				return err
+			}
+			published++
+		}
+		return nil
+	})
+	return published, err
+}
@@ -0,0 +1,88 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Route groups with their own limits
+const (
+	GroupAuth         = "auth"
+	GroupRegistration = "registration"
+	GroupUsers        = "users"
+	GroupWebhooks     = "webhooks"
+)
+
+// Supported store drivers
+const (
+	DriverMemory = "memory"
+	DriverRedis  = "redis"
+)
+
+// Limit is a token bucket holding up to Burst tokens that refills at Burst
+// tokens per Period. The zero Limit allows everything.
+type Limit struct {
+	Burst  int
+	Period time.Duration
+}
+
+// IsZero reports whether the limit is disabled
+func (l Limit) IsZero() bool {
+	return l.Burst <= 0 || l.Period <= 0
+}
+
+// Rate returns the refill rate in tokens per second
+func (l Limit) Rate() float64 {
+	return float64(l.Burst) / l.Period.Seconds()
+}
+
+// String formats the limit the way ParseLimit reads it
+func (l Limit) String() string {
+	if l.IsZero() {
+		return "off"
+	}
+	return strconv.Itoa(l.Burst) + "/" + l.Period.String()
+}
+
+// ParseLimit parses "<requests>/<period>" such as "10/m", "100/1h" or
+// "5/30s". "off" disables the limit.
+func ParseLimit(value string) (Limit, error) {
+	value = strings.TrimSpace(value)
+	if value == "off" || value == "0" {
+		return Limit{}, nil
+	}
+
+	count, period, ok := strings.Cut(value, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid rate limit %q, expected <requests>/<period>", value)
+	}
+	burst, err := strconv.Atoi(count)
+	if err != nil || burst < 1 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: requests must be a positive integer", value)
+	}
+
+	// A bare unit means one of it
+	if period == "s" || period == "m" || period == "h" {
+		period = "1" + period
+	}
+	duration, err := time.ParseDuration(period)
+	if err != nil || duration <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: bad period", value)
+	}
+	return Limit{Burst: burst, Period: duration}, nil
+}
+
+// Config holds rate limiting configuration
+type Config struct {
+	// Driver is either "memory" or "redis"
+	Driver string
+	// Limits per route group
+	Limits map[string]Limit
+	// TrustAPIKey keys anonymous clients by their X-API-Key header instead
+	// of their IP. Only enable it when keys are verified before the server.
+	TrustAPIKey bool
+	// RedisURL is a redis:// URL used by the redis driver
+	RedisURL string
+}
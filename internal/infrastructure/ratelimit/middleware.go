@@ -0,0 +1,97 @@
+package ratelimit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"go-clean-architecture/internal/usecase"
+	"go-clean-architecture/pkg/response"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// APIKeyHeader identifies API clients that are rate limited by key
+const APIKeyHeader = "X-API-Key"
+
+// NewStore creates the store for the configured driver
+func NewStore(config *Config) (Store, error) {
+	switch config.Driver {
+	case DriverMemory, "":
+		return NewMemoryStore(), nil
+	case DriverRedis:
+		options, err := redis.ParseURL(config.RedisURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid REDIS_URL: %w", err)
+		}
+		return NewRedisStore(redis.NewClient(options)), nil
+	default:
+		return nil, fmt.Errorf("unsupported RATE_LIMIT_STORE %q", config.Driver)
+	}
+}
+
+// Limiter rate limits requests with a token bucket per client and route group
+type Limiter struct {
+	store  Store
+	config *Config
+}
+
+// NewLimiter creates a new rate limiter instance
+func NewLimiter(store Store, config *Config) *Limiter {
+	return &Limiter{store: store, config: config}
+}
+
+// Middleware limits requests of the given route group. Clients are
+// identified by the authenticated user, then the X-API-Key header when
+// trusted, then their IP, so it should run after the middleware that
+// authenticates the request but before the one that rejects it, or forged
+// tokens would never be limited.
+// Rejected requests get a 429 problem response with Retry-After.
+func (l *Limiter) Middleware(group string) gin.HandlerFunc {
+	limit := l.config.Limits[group]
+	if limit.IsZero() {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		result, err := l.store.Take(ctx, group+":"+l.clientKey(c), limit)
+		if err != nil {
+			// Fail open, an unavailable store must not take the API down
+			slog.ErrorContext(ctx, "Rate limit store unavailable", "error", err)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(limit.Burst))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(result.RetryAfter.Seconds()))))
+			response.Error(c, http.StatusTooManyRequests, "rate_limited", "Too many requests, retry later", nil)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// clientKey identifies the client a request counts against
+func (l *Limiter) clientKey(c *gin.Context) string {
+	if actor, ok := usecase.UserFromContext(c.Request.Context()); ok {
+		return "user:" + strconv.FormatUint(uint64(actor.ID), 10)
+	}
+	// Unverified keys would let a client escape its limit by sending a new
+	// key with every request, so they are only used when a gateway checks them
+	if key := c.GetHeader(APIKeyHeader); key != "" && l.config.TrustAPIKey {
+		// Hash so raw keys never end up in the store
+		sum := sha256.Sum256([]byte(key))
+		return "key:" + hex.EncodeToString(sum[:8])
+	}
+	// ClientIP only honours forwarding headers from the server's trusted
+	// proxies, so a client cannot pick a fresh address per request
+	return "ip:" + c.ClientIP()
+}
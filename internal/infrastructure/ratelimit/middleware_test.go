@@ -0,0 +1,224 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"go-clean-architecture/internal/entity"
+	"go-clean-architecture/internal/testutil"
+	"go-clean-architecture/internal/usecase"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// failingStore stands in for an unreachable store
+type failingStore struct{}
+
+func (failingStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	return Result{}, errors.New("connection refused")
+}
+
+// newRouter serves GET /limited behind the auth group limit of limiter
+func newRouter(t *testing.T, limiter *Limiter, trustedProxies []string) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		t.Fatalf("SetTrustedProxies() error = %v", err)
+	}
+	router.GET("/limited", limiter.Middleware(GroupAuth), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return router
+}
+
+// request describes a request sent to the limited route. A non-zero
+// userID authenticates it as that user.
+type request struct {
+	remoteAddr string
+	headers    map[string]string
+	userID     uint
+}
+
+// send serves req and returns the recorded response
+func send(router *gin.Engine, req request) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, "/limited", nil)
+	if req.remoteAddr != "" {
+		r.RemoteAddr = req.remoteAddr
+	}
+	for name, value := range req.headers {
+		r.Header.Set(name, value)
+	}
+	if req.userID != 0 {
+		r = r.WithContext(usecase.ContextWithUser(r.Context(), &entity.User{ID: req.userID}))
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	return w
+}
+
+// newTestLimiter limits the auth group to limit
+func newTestLimiter(t *testing.T, store Store, limit string, trustAPIKey bool) *Limiter {
+	t.Helper()
+	l, err := ParseLimit(limit)
+	if err != nil {
+		t.Fatalf("ParseLimit() error = %v", err)
+	}
+	return NewLimiter(store, &Config{Limits: map[string]Limit{GroupAuth: l}, TrustAPIKey: trustAPIKey})
+}
+
+func TestMiddlewareHeaders(t *testing.T) {
+	testutil.ForEachBackend(t, storeBackends, func(t *testing.T, backend testutil.Backend[Store]) {
+		router := newRouter(t, newTestLimiter(t, backend.New(t), "2/m", false), nil)
+
+		for _, remaining := range []string{"1", "0"} {
+			w := send(router, request{})
+			if w.Code != http.StatusNoContent {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusNoContent)
+			}
+			if got := w.Header().Get("RateLimit-Limit"); got != "2" {
+				t.Errorf("RateLimit-Limit = %q, want %q", got, "2")
+			}
+			if got := w.Header().Get("RateLimit-Remaining"); got != remaining {
+				t.Errorf("RateLimit-Remaining = %q, want %q", got, remaining)
+			}
+			if got := w.Header().Get("Retry-After"); got != "" {
+				t.Errorf("Retry-After = %q on an allowed request", got)
+			}
+		}
+
+		// A token comes back every 30s
+		w := send(router, request{})
+		if w.Code != http.StatusTooManyRequests {
+			t.Fatalf("status = %d, want %d", w.Code, http.StatusTooManyRequests)
+		}
+		if got := w.Header().Get("Retry-After"); got != "30" {
+			t.Errorf("Retry-After = %q, want %q", got, "30")
+		}
+		if got := w.Header().Get("RateLimit-Remaining"); got != "0" {
+			t.Errorf("RateLimit-Remaining = %q, want %q", got, "0")
+		}
+		if !strings.Contains(w.Body.String(), "rate_limited") {
+			t.Errorf("body = %s, want a rate_limited error", w.Body.String())
+		}
+	})
+}
+
+func TestMiddlewareRetryAfterRefill(t *testing.T) {
+	testutil.ForEachBackend(t, storeBackends, func(t *testing.T, backend testutil.Backend[Store]) {
+		router := newRouter(t, newTestLimiter(t, backend.New(t), "1/100ms", false), nil)
+
+		if w := send(router, request{}); w.Code != http.StatusNoContent {
+			t.Fatalf("status = %d, want %d", w.Code, http.StatusNoContent)
+		}
+		w := send(router, request{})
+		if w.Code != http.StatusTooManyRequests {
+			t.Fatalf("status = %d, want %d", w.Code, http.StatusTooManyRequests)
+		}
+		// Retry-After rounds up to whole seconds
+		if got := w.Header().Get("Retry-After"); got != "1" {
+			t.Errorf("Retry-After = %q, want %q", got, "1")
+		}
+
+		time.Sleep(110 * time.Millisecond)
+		if w := send(router, request{}); w.Code != http.StatusNoContent {
+			t.Errorf("status after refill = %d, want %d", w.Code, http.StatusNoContent)
+		}
+	})
+}
+
+func TestMiddlewareClientKey(t *testing.T) {
+	tests := []struct {
+		name           string
+		trustAPIKey    bool
+		trustedProxies []string
+		first, second  request
+		wantShared     bool
+	}{
+		{
+			name:       "same peer",
+			first:      request{remoteAddr: "203.0.113.1:1000"},
+			second:     request{remoteAddr: "203.0.113.1:2000"},
+			wantShared: true,
+		},
+		{
+			name:   "different peers",
+			first:  request{remoteAddr: "203.0.113.1:1000"},
+			second: request{remoteAddr: "203.0.113.2:1000"},
+		},
+		{
+			name:       "forwarded for from an untrusted peer",
+			first:      request{remoteAddr: "203.0.113.1:1000", headers: map[string]string{"X-Forwarded-For": "198.51.100.1"}},
+			second:     request{remoteAddr: "203.0.113.1:1000", headers: map[string]string{"X-Forwarded-For": "198.51.100.2"}},
+			wantShared: true,
+		},
+		{
+			name:           "forwarded for from a trusted proxy",
+			trustedProxies: []string{"10.0.0.0/8"},
+			first:          request{remoteAddr: "10.0.0.1:1000", headers: map[string]string{"X-Forwarded-For": "198.51.100.1"}},
+			second:         request{remoteAddr: "10.0.0.1:1000", headers: map[string]string{"X-Forwarded-For": "198.51.100.2"}},
+		},
+		{
+			name:   "users",
+			first:  request{remoteAddr: "203.0.113.1:1000", userID: 1},
+			second: request{remoteAddr: "203.0.113.1:1000", userID: 2},
+		},
+		{
+			name:       "untrusted API keys",
+			first:      request{remoteAddr: "203.0.113.1:1000", headers: map[string]string{APIKeyHeader: "a"}},
+			second:     request{remoteAddr: "203.0.113.1:1000", headers: map[string]string{APIKeyHeader: "b"}},
+			wantShared: true,
+		},
+		{
+			name:        "trusted API keys",
+			trustAPIKey: true,
+			first:       request{remoteAddr: "203.0.113.1:1000", headers: map[string]string{APIKeyHeader: "a"}},
+			second:      request{remoteAddr: "203.0.113.1:1000", headers: map[string]string{APIKeyHeader: "b"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newRouter(t, newTestLimiter(t, NewMemoryStore(), "1/m", tt.trustAPIKey), tt.trustedProxies)
+
+			if w := send(router, tt.first); w.Code != http.StatusNoContent {
+				t.Fatalf("first status = %d, want %d", w.Code, http.StatusNoContent)
+			}
+			want := http.StatusNoContent
+			if tt.wantShared {
+				want = http.StatusTooManyRequests
+			}
+			if w := send(router, tt.second); w.Code != want {
+				t.Errorf("second status = %d, want %d", w.Code, want)
+			}
+		})
+	}
+}
+
+func TestMiddlewareFailsOpen(t *testing.T) {
+	router := newRouter(t, newTestLimiter(t, failingStore{}, "1/m", false), nil)
+
+	for i := 0; i < 3; i++ {
+		w := send(router, request{})
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("status = %d, want %d", w.Code, http.StatusNoContent)
+		}
+		if got := w.Header().Get("RateLimit-Limit"); got != "" {
+			t.Errorf("RateLimit-Limit = %q without a store", got)
+		}
+	}
+}
+
+func TestMiddlewareDisabledGroup(t *testing.T) {
+	router := newRouter(t, newTestLimiter(t, NewMemoryStore(), "off", false), nil)
+
+	for i := 0; i < 3; i++ {
+		if w := send(router, request{}); w.Code != http.StatusNoContent {
+			t.Fatalf("status = %d, want %d", w.Code, http.StatusNoContent)
+		}
+	}
+}
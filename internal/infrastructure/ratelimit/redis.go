@@ -0,0 +1,76 @@
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// takeScript refills and takes from a bucket atomically. The bucket is a
+// hash of its tokens and the time of the last take in milliseconds, and
+// expires once it would be full again.
+var takeScript = redis.NewScript(`
+local burst = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local period = tonumber(ARGV[3])
+local now = tonumber(ARGV[4])
+
+local state = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(state[1]) or burst
+local ts = tonumber(state[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - ts) / 1000 * rate)
+
+local allowed = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+end
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "ts", tostring(now))
+redis.call("PEXPIRE", KEYS[1], period)
+return {allowed, tostring(tokens)}
+`)
+
+// redisStore keeps buckets in Redis so limits apply across server instances
+type redisStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisStore creates a rate limit store backed by the given Redis client
+func NewRedisStore(client redis.UniversalClient) Store {
+	return &redisStore{client: client, prefix: "ratelimit:"}
+}
+
+// Take runs the token bucket script for key
+func (s *redisStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	values, err := takeScript.Run(ctx, s.client, []string{s.prefix + key},
+		limit.Burst,
+		limit.Rate(),
+		limit.Period.Milliseconds(),
+		time.Now().UnixMilli(),
+	).Slice()
+	if err != nil {
+		return Result{}, err
+	}
+
+	allowed, _ := values[0].(int64)
+	tokensStr, _ := values[1].(string)
+	tokens, err := strconv.ParseFloat(tokensStr, 64)
+	if err != nil {
+		return Result{}, err
+	}
+
+	if allowed == 1 {
+		return Result{Allowed: true, Remaining: int(tokens)}, nil
+	}
+	wait := (1 - tokens) / limit.Rate()
+	return Result{RetryAfter: time.Duration(wait * float64(time.Second))}, nil
+}
+
+// Close releases the Redis connections
+func (s *redisStore) Close() error {
+	return s.client.Close()
+}
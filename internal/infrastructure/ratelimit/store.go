@@ -0,0 +1,85 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Result is the outcome of taking a token from a bucket
+type Result struct {
+	Allowed bool
+	// Remaining is the number of whole tokens left
+	Remaining int
+	// RetryAfter is how long until a token is available when not allowed
+	RetryAfter time.Duration
+}
+
+// Store keeps token buckets
+type Store interface {
+	// Take removes a token from the bucket of key if one is available
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// bucket is the state of one token bucket
+type bucket struct {
+	tokens float64
+	last   time.Time
+	period time.Duration
+}
+
+// memoryStore keeps buckets in process, so limits apply per server instance
+type memoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewMemoryStore creates an in-process rate limit store
+func NewMemoryStore() Store {
+	return &memoryStore{buckets: make(map[string]*bucket)}
+}
+
+// Take refills the bucket for the time passed and takes a token
+func (s *memoryStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now, period: limit.Period}
+		s.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.last).Seconds()*limit.Rate())
+	b.last = now
+
+	return take(&b.tokens, limit), nil
+}
+
+// sweep drops buckets idle for a whole period, at most once a minute.
+// Such a bucket has refilled completely, so dropping it changes nothing.
+func (s *memoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if now.Sub(b.last) >= b.period {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// take removes a token if one is available and describes the bucket
+func take(tokens *float64, limit Limit) Result {
+	if *tokens >= 1 {
+		*tokens--
+		return Result{Allowed: true, Remaining: int(*tokens)}
+	}
+	wait := (1 - *tokens) / limit.Rate()
+	return Result{RetryAfter: time.Duration(wait * float64(time.Second))}
+}
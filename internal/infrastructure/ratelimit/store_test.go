@@ -0,0 +1,101 @@
+package ratelimit
+
+import (
+	"context"
+	"go-clean-architecture/internal/testutil"
+	"testing"
+	"time"
+)
+
+// storeBackends lists the in-process store and Redis, stood in for by miniredis
+var storeBackends = []testutil.Backend[Store]{
+	{Name: "memory", New: func(t *testing.T) Store { return NewMemoryStore() }},
+	{Name: "redis", New: func(t *testing.T) Store {
+		client, _ := testutil.NewRedis(t)
+		return NewRedisStore(client)
+	}},
+}
+
+// mustTake takes a token and fails the test on a store error
+func mustTake(t *testing.T, store Store, key string, limit Limit) Result {
+	t.Helper()
+	result, err := store.Take(context.Background(), key, limit)
+	if err != nil {
+		t.Fatalf("Take() error = %v", err)
+	}
+	return result
+}
+
+func TestStoreTake(t *testing.T) {
+	testutil.ForEachBackend(t, storeBackends, func(t *testing.T, backend testutil.Backend[Store]) {
+		store := backend.New(t)
+		limit := Limit{Burst: 3, Period: time.Minute}
+
+		for want := 2; want >= 0; want-- {
+			result := mustTake(t, store, "client", limit)
+			if !result.Allowed || result.Remaining != want {
+				t.Fatalf("Take() = %+v, want allowed with %d remaining", result, want)
+			}
+		}
+
+		// One token refills every 20s
+		result := mustTake(t, store, "client", limit)
+		if result.Allowed || result.Remaining != 0 {
+			t.Fatalf("Take() = %+v, want denied", result)
+		}
+		if result.RetryAfter <= 19*time.Second || result.RetryAfter > 20*time.Second {
+			t.Errorf("RetryAfter = %s, want about 20s", result.RetryAfter)
+		}
+
+		// Buckets are per key
+		if result := mustTake(t, store, "other", limit); !result.Allowed || result.Remaining != 2 {
+			t.Errorf("Take() other = %+v, want allowed with 2 remaining", result)
+		}
+	})
+}
+
+func TestStoreRefill(t *testing.T) {
+	testutil.ForEachBackend(t, storeBackends, func(t *testing.T, backend testutil.Backend[Store]) {
+		store := backend.New(t)
+		// One token every 50ms
+		limit := Limit{Burst: 2, Period: 100 * time.Millisecond}
+
+		mustTake(t, store, "client", limit)
+		mustTake(t, store, "client", limit)
+		denied := mustTake(t, store, "client", limit)
+		if denied.Allowed {
+			t.Fatalf("Take() = %+v, want denied", denied)
+		}
+
+		time.Sleep(denied.RetryAfter + 5*time.Millisecond)
+		if result := mustTake(t, store, "client", limit); !result.Allowed || result.Remaining != 0 {
+			t.Fatalf("Take() after refill = %+v, want allowed with 0 remaining", result)
+		}
+		if result := mustTake(t, store, "client", limit); result.Allowed {
+			t.Fatalf("Take() = %+v, want denied until the next token", result)
+		}
+
+		// A bucket never holds more than its burst
+		time.Sleep(3 * limit.Period)
+		if result := mustTake(t, store, "client", limit); !result.Allowed || result.Remaining != 1 {
+			t.Errorf("Take() after idling = %+v, want allowed with 1 remaining", result)
+		}
+	})
+}
+
+func TestRedisStoreExpiresBuckets(t *testing.T) {
+	client, server := testutil.NewRedis(t)
+	store := NewRedisStore(client)
+	limit := Limit{Burst: 5, Period: time.Minute}
+
+	mustTake(t, store, "client", limit)
+	if ttl := server.TTL("ratelimit:client"); ttl != limit.Period {
+		t.Errorf("bucket TTL = %s, want %s", ttl, limit.Period)
+	}
+
+	// An expired bucket starts full again
+	server.FastForward(limit.Period)
+	if result := mustTake(t, store, "client", limit); result.Remaining != limit.Burst-1 {
+		t.Errorf("Take() after expiry = %+v, want %d remaining", result, limit.Burst-1)
+	}
+}
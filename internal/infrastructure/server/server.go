@@ -2,65 +2,163 @@ package server
 
 import (
 	"context"
+	"fmt"
 	"go-clean-architecture/internal/adapter/controller"
+	"go-clean-architecture/internal/adapter/repository"
+	"go-clean-architecture/internal/infrastructure/cors"
+	"go-clean-architecture/internal/infrastructure/health"
+	"go-clean-architecture/internal/infrastructure/idempotency"
+	"go-clean-architecture/internal/infrastructure/metrics"
+	"go-clean-architecture/internal/infrastructure/ratelimit"
+	"go-clean-architecture/pkg/requestid"
 	"go-clean-architecture/pkg/response"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// Config holds HTTP server configuration
+type Config struct {
+	Port string
+	// Mode is the gin mode, "debug" enables gin's route logging
+	Mode string
+	// RequestTimeout bounds the context of every request
+	RequestTimeout time.Duration
+	ReadTimeout    time.Duration
+	WriteTimeout   time.Duration
+	IdleTimeout    time.Duration
+	// ShutdownTimeout bounds how long in-flight requests may finish on shutdown
+	ShutdownTimeout time.Duration
+	// TrustedProxies lists the IPs and CIDRs whose X-Forwarded-For and
+	// X-Real-IP headers are believed, none when empty
+	TrustedProxies []string
+}
+
 // Server represents the HTTP server
 type Server struct {
-	router         *gin.Engine
-	httpServer     *http.Server
-	userController *controller.UserController
+	config            *Config
+	router            *gin.Engine
+	httpServer        *http.Server
+	userController    *controller.UserController
+	authController    *controller.AuthController
+	webhookController *controller.WebhookController
+	health            *health.Health
+	metrics           *metrics.Metrics
+	idempotency       *idempotency.Idempotency
+	limiter           *ratelimit.Limiter
 }
 
 // NewServer creates a new HTTP server instance
-func NewServer(userController *controller.UserController) *Server {
+func NewServer(config *Config, userController *controller.UserController, authController *controller.AuthController, webhookController *controller.WebhookController, health *health.Health, metrics *metrics.Metrics, idempotency *idempotency.Idempotency, limiter *ratelimit.Limiter, cors *cors.Policy) (*Server, error) {
 	// Set gin mode based on environment
-	if os.Getenv("GIN_MODE") != "debug" {
+	if config.Mode != gin.DebugMode {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
+	// Report validation errors by JSON field name
+	if validate, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		response.UseJSONFieldNames(validate)
+	}
+
 	router := gin.New()
 
+	// The client IP keys rate limits and is logged and traced, so forwarding
+	// headers only count when the direct peer is a known proxy
+	if err := router.SetTrustedProxies(config.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid trusted proxies: %w", err)
+	}
+
 	// Add middlewares
-	router.Use(gin.Logger())
-	router.Use(gin.Recovery())
-	router.Use(corsMiddleware())
-	router.Use(timeoutMiddleware(30 * time.Second))
+	router.Use(tracingMiddleware())
+	router.Use(requestIDMiddleware())
+	router.Use(sessionMiddleware())
+	router.Use(requestLogger())
+	router.Use(metrics.Middleware())
+	router.Use(gin.CustomRecovery(recoverPanic))
+	router.Use(controller.ErrorHandler())
+	router.Use(cors.Middleware())
+	router.Use(timeoutMiddleware(config.RequestTimeout))
 
 	server := &Server{
-		router:         router,
-		userController: userController,
+		config:            config,
+		router:            router,
+		userController:    userController,
+		authController:    authController,
+		webhookController: webhookController,
+		health:            health,
+		metrics:           metrics,
+		idempotency:       idempotency,
+		limiter:           limiter,
 	}
 
 	server.setupRoutes()
-	return server
+	return server, nil
 }
 
 // setupRoutes configures all API routes
 func (s *Server) setupRoutes() {
-	// Health check endpoint
-	s.router.GET("/health", s.healthCheck)
+	// Health check endpoints, /health is kept as an alias of readiness
+	s.router.GET("/health", s.readiness)
+	s.router.GET("/health/live", s.liveness)
+	s.router.GET("/health/ready", s.readiness)
+
+	// Prometheus metrics endpoint
+	s.router.GET("/metrics", gin.WrapH(s.metrics.Handler()))
 
 	// API v1 routes
 	v1 := s.router.Group("/api/v1")
 	{
-		// User routes
+		// Auth routes
+		auth := v1.Group("/auth", s.limiter.Middleware(ratelimit.GroupAuth))
+		{
+			auth.POST("/login", controller.Handle(s.authController.Login))
+			auth.POST("/refresh", controller.Handle(s.authController.Refresh))
+		}
+
+		// User routes, registration is public and everything else requires a token.
+		// Tokens are checked before the limiter but only rejected after it, so
+		// requests with forged tokens count against their IP.
+		// Registration honours Idempotency-Key; errors are rendered inside the
+		// idempotency middleware so they are replayed too.
 		users := v1.Group("/users")
+		users.POST("",
+			s.authController.Authenticate,
+			s.limiter.Middleware(ratelimit.GroupRegistration),
+			s.authController.OptionalAuth,
+			s.idempotency.Middleware(),
+			controller.ErrorHandler(),
+			controller.Handle(s.userController.CreateUser),
+		)
+		authenticated := users.Group("", s.authController.Authenticate, s.limiter.Middleware(ratelimit.GroupUsers), s.authController.RequireAuth)
+		{
+			authenticated.GET("", controller.Handle(s.userController.GetAllUsers))
+			authenticated.GET("/:id", controller.Handle(s.userController.GetUser))
+			authenticated.PUT("/:id", controller.Handle(s.userController.UpdateUser))
+			authenticated.PATCH("/:id", controller.Handle(s.userController.PatchUser))
+			authenticated.DELETE("/:id", controller.Handle(s.userController.DeleteUser))
+			authenticated.PUT("/:id/activate", controller.Handle(s.userController.ActivateUser))
+			authenticated.PUT("/:id/deactivate", controller.Handle(s.userController.DeactivateUser))
+		}
+
+		// Webhook routes, managed by admins
+		webhooks := v1.Group("/webhooks", s.authController.Authenticate, s.limiter.Middleware(ratelimit.GroupWebhooks), s.authController.RequireAuth)
 		{
-			users.POST("", s.userController.CreateUser)
-			users.GET("", s.userController.GetAllUsers)
-			users.GET("/:id", s.userController.GetUser)
-			users.PUT("/:id", s.userController.UpdateUser)
-			users.DELETE("/:id", s.userController.DeleteUser)
-			users.PUT("/:id/activate", s.userController.ActivateUser)
-			users.PUT("/:id/deactivate", s.userController.DeactivateUser)
+			webhooks.POST("", controller.Handle(s.webhookController.CreateWebhook))
+			webhooks.GET("", controller.Handle(s.webhookController.GetAllWebhooks))
+			webhooks.GET("/:id", controller.Handle(s.webhookController.GetWebhook))
+			webhooks.PUT("/:id", controller.Handle(s.webhookController.UpdateWebhook))
+			webhooks.DELETE("/:id", controller.Handle(s.webhookController.DeleteWebhook))
+			webhooks.GET("/:id/deliveries", controller.Handle(s.webhookController.GetDeliveries))
 		}
 	}
 
@@ -71,25 +169,22 @@ func (s *Server) setupRoutes() {
 }
 
 // Start starts the HTTP server
-func (s *Server) Start(port string) error {
-	if port == "" {
-		port = getEnv("PORT", "8080")
-	}
-
+func (s *Server) Start() error {
 	s.httpServer = &http.Server{
-		Addr:         ":" + port,
+		Addr:         ":" + s.config.Port,
 		Handler:      s.router,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+		ReadTimeout:  s.config.ReadTimeout,
+		WriteTimeout: s.config.WriteTimeout,
+		IdleTimeout:  s.config.IdleTimeout,
 	}
 
-	log.Printf("Server starting on port %s", port)
+	slog.Info("Server starting", "port", s.config.Port)
 
 	// Start server in a goroutine
 	go func() {
 		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Failed to start server: %v", err)
+			slog.Error("Failed to start server", "error", err)
+			os.Exit(1)
 		}
 	}()
 
@@ -98,35 +193,120 @@ func (s *Server) Start(port string) error {
 
 // Shutdown gracefully shuts down the server
 func (s *Server) Shutdown(ctx context.Context) error {
-	log.Println("Shutting down server...")
+	slog.Info("Shutting down server")
 	return s.httpServer.Shutdown(ctx)
 }
 
-// healthCheck handles health check requests
-func (s *Server) healthCheck(c *gin.Context) {
-	response.Success(c, "Server is healthy", gin.H{
-		"status":    "ok",
-		"timestamp": time.Now().UTC().Format(time.RFC3339),
-		"version":   "1.0.0",
-	})
+// liveness handles GET /health/live, which only reports that the process serves requests
+func (s *Server) liveness(c *gin.Context) {
+	response.Success(c, "Server is alive", s.health.Live())
+}
+
+// readiness handles GET /health/ready and fails with 503 while a critical dependency is down
+func (s *Server) readiness(c *gin.Context) {
+	report := s.health.Ready(c.Request.Context())
+	if !report.Ready() {
+		response.ServiceUnavailable(c, "Server is not ready", report)
+		return
+	}
+	response.Success(c, "Server is ready", report)
 }
 
-// corsMiddleware adds CORS headers
-func corsMiddleware() gin.HandlerFunc {
+// tracingMiddleware continues the trace from an incoming W3C traceparent
+// header, or starts a new one, with a server span around the request
+func tracingMiddleware() gin.HandlerFunc {
+	tracer := otel.Tracer("go-clean-architecture/internal/infrastructure/server")
+
 	return func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization, X-Requested-With")
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
+		// Name spans by route template to keep their cardinality low
+		route := c.FullPath()
+		name := c.Request.Method + " " + route
+		if route == "" {
+			name = c.Request.Method
+		}
+
+		ctx, span := tracer.Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", c.Request.Method),
+				attribute.String("http.route", route),
+				attribute.String("url.path", c.Request.URL.Path),
+				attribute.String("client.address", c.ClientIP()),
+			),
+		)
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
+
+// requestIDMiddleware accepts the client's X-Request-ID or generates one,
+// stores it in the request context and echoes it in the response
+func requestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestid.Header)
+		if !requestid.IsValid(id) {
+			id = requestid.New()
 		}
 
+		c.Request = c.Request.WithContext(requestid.NewContext(c.Request.Context(), id))
+		c.Header(requestid.Header, id)
 		c.Next()
 	}
 }
 
+// sessionMiddleware starts a read-your-writes session per request, so reads
+// that follow a write in the same request are not sent to a lagging replica
+func sessionMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(repository.WithSession(c.Request.Context()))
+		c.Next()
+	}
+}
+
+// requestLogger logs every request once it has been handled
+func requestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		slog.LogAttrs(c.Request.Context(), level, "HTTP request",
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("route", c.FullPath()),
+			slog.Int("status", status),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("client_ip", c.ClientIP()),
+			slog.Int("size", c.Writer.Size()),
+		)
+	}
+}
+
+// recoverPanic logs a recovered panic and answers with a 500
+func recoverPanic(c *gin.Context, recovered any) {
+	slog.ErrorContext(c.Request.Context(), "Panic recovered", "panic", recovered)
+	response.InternalError(c, "Internal server error", nil)
+	c.Abort()
+}
+
 // timeoutMiddleware adds request timeout
 func timeoutMiddleware(timeout time.Duration) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -159,11 +339,3 @@ func timeoutMiddleware(timeout time.Duration) gin.HandlerFunc {
 		}
 	}
 }
-
-// getEnv gets environment variable with fallback
-func getEnv(key, fallback string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return fallback
-}
@@ -0,0 +1,133 @@
+package server
+
+import (
+	"context"
+	"go-clean-architecture/internal/adapter/repository"
+	"go-clean-architecture/internal/entity"
+	"go-clean-architecture/internal/infrastructure/auth"
+	"go-clean-architecture/internal/infrastructure/database"
+	"go-clean-architecture/internal/infrastructure/metrics"
+	"go-clean-architecture/internal/infrastructure/tracing"
+	"go-clean-architecture/internal/usecase"
+	"go-clean-architecture/internal/usecase/interfaces"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracers created at package level only follow the first global provider,
+// so every run records into the same exporter
+var (
+	exporter        = tracetest.NewInMemoryExporter()
+	installProvider sync.Once
+)
+
+func TestTracingSpanTree(t *testing.T) {
+	ctx := context.Background()
+	installProvider.Do(func() {
+		otel.SetTracerProvider(tracing.NewProvider(&tracing.Config{ServiceName: "test"}, sdktrace.WithSyncer(exporter)))
+		otel.SetTextMapPropagator(propagation.TraceContext{})
+	})
+
+	db, err := database.Open(&database.Config{Driver: database.DriverSQLite, DSN: ":memory:"})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		t.Fatalf("NewMigrator() error = %v", err)
+	}
+	if err := migrator.Up(ctx); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	users := repository.NewUserRepository(db, nil)
+	txManager, err := repository.NewTxManager(db, interfaces.TxOptions{})
+	if err != nil {
+		t.Fatalf("NewTxManager() error = %v", err)
+	}
+	userUseCase := usecase.NewUserUseCase(users, repository.NewOutboxRepository(db), txManager, auth.NewBcryptHasher(0), metrics.NewMetrics())
+	alice := &entity.User{Name: "Alice", Email: "alice@example.com"}
+	if err := users.Create(ctx, alice); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(tracingMiddleware())
+	router.GET("/users/:id", func(c *gin.Context) {
+		id, _ := strconv.Atoi(c.Param("id"))
+		ctx := usecase.ContextWithUser(c.Request.Context(), &entity.User{ID: 1000, Role: entity.RoleAdmin})
+		if _, err := userUseCase.GetUser(ctx, uint(id)); err != nil {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	// Only the spans of the request are of interest
+	exporter.Reset()
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	const remoteSpanID = "00f067aa0ba902b7"
+	req := httptest.NewRequest(http.MethodGet, "/users/"+strconv.Itoa(int(alice.ID)), nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-"+remoteSpanID+"-01")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	spans := make(map[string]tracetest.SpanStub)
+	for _, span := range exporter.GetSpans() {
+		if span.SpanContext.TraceID().String() != traceID {
+			t.Errorf("span %q trace ID = %s, want %s", span.Name, span.SpanContext.TraceID(), traceID)
+		}
+		spans[span.Name] = span
+	}
+
+	// Each span is a child of the one before it
+	tree := []struct {
+		name string
+		kind trace.SpanKind
+	}{
+		{name: "GET /users/:id", kind: trace.SpanKindServer},
+		{name: "usecase.get_user", kind: trace.SpanKindInternal},
+		{name: "gorm.query", kind: trace.SpanKindClient},
+	}
+	parent := remoteSpanID
+	for _, node := range tree {
+		span, ok := spans[node.name]
+		if !ok {
+			t.Fatalf("no %q span, got %v", node.name, spanNames(exporter.GetSpans()))
+		}
+		if got := span.Parent.SpanID().String(); got != parent {
+			t.Errorf("%q parent = %s, want %s", node.name, got, parent)
+		}
+		if span.SpanKind != node.kind {
+			t.Errorf("%q kind = %s, want %s", node.name, span.SpanKind, node.kind)
+		}
+		parent = span.SpanContext.SpanID().String()
+	}
+	if !spans["GET /users/:id"].Parent.IsRemote() {
+		t.Error("server span parent is not the remote traceparent")
+	}
+}
+
+// spanNames lists the names of spans for failure messages
+func spanNames(spans tracetest.SpanStubs) []string {
+	names := make([]string, len(spans))
+	for i, span := range spans {
+		names[i] = span.Name
+	}
+	return names
+}
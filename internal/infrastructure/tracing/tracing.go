@@ -0,0 +1,72 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Supported span exporters
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// Config holds tracing configuration
+type Config struct {
+	// Exporter is one of "none", "stdout" or "otlp". The OTLP exporter reads
+	// its endpoint from the standard OTEL_EXPORTER_OTLP_* variables and
+	// defaults to a collector on localhost:4318.
+	Exporter       string
+	ServiceName    string
+	ServiceVersion string
+}
+
+// Setup installs a global tracer provider for the configured exporter and
+// the W3C trace context propagator. The returned function flushes and stops
+// the provider.
+func Setup(ctx context.Context, config *Config) (func(context.Context) error, error) {
+	// Propagate traceparent even when spans are not exported
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch config.Exporter {
+	case ExporterNone, "":
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case ExporterOTLP:
+		exporter, err = otlptracehttp.New(ctx)
+	default:
+		return nil, fmt.Errorf("unsupported OTEL_TRACES_EXPORTER %q", config.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s span exporter: %w", config.Exporter, err)
+	}
+
+	provider := NewProvider(config, sdktrace.WithBatcher(exporter))
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// NewProvider creates a tracer provider describing this service. Tests can
+// pass sdktrace.WithSyncer(tracetest.NewInMemoryExporter()) to assert spans.
+func NewProvider(config *Config, options ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	res := resource.NewSchemaless(
+		semconv.ServiceName(config.ServiceName),
+		semconv.ServiceVersion(config.ServiceVersion),
+	)
+	return sdktrace.NewTracerProvider(append([]sdktrace.TracerProviderOption{sdktrace.WithResource(res)}, options...)...)
+}
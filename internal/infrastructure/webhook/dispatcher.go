@@ -0,0 +1,235 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go-clean-architecture/internal/entity"
+	"go-clean-architecture/internal/usecase/interfaces"
+	"go-clean-architecture/pkg/backoff"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Request headers sent with every delivery
+const (
+	SignatureHeader = "X-Signature"
+	TimestampHeader = "X-Webhook-Timestamp"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+// DefaultTolerance is how old a signed request Verify accepts by default
+const DefaultTolerance = 5 * time.Minute
+
+// Errors returned by Verify
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrStaleTimestamp   = errors.New("webhook timestamp outside the tolerance")
+)
+
+// Config holds webhook dispatcher configuration
+type Config struct {
+	// PollInterval is how long the dispatcher sleeps when nothing is due
+	PollInterval time.Duration
+	// BatchSize bounds the deliveries sent per poll
+	BatchSize int
+	// Timeout bounds a single delivery request
+	Timeout time.Duration
+	// MaxAttempts is the number of attempts before a delivery is dead
+	MaxAttempts int
+	// MinBackoff is the delay after the first failed attempt. It doubles on
+	// every further failure up to MaxBackoff.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// Dispatcher sends queued webhook deliveries as signed HTTP POST requests
+type Dispatcher struct {
+	webhooks   interfaces.WebhookRepository
+	deliveries interfaces.WebhookDeliveryRepository
+	client     *http.Client
+	config     *Config
+}
+
+// NewDispatcher creates a new webhook dispatcher instance.
+// A nil client uses one with the configured timeout that does not follow
+// redirects.
+func NewDispatcher(webhooks interfaces.WebhookRepository, deliveries interfaces.WebhookDeliveryRepository, client *http.Client, config *Config) *Dispatcher {
+	if client == nil {
+		client = &http.Client{
+			Timeout: config.Timeout,
+			// Following a redirect would send the signed payload to a host
+			// that was never validated, so a 3xx counts as a failed attempt
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+	}
+	return &Dispatcher{
+		webhooks:   webhooks,
+		deliveries: deliveries,
+		client:     client,
+		config:     config,
+	}
+}
+
+// Run sends due deliveries until ctx is cancelled
+func (d *Dispatcher) Run(ctx context.Context) {
+	slog.Info("Webhook dispatcher started", "poll_interval", d.config.PollInterval)
+	for {
+		sent, err := d.DispatchOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "Webhook dispatch failed", "error", err)
+		}
+
+		// Keep draining while there is work, otherwise wait for the next poll
+		if sent > 0 && err == nil && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			slog.Info("Webhook dispatcher stopped")
+			return
+		case <-time.After(d.config.PollInterval):
+		}
+	}
+}
+
+// DispatchOnce sends one batch of due deliveries and returns how many were
+// attempted
+func (d *Dispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	// Lease the batch for as long as sending all of it may take, so other
+	// instances do not send the same deliveries meanwhile
+	lease := time.Duration(d.config.BatchSize)*d.config.Timeout + d.config.PollInterval
+	due, err := d.deliveries.Claim(ctx, time.Now(), lease, d.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	webhooks := make(map[uint]*entity.Webhook)
+	for _, delivery := range due {
+		webhook, ok := webhooks[delivery.WebhookID]
+		if !ok {
+			webhook, err = d.webhooks.GetByID(ctx, delivery.WebhookID)
+			if errors.Is(err, entity.ErrWebhookNotFound) {
+				// Deleted together with its deliveries after they were claimed
+				continue
+			}
+			if err != nil {
+				return 0, err
+			}
+			webhooks[delivery.WebhookID] = webhook
+		}
+
+		d.attempt(ctx, webhook, delivery)
+		if err := d.deliveries.Update(ctx, delivery); err != nil {
+			return 0, err
+		}
+	}
+	return len(due), nil
+}
+
+// attempt sends a delivery once and records the outcome on it
+func (d *Dispatcher) attempt(ctx context.Context, webhook *entity.Webhook, delivery *entity.WebhookDelivery) {
+	delivery.Attempts++
+	delivery.ResponseStatus = 0
+
+	var err error
+	if webhook.Active {
+		delivery.ResponseStatus, err = d.send(ctx, webhook, delivery)
+	} else {
+		err = errors.New("webhook is inactive")
+	}
+
+	now := time.Now()
+	switch {
+	case err == nil:
+		delivery.Status = entity.DeliverySucceeded
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+		return
+	case delivery.Attempts >= d.config.MaxAttempts || !webhook.Active:
+		delivery.Status = entity.DeliveryDead
+	default:
+		delivery.NextAttemptAt = now.Add(backoff.Exponential(delivery.Attempts, d.config.MinBackoff, d.config.MaxBackoff))
+	}
+	delivery.LastError = err.Error()
+
+	slog.WarnContext(ctx, "Webhook delivery failed",
+		"webhook_id", webhook.ID,
+		"delivery_id", delivery.ID,
+		"attempt", delivery.Attempts,
+		"status", delivery.Status,
+		"error", err,
+	)
+}
+
+// send POSTs the signed payload and returns the response status.
+// Any status other than 2xx is an error.
+func (d *Dispatcher) send(ctx context.Context, webhook *entity.Webhook, delivery *entity.WebhookDelivery) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.config.Timeout)
+	defer cancel()
+
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	// Every attempt is signed anew, so a retry carries a fresh timestamp
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, timestamp, body))
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, strconv.FormatUint(delivery.ID, 10))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Drain a little of the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign returns the X-Signature header value for a payload sent at the
+// given Unix time: "sha256=" followed by the hex encoded HMAC-SHA256 of
+// the timestamp, a dot and the body. Signing the timestamp keeps a
+// captured request from being replayed once it is too old.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a received delivery the way receivers should: the
+// X-Webhook-Timestamp header must be within tolerance of now and the
+// X-Signature header must match Sign, compared in constant time.
+func Verify(secret, signature, timestamp string, body []byte, tolerance time.Duration, now time.Time) error {
+	sent, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if age := now.Sub(time.Unix(sent, 0)); age > tolerance || age < -tolerance {
+		return ErrStaleTimestamp
+	}
+	if !hmac.Equal([]byte(signature), []byte(Sign(secret, sent, body))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -0,0 +1,365 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"go-clean-architecture/internal/adapter/repository"
+	"go-clean-architecture/internal/entity"
+	"go-clean-architecture/internal/usecase/interfaces"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const (
+	testSecret  = "0123456789abcdef"
+	testPayload = `{"id":7,"type":"user.created","data":{"id":1}}`
+)
+
+// testConfig retries quickly so tests can wait for the backoff
+var testConfig = Config{
+	PollInterval: 10 * time.Millisecond,
+	BatchSize:    10,
+	Timeout:      2 * time.Second,
+	MaxAttempts:  3,
+	MinBackoff:   20 * time.Millisecond,
+	MaxBackoff:   40 * time.Millisecond,
+}
+
+// fixture is a dispatcher sending to a test server with one queued delivery
+type fixture struct {
+	dispatcher *Dispatcher
+	deliveries interfaces.WebhookDeliveryRepository
+	webhook    *entity.Webhook
+	requests   atomic.Int32
+}
+
+// newFixture starts a test server answering with handler and queues a
+// delivery for a webhook pointing at it
+func newFixture(t *testing.T, active bool, handler http.HandlerFunc) *fixture {
+	t.Helper()
+	f := &fixture{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.requests.Add(1)
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	ctx := context.Background()
+	webhooks, deliveries := repository.NewMemoryWebhookRepositories()
+	f.webhook = &entity.Webhook{URL: server.URL + "/hooks", Events: []string{entity.EventAll}, Secret: testSecret, Active: active}
+	if err := webhooks.Create(ctx, f.webhook); err != nil {
+		t.Fatalf("Create() webhook error = %v", err)
+	}
+	err := deliveries.Create(ctx, &entity.WebhookDelivery{
+		WebhookID:     f.webhook.ID,
+		EventID:       7,
+		EventType:     entity.EventUserCreated,
+		Payload:       testPayload,
+		Status:        entity.DeliveryPending,
+		NextAttemptAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("Create() delivery error = %v", err)
+	}
+
+	config := testConfig
+	f.dispatcher = NewDispatcher(webhooks, deliveries, nil, &config)
+	f.deliveries = deliveries
+	return f
+}
+
+// dispatch runs one dispatch and checks how many deliveries were attempted
+func (f *fixture) dispatch(t *testing.T, want int) {
+	t.Helper()
+	sent, err := f.dispatcher.DispatchOnce(context.Background())
+	if err != nil {
+		t.Fatalf("DispatchOnce() error = %v", err)
+	}
+	if sent != want {
+		t.Fatalf("DispatchOnce() sent %d, want %d", sent, want)
+	}
+}
+
+// delivery returns the logged delivery
+func (f *fixture) delivery(t *testing.T) *entity.WebhookDelivery {
+	t.Helper()
+	log, err := f.deliveries.GetByWebhook(context.Background(), f.webhook.ID, 10, 0)
+	if err != nil {
+		t.Fatalf("GetByWebhook() error = %v", err)
+	}
+	if len(log) != 1 {
+		t.Fatalf("delivery log has %d entries, want 1", len(log))
+	}
+	return log[0]
+}
+
+// waitUntilDue sleeps until the delivery's next attempt is due
+func waitUntilDue(delivery *entity.WebhookDelivery) {
+	time.Sleep(time.Until(delivery.NextAttemptAt) + 5*time.Millisecond)
+}
+
+func TestDispatcherSignsRequests(t *testing.T) {
+	var got *http.Request
+	var body []byte
+	f := newFixture(t, true, func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = io.ReadAll(r.Body)
+	})
+
+	f.dispatch(t, 1)
+
+	if got.Method != http.MethodPost || got.URL.Path != "/hooks" {
+		t.Errorf("request = %s %s, want POST /hooks", got.Method, got.URL.Path)
+	}
+	if string(body) != testPayload {
+		t.Errorf("body = %s, want %s", body, testPayload)
+	}
+
+	// The signature covers the timestamp so old requests cannot be replayed
+	timestamp := got.Header.Get(TimestampHeader)
+	sent, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || time.Since(time.Unix(sent, 0)) > time.Minute {
+		t.Fatalf("%s = %q, want the current Unix time", TimestampHeader, timestamp)
+	}
+	mac := hmac.New(sha256.New, []byte(testSecret))
+	mac.Write([]byte(timestamp + "." + testPayload))
+	headers := map[string]string{
+		"Content-Type":  "application/json",
+		SignatureHeader: "sha256=" + hex.EncodeToString(mac.Sum(nil)),
+		EventHeader:     entity.EventUserCreated,
+		DeliveryHeader:  strconv.FormatUint(f.delivery(t).ID, 10),
+	}
+	for name, want := range headers {
+		if value := got.Header.Get(name); value != want {
+			t.Errorf("%s = %q, want %q", name, value, want)
+		}
+	}
+}
+
+func TestDispatcherRetriesWithBackoff(t *testing.T) {
+	f := newFixture(t, true, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	before := time.Now()
+	f.dispatch(t, 1)
+	after := time.Now()
+
+	delivery := f.delivery(t)
+	if delivery.Status != entity.DeliveryPending || delivery.Attempts != 1 || delivery.ResponseStatus != http.StatusServiceUnavailable {
+		t.Fatalf("delivery = %s, %d attempts, response %d, want pending, 1, 503",
+			delivery.Status, delivery.Attempts, delivery.ResponseStatus)
+	}
+	// MinBackoff with up to 20% jitter
+	earliest := before.Add(testConfig.MinBackoff * 8 / 10)
+	latest := after.Add(testConfig.MinBackoff * 12 / 10)
+	if delivery.NextAttemptAt.Before(earliest) || delivery.NextAttemptAt.After(latest) {
+		t.Errorf("next attempt in %s, want about %s", delivery.NextAttemptAt.Sub(before), testConfig.MinBackoff)
+	}
+
+	// Nothing is due before the backoff elapsed
+	f.dispatch(t, 0)
+
+	waitUntilDue(delivery)
+	f.dispatch(t, 1)
+	if got := f.delivery(t).Attempts; got != 2 {
+		t.Errorf("attempts = %d, want 2", got)
+	}
+	if got := f.requests.Load(); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+}
+
+func TestDispatcherMarksDeadAfterMaxAttempts(t *testing.T) {
+	f := newFixture(t, true, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	for attempt := 1; attempt <= testConfig.MaxAttempts; attempt++ {
+		f.dispatch(t, 1)
+		delivery := f.delivery(t)
+		if delivery.Attempts != attempt {
+			t.Fatalf("attempts = %d, want %d", delivery.Attempts, attempt)
+		}
+		if attempt < testConfig.MaxAttempts {
+			waitUntilDue(delivery)
+		}
+	}
+
+	delivery := f.delivery(t)
+	if delivery.Status != entity.DeliveryDead {
+		t.Fatalf("status = %s, want %s", delivery.Status, entity.DeliveryDead)
+	}
+
+	// Dead deliveries are never attempted again
+	time.Sleep(testConfig.MaxBackoff)
+	f.dispatch(t, 0)
+	if got := f.requests.Load(); got != int32(testConfig.MaxAttempts) {
+		t.Errorf("requests = %d, want %d", got, testConfig.MaxAttempts)
+	}
+}
+
+func TestDispatcherDeliveryLog(t *testing.T) {
+	redirected := atomic.Int32{}
+	elsewhere := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		redirected.Add(1)
+	}))
+	t.Cleanup(elsewhere.Close)
+
+	tests := []struct {
+		name         string
+		active       bool
+		handler      http.HandlerFunc
+		wantStatus   string
+		wantResponse int
+		wantError    string
+		wantRequests int32
+	}{
+		{
+			name:         "success",
+			active:       true,
+			handler:      func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) },
+			wantStatus:   entity.DeliverySucceeded,
+			wantResponse: http.StatusNoContent,
+			wantRequests: 1,
+		},
+		{
+			name:         "client error",
+			active:       true,
+			handler:      func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusGone) },
+			wantStatus:   entity.DeliveryPending,
+			wantResponse: http.StatusGone,
+			wantError:    "unexpected response status 410",
+			wantRequests: 1,
+		},
+		{
+			name:   "redirect is not followed",
+			active: true,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Redirect(w, r, elsewhere.URL, http.StatusTemporaryRedirect)
+			},
+			wantStatus:   entity.DeliveryPending,
+			wantResponse: http.StatusTemporaryRedirect,
+			wantError:    "unexpected response status 307",
+			wantRequests: 1,
+		},
+		{
+			name:       "inactive webhook",
+			handler:    func(w http.ResponseWriter, r *http.Request) {},
+			wantStatus: entity.DeliveryDead,
+			wantError:  "webhook is inactive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t, tt.active, tt.handler)
+			f.dispatch(t, 1)
+
+			delivery := f.delivery(t)
+			if delivery.Status != tt.wantStatus || delivery.ResponseStatus != tt.wantResponse {
+				t.Errorf("delivery = %s, response %d, want %s, response %d",
+					delivery.Status, delivery.ResponseStatus, tt.wantStatus, tt.wantResponse)
+			}
+			if !strings.Contains(delivery.LastError, tt.wantError) || (tt.wantError == "") != (delivery.LastError == "") {
+				t.Errorf("last error = %q, want %q", delivery.LastError, tt.wantError)
+			}
+			if (tt.wantStatus == entity.DeliverySucceeded) != (delivery.DeliveredAt != nil) {
+				t.Errorf("delivered at = %v for status %s", delivery.DeliveredAt, delivery.Status)
+			}
+			if delivery.Attempts != 1 || delivery.Payload != testPayload || delivery.EventType != entity.EventUserCreated {
+				t.Errorf("delivery = %d attempts, %s %s", delivery.Attempts, delivery.EventType, delivery.Payload)
+			}
+			if got := f.requests.Load(); got != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+	if got := redirected.Load(); got != 0 {
+		t.Errorf("redirect target received %d requests, want 0", got)
+	}
+}
+
+func TestDispatcherClaimsOnce(t *testing.T) {
+	release := make(chan struct{})
+	f := newFixture(t, true, func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	})
+
+	// A second dispatcher polling while the first is sending skips the delivery
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f.dispatcher.DispatchOnce(context.Background())
+	}()
+	for f.requests.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	f.dispatch(t, 0)
+	close(release)
+	<-done
+
+	if got := f.delivery(t).Status; got != entity.DeliverySucceeded {
+		t.Errorf("status = %s, want %s", got, entity.DeliverySucceeded)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(testPayload)
+	signature := Sign(testSecret, now.Unix(), body)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(testSecret))
+	mac.Write(body)
+	bodyOnly := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	tests := []struct {
+		name      string
+		secret    string
+		signature string
+		timestamp string
+		body      string
+		now       time.Time
+		wantErr   error
+	}{
+		{name: "valid", now: now},
+		{name: "within tolerance", now: now.Add(DefaultTolerance)},
+		{name: "replayed later", now: now.Add(DefaultTolerance + time.Second), wantErr: ErrStaleTimestamp},
+		{name: "from the future", now: now.Add(-DefaultTolerance - time.Second), wantErr: ErrStaleTimestamp},
+		{name: "other timestamp", timestamp: strconv.FormatInt(now.Unix()+1, 10), now: now, wantErr: ErrInvalidSignature},
+		{name: "malformed timestamp", timestamp: "yesterday", now: now, wantErr: ErrInvalidSignature},
+		{name: "tampered body", body: `{"id":8}`, now: now, wantErr: ErrInvalidSignature},
+		{name: "other secret", secret: "fedcba9876543210", now: now, wantErr: ErrInvalidSignature},
+		{name: "body only signature", signature: bodyOnly, now: now, wantErr: ErrInvalidSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret, sig, ts, b := testSecret, signature, timestamp, body
+			if tt.secret != "" {
+				secret = tt.secret
+			}
+			if tt.signature != "" {
+				sig = tt.signature
+			}
+			if tt.timestamp != "" {
+				ts = tt.timestamp
+			}
+			if tt.body != "" {
+				b = []byte(tt.body)
+			}
+			if err := Verify(secret, sig, ts, b, DefaultTolerance, tt.now); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
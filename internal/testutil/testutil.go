@@ -0,0 +1,35 @@
+// Package testutil holds helpers shared by tests of several packages
+package testutil
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// Backend is one implementation of T a test suite runs against
+type Backend[T any] struct {
+	Name string
+	// New returns a fresh instance cleaned up with the test
+	New func(t *testing.T) T
+}
+
+// ForEachBackend runs test as a subtest against every backend
+func ForEachBackend[T any](t *testing.T, backends []Backend[T], test func(t *testing.T, backend Backend[T])) {
+	for _, backend := range backends {
+		t.Run(backend.Name, func(t *testing.T) {
+			test(t, backend)
+		})
+	}
+}
+
+// NewRedis starts a miniredis server standing in for Redis and returns a
+// client connected to it. Both are closed when the test ends.
+func NewRedis(t *testing.T) (*redis.Client, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return client, server
+}
@@ -0,0 +1,140 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"go-clean-architecture/internal/entity"
+	"go-clean-architecture/internal/usecase/interfaces"
+	"sync"
+	"time"
+)
+
+// dummyPassword is hashed once to give failed lookups a hash to compare against
+const dummyPassword = "dummy password for failed logins"
+
+// TokenPair holds the tokens returned by login and refresh
+type TokenPair struct {
+	AccessToken      string
+	AccessExpiresAt  time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+}
+
+// AuthUseCase implements business logic for authentication
+type AuthUseCase struct {
+	userRepo interfaces.UserRepository
+	hasher   interfaces.PasswordHasher
+	tokens   interfaces.TokenService
+
+	// dummyHash is compared against when there is no usable hash, so that
+	// unknown emails take as long to reject as wrong passwords
+	dummyHash     string
+	dummyHashOnce sync.Once
+}
+
+// NewAuthUseCase creates a new auth use case instance
+func NewAuthUseCase(userRepo interfaces.UserRepository, hasher interfaces.PasswordHasher, tokens interfaces.TokenService) *AuthUseCase {
+	return &AuthUseCase{
+		userRepo: userRepo,
+		hasher:   hasher,
+		tokens:   tokens,
+	}
+}
+
+// Login verifies credentials and issues a token pair
+func (uc *AuthUseCase) Login(ctx context.Context, email, password string) (*TokenPair, error) {
+	if email == "" || password == "" {
+		return nil, entity.ErrInvalidCredentials
+	}
+
+	user, err := uc.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, entity.ErrUserNotFound) {
+			uc.compareDummy(password)
+			return nil, entity.ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	// Inactive users and users without a password cannot log in
+	if !user.Active || !user.HasPassword() {
+		uc.compareDummy(password)
+		return nil, entity.ErrInvalidCredentials
+	}
+	if err := uc.hasher.Compare(user.PasswordHash, password); err != nil {
+		if errors.Is(err, entity.ErrInvalidCredentials) {
+			return nil, entity.ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	return uc.issueTokens(user)
+}
+
+// compareDummy spends the time of a password check whose result is ignored
+func (uc *AuthUseCase) compareDummy(password string) {
+	uc.dummyHashOnce.Do(func() {
+		uc.dummyHash, _ = uc.hasher.Hash(dummyPassword)
+	})
+	_ = uc.hasher.Compare(uc.dummyHash, password)
+}
+
+// Refresh exchanges a valid refresh token for a new token pair
+func (uc *AuthUseCase) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	claims, err := uc.tokens.ParseRefreshToken(refreshToken)
+	if err != nil {
+		return nil, entity.ErrInvalidToken
+	}
+
+	user, err := uc.activeUser(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.issueTokens(user)
+}
+
+// Authenticate resolves an access token to the user it was issued to
+func (uc *AuthUseCase) Authenticate(ctx context.Context, accessToken string) (*entity.User, error) {
+	claims, err := uc.tokens.ParseAccessToken(accessToken)
+	if err != nil {
+		return nil, entity.ErrInvalidToken
+	}
+
+	return uc.activeUser(ctx, claims.UserID)
+}
+
+// activeUser loads a token subject, rejecting deleted or deactivated users
+func (uc *AuthUseCase) activeUser(ctx context.Context, id uint) (*entity.User, error) {
+	user, err := uc.userRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, entity.ErrUserNotFound) {
+			return nil, entity.ErrInvalidToken
+		}
+		return nil, err
+	}
+	if !user.Active {
+		return nil, entity.ErrInvalidToken
+	}
+	return user, nil
+}
+
+// issueTokens creates a fresh access and refresh token for a user
+func (uc *AuthUseCase) issueTokens(user *entity.User) (*TokenPair, error) {
+	accessToken, accessExpiresAt, err := uc.tokens.IssueAccessToken(user)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, refreshExpiresAt, err := uc.tokens.IssueRefreshToken(user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:      accessToken,
+		AccessExpiresAt:  accessExpiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: refreshExpiresAt,
+	}, nil
+}
@@ -0,0 +1,191 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"go-clean-architecture/internal/adapter/repository"
+	"go-clean-architecture/internal/entity"
+	"go-clean-architecture/internal/infrastructure/auth"
+	"go-clean-architecture/internal/usecase"
+	"go-clean-architecture/internal/usecase/interfaces"
+	"testing"
+	"time"
+)
+
+// countingHasher counts the password comparisons of plainHasher
+type countingHasher struct {
+	plainHasher
+	compares int
+}
+
+func (h *countingHasher) Compare(hash, password string) error {
+	h.compares++
+	return h.plainHasher.Compare(hash, password)
+}
+
+// authFixture is an auth use case over an in-memory repository holding an
+// active user, an inactive user and a user without a password
+type authFixture struct {
+	uc       *usecase.AuthUseCase
+	users    interfaces.UserRepository
+	hasher   *countingHasher
+	tokens   interfaces.TokenService
+	active   *entity.User
+	inactive *entity.User
+}
+
+// newAuthFixture creates the fixture, signing tokens with HS256
+func newAuthFixture(t *testing.T) *authFixture {
+	t.Helper()
+	tokens, err := auth.NewJWTService(&auth.Config{
+		Algorithm:  auth.AlgorithmHS256,
+		Secret:     "0123456789abcdef0123456789abcdef",
+		Issuer:     "test",
+		AccessTTL:  time.Minute,
+		RefreshTTL: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("NewJWTService() error = %v", err)
+	}
+
+	f := &authFixture{
+		users:  repository.NewMemoryUserRepository(),
+		hasher: &countingHasher{},
+		tokens: tokens,
+	}
+	f.uc = usecase.NewAuthUseCase(f.users, f.hasher, tokens)
+	f.active = f.createUser(t, "active@example.com", "secret", true)
+	f.inactive = f.createUser(t, "inactive@example.com", "secret", false)
+	f.createUser(t, "nopassword@example.com", "", true)
+	return f
+}
+
+// createUser stores a user with the given password, empty for none
+func (f *authFixture) createUser(t *testing.T, email, password string, active bool) *entity.User {
+	t.Helper()
+	user := &entity.User{Name: email, Email: email}
+	if password != "" {
+		user.PasswordHash, _ = f.hasher.Hash(password)
+	}
+	if err := f.users.Create(context.Background(), user); err != nil {
+		t.Fatalf("Create(%s) error = %v", email, err)
+	}
+	if !active {
+		user.Active = false
+		if err := f.users.Update(context.Background(), user); err != nil {
+			t.Fatalf("Update(%s) error = %v", email, err)
+		}
+	}
+	return user
+}
+
+// deactivate deactivates a stored user
+func (f *authFixture) deactivate(t *testing.T, id uint) {
+	t.Helper()
+	user, err := f.users.GetByID(context.Background(), id)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	user.Active = false
+	if err := f.users.Update(context.Background(), user); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+}
+
+func TestAuthUseCaseLogin(t *testing.T) {
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		wantErr  error
+	}{
+		{name: "valid credentials", email: "active@example.com", password: "secret"},
+		{name: "wrong password", email: "active@example.com", password: "wrong", wantErr: entity.ErrInvalidCredentials},
+		{name: "unknown email", email: "unknown@example.com", password: "secret", wantErr: entity.ErrInvalidCredentials},
+		{name: "inactive user", email: "inactive@example.com", password: "secret", wantErr: entity.ErrInvalidCredentials},
+		{name: "user without password", email: "nopassword@example.com", password: "secret", wantErr: entity.ErrInvalidCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAuthFixture(t)
+
+			tokens, err := f.uc.Login(context.Background(), tt.email, tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Login() error = %v, want %v", err, tt.wantErr)
+			}
+			// Every outcome costs one password comparison, so response
+			// times do not reveal which emails exist
+			if f.hasher.compares != 1 {
+				t.Errorf("Login() compared %d passwords, want 1", f.hasher.compares)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			claims, err := f.tokens.ParseAccessToken(tokens.AccessToken)
+			if err != nil || claims.UserID != f.active.ID {
+				t.Errorf("ParseAccessToken() = %+v, %v, want user %d", claims, err, f.active.ID)
+			}
+		})
+	}
+}
+
+func TestAuthUseCaseRefresh(t *testing.T) {
+	f := newAuthFixture(t)
+	tokens, err := f.uc.Login(context.Background(), "active@example.com", "secret")
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+
+	refreshed, err := f.uc.Refresh(context.Background(), tokens.RefreshToken)
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if _, err := f.uc.Authenticate(context.Background(), refreshed.AccessToken); err != nil {
+		t.Errorf("Authenticate(refreshed) error = %v", err)
+	}
+
+	if _, err := f.uc.Refresh(context.Background(), tokens.AccessToken); !errors.Is(err, entity.ErrInvalidToken) {
+		t.Errorf("Refresh(access token) error = %v, want %v", err, entity.ErrInvalidToken)
+	}
+
+	f.deactivate(t, f.active.ID)
+	if _, err := f.uc.Refresh(context.Background(), tokens.RefreshToken); !errors.Is(err, entity.ErrInvalidToken) {
+		t.Errorf("Refresh() for a deactivated user error = %v, want %v", err, entity.ErrInvalidToken)
+	}
+}
+
+func TestAuthUseCaseAuthenticate(t *testing.T) {
+	f := newAuthFixture(t)
+	access, _, err := f.tokens.IssueAccessToken(f.active)
+	if err != nil {
+		t.Fatalf("IssueAccessToken() error = %v", err)
+	}
+	refresh, _, err := f.tokens.IssueRefreshToken(f.active)
+	if err != nil {
+		t.Fatalf("IssueRefreshToken() error = %v", err)
+	}
+	inactive, _, err := f.tokens.IssueAccessToken(f.inactive)
+	if err != nil {
+		t.Fatalf("IssueAccessToken() error = %v", err)
+	}
+	deleted, _, err := f.tokens.IssueAccessToken(&entity.User{ID: 999})
+	if err != nil {
+		t.Fatalf("IssueAccessToken() error = %v", err)
+	}
+
+	user, err := f.uc.Authenticate(context.Background(), access)
+	if err != nil || user.ID != f.active.ID {
+		t.Errorf("Authenticate() = %v, %v, want user %d", user, err, f.active.ID)
+	}
+	for name, token := range map[string]string{
+		"refresh token":    refresh,
+		"deactivated user": inactive,
+		"deleted user":     deleted,
+	} {
+		if _, err := f.uc.Authenticate(context.Background(), token); !errors.Is(err, entity.ErrInvalidToken) {
+			t.Errorf("Authenticate(%s) error = %v, want %v", name, err, entity.ErrInvalidToken)
+		}
+	}
+}
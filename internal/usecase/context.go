@@ -0,0 +1,20 @@
+package usecase
+
+import (
+	"context"
+	"go-clean-architecture/internal/entity"
+)
+
+// currentUserKey is the context key for the authenticated user
+type currentUserKey struct{}
+
+// ContextWithUser returns a copy of ctx carrying the authenticated user
+func ContextWithUser(ctx context.Context, user *entity.User) context.Context {
+	return context.WithValue(ctx, currentUserKey{}, user)
+}
+
+// UserFromContext returns the authenticated user, if any
+func UserFromContext(ctx context.Context) (*entity.User, bool) {
+	user, ok := ctx.Value(currentUserKey{}).(*entity.User)
+	return user, ok && user != nil
+}
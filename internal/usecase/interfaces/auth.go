@@ -0,0 +1,29 @@
+package interfaces
+
+import (
+	"go-clean-architecture/internal/entity"
+	"time"
+)
+
+// PasswordHasher defines the contract for one-way password hashing
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	// Compare returns nil only when the password matches the hash
+	Compare(hash, password string) error
+}
+
+// TokenClaims identifies the user a token was issued to
+type TokenClaims struct {
+	UserID    uint
+	ExpiresAt time.Time
+}
+
+// TokenService defines the contract for issuing and verifying auth tokens.
+// Parse methods return entity.ErrInvalidToken for any malformed, expired or
+// wrongly typed token.
+type TokenService interface {
+	IssueAccessToken(user *entity.User) (string, time.Time, error)
+	IssueRefreshToken(user *entity.User) (string, time.Time, error)
+	ParseAccessToken(token string) (*TokenClaims, error)
+	ParseRefreshToken(token string) (*TokenClaims, error)
+}
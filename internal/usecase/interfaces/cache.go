@@ -0,0 +1,16 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+)
+
+// Cache defines the contract for a byte oriented key/value cache
+type Cache interface {
+	// Get returns the value and true on a hit, or false on a miss
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores a value that expires after ttl
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes the keys, ignoring ones that do not exist
+	Delete(ctx context.Context, keys ...string) error
+}
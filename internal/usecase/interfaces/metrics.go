@@ -0,0 +1,7 @@
+package interfaces
+
+// OperationMetrics records the outcome of use case operations
+type OperationMetrics interface {
+	// CountOperation increments the counter for an operation and its outcome
+	CountOperation(operation, outcome string)
+}
@@ -0,0 +1,39 @@
+package interfaces
+
+import (
+	"context"
+	"go-clean-architecture/internal/entity"
+	"time"
+)
+
+// OutboxMessage is a recorded domain event waiting to be published
+type OutboxMessage struct {
+	ID          uint64
+	EventType   string
+	AggregateID uint
+	// Payload is the JSON encoded event
+	Payload    []byte
+	OccurredAt time.Time
+	// Attempts counts the failed publish attempts so far
+	Attempts int
+}
+
+// OutboxRepository defines the contract for the transactional outbox.
+// Events added with a ctx from TxManager.WithinTx are only stored when the
+// transaction commits.
+type OutboxRepository interface {
+	Add(ctx context.Context, events ...entity.Event) error
+	// Pending returns up to limit messages due at now. Only the oldest
+	// unpublished message of each aggregate is returned, so a message is
+	// never published before an earlier one of the same aggregate.
+	Pending(ctx context.Context, now time.Time, limit int) ([]*OutboxMessage, error)
+	MarkPublished(ctx context.Context, id uint64) error
+	// MarkFailed records a failed attempt and when to try again
+	MarkFailed(ctx context.Context, id uint64, next time.Time, reason string) error
+}
+
+// EventPublisher defines the contract for delivering outbox messages.
+// Delivery is at least once, so consumers should deduplicate by message ID.
+type EventPublisher interface {
+	Publish(ctx context.Context, message *OutboxMessage) error
+}
@@ -0,0 +1,32 @@
+package interfaces
+
+import "context"
+
+// IsolationLevel is the transaction isolation level. The empty level uses
+// the database default.
+type IsolationLevel string
+
+// Supported isolation levels
+const (
+	IsolationDefault        IsolationLevel = ""
+	IsolationReadCommitted  IsolationLevel = "read_committed"
+	IsolationRepeatableRead IsolationLevel = "repeatable_read"
+	IsolationSerializable   IsolationLevel = "serializable"
+)
+
+// TxOptions configures the transactions started by a TxManager
+type TxOptions struct {
+	Isolation IsolationLevel
+	// MaxAttempts bounds how often a transaction is run when it fails with a
+	// serialization failure or deadlock. Values below 1 mean a single attempt.
+	MaxAttempts int
+}
+
+// TxManager defines the contract for running a unit of work atomically
+type TxManager interface {
+	// WithinTx runs fn inside a transaction that commits when fn returns nil
+	// and rolls back otherwise. Repositories called with the ctx passed to fn
+	// take part in the transaction. fn may run more than once when the
+	// transaction is retried, and nested calls join the outer transaction.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
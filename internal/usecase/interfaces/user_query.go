@@ -0,0 +1,57 @@
+package interfaces
+
+import "time"
+
+// Sortable user columns
+const (
+	UserSortID        = "id"
+	UserSortName      = "name"
+	UserSortEmail     = "email"
+	UserSortCreatedAt = "created_at"
+	UserSortUpdatedAt = "updated_at"
+)
+
+// UserFilter narrows the set of users returned by a listing.
+// Zero values mean "no constraint".
+type UserFilter struct {
+	Active        *bool
+	EmailDomain   string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	Search        string
+}
+
+// SortField orders a listing by a single column
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// UserQuery combines filtering and ordering for user listings
+type UserQuery struct {
+	Filter UserFilter
+	Sort   []SortField
+}
+
+// IsSortableUserField reports whether a column can be used for ordering
+func IsSortableUserField(field string) bool {
+	switch field {
+	case UserSortID, UserSortName, UserSortEmail, UserSortCreatedAt, UserSortUpdatedAt:
+		return true
+	}
+	return false
+}
+
+// UserCursor identifies a row in the keyset ordering created_at DESC, id DESC
+type UserCursor struct {
+	CreatedAt time.Time
+	ID        uint
+}
+
+// CursorPage requests up to Limit rows adjacent to a cursor.
+// A nil Cursor starts from the newest user.
+type CursorPage struct {
+	Cursor   *UserCursor
+	Backward bool
+	Limit    int
+}
@@ -5,13 +5,17 @@ import (
 	"go-clean-architecture/internal/entity"
 )
 
-// UserRepository defines the contract for user data access
+// UserRepository defines the contract for user data access.
+// Writes are conditional on the version the caller last read and fail with
+// entity.ErrVersionConflict when the stored user has moved on.
 type UserRepository interface {
 	Create(ctx context.Context, user *entity.User) error
 	GetByID(ctx context.Context, id uint) (*entity.User, error)
 	GetByEmail(ctx context.Context, email string) (*entity.User, error)
-	GetAll(ctx context.Context, limit, offset int) ([]*entity.User, error)
+	GetAll(ctx context.Context, query UserQuery, limit, offset int) ([]*entity.User, error)
+	GetAllByCursor(ctx context.Context, filter UserFilter, page CursorPage) ([]*entity.User, error)
 	Update(ctx context.Context, user *entity.User) error
-	Delete(ctx context.Context, id uint) error
-	Count(ctx context.Context) (int64, error)
+	Patch(ctx context.Context, id, version uint, patch *entity.UserPatch) error
+	Delete(ctx context.Context, id, version uint) error
+	Count(ctx context.Context, filter UserFilter) (int64, error)
 }
@@ -0,0 +1,31 @@
+package interfaces
+
+import (
+	"context"
+	"go-clean-architecture/internal/entity"
+	"time"
+)
+
+// WebhookRepository defines the contract for webhook subscription storage
+type WebhookRepository interface {
+	Create(ctx context.Context, webhook *entity.Webhook) error
+	GetByID(ctx context.Context, id uint) (*entity.Webhook, error)
+	GetAll(ctx context.Context) ([]*entity.Webhook, error)
+	Update(ctx context.Context, webhook *entity.Webhook) error
+	// Delete removes a webhook together with its delivery log
+	Delete(ctx context.Context, id uint) error
+}
+
+// WebhookDeliveryRepository defines the contract for the webhook delivery log
+type WebhookDeliveryRepository interface {
+	Create(ctx context.Context, deliveries ...*entity.WebhookDelivery) error
+	// Claim returns up to limit pending deliveries whose next attempt is at
+	// or before now, oldest first, and pushes their next attempt back by
+	// lease so other dispatchers skip them. A dispatcher that dies before
+	// recording the outcome leaves them to be claimed again after the lease.
+	Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*entity.WebhookDelivery, error)
+	Update(ctx context.Context, delivery *entity.WebhookDelivery) error
+	// GetByWebhook returns the deliveries of a webhook, newest first
+	GetByWebhook(ctx context.Context, webhookID uint, limit, offset int) ([]*entity.WebhookDelivery, error)
+	CountByWebhook(ctx context.Context, webhookID uint) (int64, error)
+}
@@ -0,0 +1,38 @@
+package usecase
+
+import (
+	"context"
+	"go-clean-architecture/internal/entity"
+	"go-clean-architecture/internal/usecase/interfaces"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// tracer creates the spans of use case operations
+var tracer = otel.Tracer("go-clean-architecture/internal/usecase")
+
+// startOperation opens a span for an operation. The returned function ends
+// it and counts the operation by the outcome of its error.
+func startOperation(ctx context.Context, metrics interfaces.OperationMetrics, operation string) (context.Context, func(err *error)) {
+	ctx, span := tracer.Start(ctx, "usecase."+operation)
+	return ctx, func(err *error) {
+		result := outcome(*err)
+		span.SetAttributes(attribute.String("usecase.outcome", result))
+		if *err != nil && entity.KindOf(*err) == entity.KindInternal {
+			span.RecordError(*err)
+			span.SetStatus(codes.Error, (*err).Error())
+		}
+		span.End()
+		metrics.CountOperation(operation, result)
+	}
+}
+
+// outcome classifies an operation error for metrics by its domain error kind
+func outcome(err error) string {
+	if err == nil {
+		return "success"
+	}
+	return entity.KindOf(err).String()
+}
@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"go-clean-architecture/internal/entity"
+)
+
+// UserAction is an operation guarded by the user policy
+type UserAction string
+
+// User actions
+const (
+	ActionCreateUser     UserAction = "create"
+	ActionReadUser       UserAction = "read"
+	ActionListUsers      UserAction = "list"
+	ActionUpdateUser     UserAction = "update"
+	ActionDeleteUser     UserAction = "delete"
+	ActionActivateUser   UserAction = "activate"
+	ActionDeactivateUser UserAction = "deactivate"
+	ActionAssignRole     UserAction = "assign_role"
+)
+
+// UserPolicy decides which user operations an actor may perform:
+//   - admins may do anything
+//   - operators may read and list everyone, and activate or deactivate
+//     anyone but admins
+//   - everyone else may only read and update their own record
+//
+// Only admins may assign roles other than self, and anonymous callers may
+// only register.
+type UserPolicy struct{}
+
+// NewUserPolicy creates the default user policy
+func NewUserPolicy() *UserPolicy {
+	return &UserPolicy{}
+}
+
+// Authorize returns entity.ErrForbidden unless actor may perform action on
+// target. target is nil for collection level actions such as listing.
+func (p *UserPolicy) Authorize(actor *entity.User, action UserAction, target *entity.User) error {
+	if p.allowed(actor, action, target) {
+		return nil
+	}
+	return entity.ErrForbidden
+}
+
+// allowed evaluates the policy rules
+func (p *UserPolicy) allowed(actor *entity.User, action UserAction, target *entity.User) bool {
+	if actor == nil {
+		// Anonymous self registration
+		return action == ActionCreateUser && target != nil && target.HasRole(entity.RoleSelf)
+	}
+
+	if actor.HasRole(entity.RoleAdmin) {
+		return true
+	}
+
+	isSelf := target != nil && target.ID == actor.ID
+
+	switch action {
+	case ActionCreateUser:
+		return target != nil && target.HasRole(entity.RoleSelf)
+	case ActionReadUser:
+		return isSelf || actor.HasRole(entity.RoleOperator)
+	case ActionListUsers:
+		return actor.HasRole(entity.RoleOperator)
+	case ActionUpdateUser:
+		return isSelf
+	case ActionActivateUser, ActionDeactivateUser:
+		return actor.HasRole(entity.RoleOperator) && target != nil && !target.HasRole(entity.RoleAdmin)
+	}
+	return false
+}
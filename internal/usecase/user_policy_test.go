@@ -0,0 +1,186 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"go-clean-architecture/internal/entity"
+	"go-clean-architecture/internal/usecase"
+	"testing"
+)
+
+func TestUserPolicyMatrix(t *testing.T) {
+	actors := map[string]*entity.User{
+		"admin":     {ID: 1, Role: entity.RoleAdmin},
+		"operator":  {ID: 2, Role: entity.RoleOperator},
+		"self":      {ID: 3, Role: entity.RoleSelf},
+		"anonymous": nil,
+	}
+	// target returns the user an action applies to, "own" being the actor
+	target := func(kind string, actor *entity.User) *entity.User {
+		switch kind {
+		case "own":
+			if actor == nil {
+				return &entity.User{ID: 99, Role: entity.RoleSelf}
+			}
+			return actor
+		case "none":
+			return nil
+		}
+		return &entity.User{ID: 10, Role: kind}
+	}
+
+	// Each row lists whether admin, operator, self and anonymous are allowed
+	tests := []struct {
+		action                           usecase.UserAction
+		target                           string
+		admin, operator, self, anonymous bool
+	}{
+		{action: usecase.ActionCreateUser, target: entity.RoleSelf, admin: true, operator: true, self: true, anonymous: true},
+		{action: usecase.ActionCreateUser, target: entity.RoleOperator, admin: true},
+		{action: usecase.ActionCreateUser, target: entity.RoleAdmin, admin: true},
+		{action: usecase.ActionReadUser, target: "own", admin: true, operator: true, self: true},
+		{action: usecase.ActionReadUser, target: entity.RoleSelf, admin: true, operator: true},
+		{action: usecase.ActionReadUser, target: entity.RoleAdmin, admin: true, operator: true},
+		{action: usecase.ActionListUsers, target: "none", admin: true, operator: true},
+		{action: usecase.ActionUpdateUser, target: "own", admin: true, operator: true, self: true},
+		{action: usecase.ActionUpdateUser, target: entity.RoleSelf, admin: true},
+		{action: usecase.ActionUpdateUser, target: entity.RoleAdmin, admin: true},
+		{action: usecase.ActionDeleteUser, target: "own", admin: true},
+		{action: usecase.ActionDeleteUser, target: entity.RoleSelf, admin: true},
+		{action: usecase.ActionActivateUser, target: entity.RoleSelf, admin: true, operator: true},
+		{action: usecase.ActionActivateUser, target: entity.RoleOperator, admin: true, operator: true},
+		{action: usecase.ActionActivateUser, target: entity.RoleAdmin, admin: true},
+		{action: usecase.ActionDeactivateUser, target: "own", admin: true, operator: true},
+		{action: usecase.ActionDeactivateUser, target: entity.RoleSelf, admin: true, operator: true},
+		{action: usecase.ActionDeactivateUser, target: entity.RoleAdmin, admin: true},
+		{action: usecase.ActionAssignRole, target: "own", admin: true},
+		{action: usecase.ActionAssignRole, target: entity.RoleSelf, admin: true},
+	}
+
+	policy := usecase.NewUserPolicy()
+	for _, tt := range tests {
+		want := map[string]bool{"admin": tt.admin, "operator": tt.operator, "self": tt.self, "anonymous": tt.anonymous}
+		for name, actor := range actors {
+			t.Run(name+" "+string(tt.action)+" "+tt.target, func(t *testing.T) {
+				err := policy.Authorize(actor, tt.action, target(tt.target, actor))
+				if want[name] && err != nil {
+					t.Errorf("Authorize() error = %v, want allowed", err)
+				}
+				if !want[name] && !errors.Is(err, entity.ErrForbidden) {
+					t.Errorf("Authorize() error = %v, want %v", err, entity.ErrForbidden)
+				}
+			})
+		}
+	}
+}
+
+func TestUserUseCaseEscalation(t *testing.T) {
+	tests := []struct {
+		name    string
+		actor   string
+		target  string
+		change  func(uc *usecase.UserUseCase, ctx context.Context, id uint) error
+		wantErr error
+	}{
+		{
+			name:  "operator deactivates admin",
+			actor: entity.RoleOperator, target: entity.RoleAdmin,
+			change: func(uc *usecase.UserUseCase, ctx context.Context, id uint) error {
+				return uc.DeactivateUser(ctx, id, 0)
+			},
+			wantErr: entity.ErrForbidden,
+		},
+		{
+			name:  "operator deactivates admin through PATCH",
+			actor: entity.RoleOperator, target: entity.RoleAdmin,
+			change: func(uc *usecase.UserUseCase, ctx context.Context, id uint) error {
+				_, err := uc.PatchUser(ctx, id, &entity.UserPatch{Active: ptr(false)}, 0)
+				return err
+			},
+			wantErr: entity.ErrForbidden,
+		},
+		{
+			name:  "operator deactivates self user",
+			actor: entity.RoleOperator, target: entity.RoleSelf,
+			change: func(uc *usecase.UserUseCase, ctx context.Context, id uint) error {
+				return uc.DeactivateUser(ctx, id, 0)
+			},
+		},
+		{
+			name:  "self changes own role through PUT",
+			actor: entity.RoleSelf, target: "own",
+			change: func(uc *usecase.UserUseCase, ctx context.Context, id uint) error {
+				return uc.UpdateUser(ctx, id, &entity.User{Name: "Actor", Email: "actor@example.com", Role: entity.RoleAdmin}, 0)
+			},
+			wantErr: entity.ErrForbidden,
+		},
+		{
+			name:  "self changes own role through PATCH",
+			actor: entity.RoleSelf, target: "own",
+			change: func(uc *usecase.UserUseCase, ctx context.Context, id uint) error {
+				_, err := uc.PatchUser(ctx, id, &entity.UserPatch{Role: ptr(entity.RoleOperator)}, 0)
+				return err
+			},
+			wantErr: entity.ErrForbidden,
+		},
+		{
+			name:  "self deactivates itself through PATCH",
+			actor: entity.RoleSelf, target: "own",
+			change: func(uc *usecase.UserUseCase, ctx context.Context, id uint) error {
+				_, err := uc.PatchUser(ctx, id, &entity.UserPatch{Active: ptr(false)}, 0)
+				return err
+			},
+			wantErr: entity.ErrForbidden,
+		},
+		{
+			name:  "operator changes own role through PATCH",
+			actor: entity.RoleOperator, target: "own",
+			change: func(uc *usecase.UserUseCase, ctx context.Context, id uint) error {
+				_, err := uc.PatchUser(ctx, id, &entity.UserPatch{Role: ptr(entity.RoleAdmin)}, 0)
+				return err
+			},
+			wantErr: entity.ErrForbidden,
+		},
+		{
+			name:  "self updates own name through PUT",
+			actor: entity.RoleSelf, target: "own",
+			change: func(uc *usecase.UserUseCase, ctx context.Context, id uint) error {
+				return uc.UpdateUser(ctx, id, &entity.User{Name: "Renamed", Email: "actor@example.com"}, 0)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, users := newUserUseCase(t)
+			ctx := context.Background()
+
+			actor := &entity.User{Name: "Actor", Email: "actor@example.com", Role: tt.actor}
+			if err := users.Create(ctx, actor); err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+			target := actor
+			if tt.target != "own" {
+				target = &entity.User{Name: "Target", Email: "target@example.com", Role: tt.target}
+				if err := users.Create(ctx, target); err != nil {
+					t.Fatalf("Create() error = %v", err)
+				}
+			}
+
+			err := tt.change(uc, usecase.ContextWithUser(ctx, actor), target.ID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil {
+				return
+			}
+			stored, err := users.GetByID(ctx, target.ID)
+			if err != nil {
+				t.Fatalf("GetByID() error = %v", err)
+			}
+			if stored.Role != target.Role || !stored.Active || stored.Version != target.Version {
+				t.Errorf("denied change was stored: %+v", stored)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"go-clean-architecture/internal/entity"
 	"go-clean-architecture/internal/usecase/interfaces"
@@ -10,34 +11,121 @@ import (
 // UserUseCase implements business logic for user operations
 type UserUseCase struct {
 	userRepo interfaces.UserRepository
+	outbox   interfaces.OutboxRepository
+	tx       interfaces.TxManager
+	hasher   interfaces.PasswordHasher
+	policy   *UserPolicy
+	metrics  interfaces.OperationMetrics
 }
 
 // NewUserUseCase creates a new user use case instance
-func NewUserUseCase(userRepo interfaces.UserRepository) *UserUseCase {
+func NewUserUseCase(userRepo interfaces.UserRepository, outbox interfaces.OutboxRepository, tx interfaces.TxManager, hasher interfaces.PasswordHasher, metrics interfaces.OperationMetrics) *UserUseCase {
 	return &UserUseCase{
 		userRepo: userRepo,
+		outbox:   outbox,
+		tx:       tx,
+		hasher:   hasher,
+		policy:   NewUserPolicy(),
+		metrics:  metrics,
 	}
 }
 
 // CreateUser creates a new user with business validation
-func (uc *UserUseCase) CreateUser(ctx context.Context, user *entity.User) error {
+func (uc *UserUseCase) CreateUser(ctx context.Context, user *entity.User) (err error) {
+	ctx, finish := uc.start(ctx, "create_user")
+	defer finish(&err)
+
 	// Business validation
 	if !user.IsValid() {
 		return entity.ErrInvalidUserName
 	}
+	if user.Role == "" {
+		user.Role = entity.RoleSelf
+	}
+	if !entity.IsValidRole(user.Role) {
+		return entity.ErrInvalidUserRole
+	}
 
-	// Check if user already exists
-	existingUser, err := uc.userRepo.GetByEmail(ctx, user.Email)
-	if err == nil && existingUser != nil {
-		return entity.ErrUserAlreadyExists
+	if err := uc.authorize(ctx, ActionCreateUser, user); err != nil {
+		return err
 	}
 
-	// Create user
-	return uc.userRepo.Create(ctx, user)
+	// Hash before the transaction so it is not held open while hashing
+	if err := uc.hashPassword(user); err != nil {
+		return err
+	}
+
+	return uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		// Check if user already exists
+		existingUser, err := uc.userRepo.GetByEmail(ctx, user.Email)
+		if err == nil && existingUser != nil {
+			return entity.ErrUserAlreadyExists
+		}
+		if err != nil && !errors.Is(err, entity.ErrUserNotFound) {
+			return err
+		}
+
+		// A retried attempt must not reuse the ID of a rolled back insert
+		user.ID = 0
+		if err := uc.userRepo.Create(ctx, user); err != nil {
+			return err
+		}
+		return uc.outbox.Add(ctx, entity.UserCreated{User: *user})
+	})
+}
+
+// EnsureAdmin creates an admin account unless a user with the email exists.
+// It bypasses the policy and is meant for bootstrapping at startup.
+func (uc *UserUseCase) EnsureAdmin(ctx context.Context, name, email, password string) error {
+	if password == "" {
+		return fmt.Errorf("admin password is required")
+	}
+
+	admin := &entity.User{
+		Name:     name,
+		Email:    email,
+		Password: password,
+		Role:     entity.RoleAdmin,
+		Active:   true,
+	}
+	if err := uc.hashPassword(admin); err != nil {
+		return err
+	}
+
+	return uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		existingUser, err := uc.userRepo.GetByEmail(ctx, email)
+		if err == nil && existingUser != nil {
+			return nil
+		}
+		if err != nil && !errors.Is(err, entity.ErrUserNotFound) {
+			return err
+		}
+		if err := uc.userRepo.Create(ctx, admin); err != nil {
+			return err
+		}
+		return uc.outbox.Add(ctx, entity.UserCreated{User: *admin})
+	})
 }
 
 // GetUser retrieves a user by ID
-func (uc *UserUseCase) GetUser(ctx context.Context, id uint) (*entity.User, error) {
+func (uc *UserUseCase) GetUser(ctx context.Context, id uint) (_ *entity.User, err error) {
+	ctx, finish := uc.start(ctx, "get_user")
+	defer finish(&err)
+
+	user, err := uc.getUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.authorize(ctx, ActionReadUser, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// getUser retrieves a user by ID without authorization
+func (uc *UserUseCase) getUser(ctx context.Context, id uint) (*entity.User, error) {
 	if id == 0 {
 		return nil, entity.ErrInvalidUserID
 	}
@@ -55,16 +143,38 @@ func (uc *UserUseCase) GetUser(ctx context.Context, id uint) (*entity.User, erro
 }
 
 // GetUserByEmail retrieves a user by email
-func (uc *UserUseCase) GetUserByEmail(ctx context.Context, email string) (*entity.User, error) {
+func (uc *UserUseCase) GetUserByEmail(ctx context.Context, email string) (_ *entity.User, err error) {
+	ctx, finish := uc.start(ctx, "get_user_by_email")
+	defer finish(&err)
+
 	if email == "" {
 		return nil, entity.ErrInvalidUserEmail
 	}
 
-	return uc.userRepo.GetByEmail(ctx, email)
+	user, err := uc.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.authorize(ctx, ActionReadUser, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
 }
 
-// GetAllUsers retrieves all users with pagination
-func (uc *UserUseCase) GetAllUsers(ctx context.Context, page, pageSize int) ([]*entity.User, int64, error) {
+// GetAllUsers retrieves users matching the query with pagination
+func (uc *UserUseCase) GetAllUsers(ctx context.Context, query interfaces.UserQuery, page, pageSize int) (_ []*entity.User, _ int64, err error) {
+	ctx, finish := uc.start(ctx, "list_users")
+	defer finish(&err)
+
+	if err := uc.authorize(ctx, ActionListUsers, nil); err != nil {
+		return nil, 0, err
+	}
+	if err := validateUserQuery(query); err != nil {
+		return nil, 0, err
+	}
+
 	if page < 1 {
 		page = 1
 	}
@@ -73,12 +183,12 @@ func (uc *UserUseCase) GetAllUsers(ctx context.Context, page, pageSize int) ([]*
 	}
 
 	offset := (page - 1) * pageSize
-	users, err := uc.userRepo.GetAll(ctx, pageSize, offset)
+	users, err := uc.userRepo.GetAll(ctx, query, pageSize, offset)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	total, err := uc.userRepo.Count(ctx)
+	total, err := uc.userRepo.Count(ctx, query.Filter)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -86,19 +196,105 @@ func (uc *UserUseCase) GetAllUsers(ctx context.Context, page, pageSize int) ([]*
 	return users, total, nil
 }
 
-// UpdateUser updates an existing user
-func (uc *UserUseCase) UpdateUser(ctx context.Context, id uint, user *entity.User) error {
-	if id == 0 {
-		return entity.ErrInvalidUserID
+// UserCursorPage is a keyset page of users with cursors for its neighbours.
+// Next and Prev are nil when there is nothing further in that direction.
+type UserCursorPage struct {
+	Users []*entity.User
+	Next  *interfaces.UserCursor
+	Prev  *interfaces.UserCursor
+}
+
+// GetUsersByCursor retrieves users matching the query with keyset pagination
+func (uc *UserUseCase) GetUsersByCursor(ctx context.Context, query interfaces.UserQuery, page interfaces.CursorPage) (_ *UserCursorPage, err error) {
+	ctx, finish := uc.start(ctx, "list_users_by_cursor")
+	defer finish(&err)
+
+	if err := uc.authorize(ctx, ActionListUsers, nil); err != nil {
+		return nil, err
+	}
+	if err := validateUserQuery(query); err != nil {
+		return nil, err
+	}
+
+	// Keyset pagination only supports its own fixed ordering
+	for _, field := range query.Sort {
+		if field.Field != interfaces.UserSortCreatedAt || !field.Desc {
+			return nil, fmt.Errorf("%w: cursor pagination only supports sort=-created_at", entity.ErrInvalidUserQuery)
+		}
+	}
+
+	if page.Limit < 1 || page.Limit > 100 {
+		page.Limit = 10
 	}
+	limit := page.Limit
 
-	// Check if user exists
-	existingUser, err := uc.userRepo.GetByID(ctx, id)
+	// Fetch one extra row to find out whether another page exists
+	page.Limit++
+	users, err := uc.userRepo.GetAllByCursor(ctx, query.Filter, page)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if existingUser == nil {
-		return entity.ErrUserNotFound
+
+	hasMore := len(users) > limit
+	if hasMore {
+		if page.Backward {
+			users = users[1:]
+		} else {
+			users = users[:limit]
+		}
+	}
+
+	result := &UserCursorPage{Users: users}
+	if len(users) == 0 {
+		return result, nil
+	}
+
+	// Coming from one side guarantees rows exist on that side
+	hasNext := (hasMore && !page.Backward) || (page.Backward && page.Cursor != nil)
+	hasPrev := (hasMore && page.Backward) || (!page.Backward && page.Cursor != nil)
+
+	if hasNext {
+		result.Next = userCursor(users[len(users)-1])
+	}
+	if hasPrev {
+		result.Prev = userCursor(users[0])
+	}
+	return result, nil
+}
+
+// userCursor returns the keyset position of a user
+func userCursor(user *entity.User) *interfaces.UserCursor {
+	return &interfaces.UserCursor{CreatedAt: user.CreatedAt, ID: user.ID}
+}
+
+// validateUserQuery checks that a listing query is well formed
+func validateUserQuery(query interfaces.UserQuery) error {
+	filter := query.Filter
+	if filter.CreatedAfter != nil && filter.CreatedBefore != nil && !filter.CreatedAfter.Before(*filter.CreatedBefore) {
+		return fmt.Errorf("%w: created_after must be before created_before", entity.ErrInvalidUserQuery)
+	}
+
+	seen := make(map[string]bool, len(query.Sort))
+	for _, field := range query.Sort {
+		if !interfaces.IsSortableUserField(field.Field) {
+			return fmt.Errorf("%w: cannot sort by %q", entity.ErrInvalidUserQuery, field.Field)
+		}
+		if seen[field.Field] {
+			return fmt.Errorf("%w: duplicate sort field %q", entity.ErrInvalidUserQuery, field.Field)
+		}
+		seen[field.Field] = true
+	}
+	return nil
+}
+
+// UpdateUser updates an existing user.
+// A non-zero expectedVersion must match the stored version.
+func (uc *UserUseCase) UpdateUser(ctx context.Context, id uint, user *entity.User, expectedVersion uint) (err error) {
+	ctx, finish := uc.start(ctx, "update_user")
+	defer finish(&err)
+
+	if id == 0 {
+		return entity.ErrInvalidUserID
 	}
 
 	// Business validation
@@ -106,54 +302,279 @@ func (uc *UserUseCase) UpdateUser(ctx context.Context, id uint, user *entity.Use
 		return entity.ErrInvalidUserName
 	}
 
-	// Check email uniqueness if email is being changed
-	if user.Email != existingUser.Email {
-		emailUser, err := uc.userRepo.GetByEmail(ctx, user.Email)
-		if err == nil && emailUser != nil && emailUser.ID != id {
-			return fmt.Errorf("email already taken by another user")
+	// Hash once up front, a retried transaction reuses the hash
+	if err := uc.hashPassword(user); err != nil {
+		return err
+	}
+	passwordHash := user.PasswordHash
+
+	return uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		// Check if user exists
+		existingUser, err := uc.userRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if existingUser == nil {
+			return entity.ErrUserNotFound
+		}
+		if err := checkVersion(existingUser, expectedVersion); err != nil {
+			return err
+		}
+
+		// Keep the current role unless a new one was supplied
+		if user.Role == "" {
+			user.Role = existingUser.Role
+		}
+		// A PUT body cannot tell an omitted active from false, so activation
+		// only changes through PATCH or the activate and deactivate actions
+		user.Active = existingUser.Active
+		if err := uc.authorizeUpdate(ctx, existingUser, &user.Role, nil); err != nil {
+			return err
+		}
+
+		// Check email uniqueness if email is being changed
+		if user.Email != existingUser.Email {
+			emailUser, err := uc.userRepo.GetByEmail(ctx, user.Email)
+			if err == nil && emailUser != nil && emailUser.ID != id {
+				return entity.ErrUserAlreadyExists
+			}
+			if err != nil && !errors.Is(err, entity.ErrUserNotFound) {
+				return err
+			}
+		}
+
+		// Keep the current password unless a new one was supplied
+		user.PasswordHash = passwordHash
+		if user.PasswordHash == "" {
+			user.PasswordHash = existingUser.PasswordHash
+		}
+
+		user.ID = id
+		user.Version = existingUser.Version
+		user.CreatedAt = existingUser.CreatedAt
+		if err := uc.userRepo.Update(ctx, user); err != nil {
+			return err
+		}
+		return uc.outbox.Add(ctx, updateEvents(existingUser, user)...)
+	})
+}
+
+// PatchUser applies a partial update and returns the updated user.
+// A non-zero expectedVersion must match the stored version.
+func (uc *UserUseCase) PatchUser(ctx context.Context, id uint, patch *entity.UserPatch, expectedVersion uint) (_ *entity.User, err error) {
+	ctx, finish := uc.start(ctx, "patch_user")
+	defer finish(&err)
+
+	// Business validation
+	if patch.Name != nil && *patch.Name == "" {
+		return nil, entity.ErrInvalidUserName
+	}
+	if patch.Email != nil && *patch.Email == "" {
+		return nil, entity.ErrInvalidUserEmail
+	}
+
+	// Hash once up front, a retried transaction reuses the hash
+	if patch.Password != nil {
+		hash, err := uc.hasher.Hash(*patch.Password)
+		if err != nil {
+			return nil, err
 		}
+		patch.PasswordHash = &hash
+		patch.Password = nil
 	}
 
-	user.ID = id
-	return uc.userRepo.Update(ctx, user)
+	var user *entity.User
+	err = uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		existingUser, err := uc.getUser(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion(existingUser, expectedVersion); err != nil {
+			return err
+		}
+		if err := uc.authorizeUpdate(ctx, existingUser, patch.Role, patch.Active); err != nil {
+			return err
+		}
+
+		if patch.IsEmpty() {
+			user = existingUser
+			return nil
+		}
+
+		// Check email uniqueness if email is being changed
+		if patch.Email != nil && *patch.Email != existingUser.Email {
+			emailUser, err := uc.userRepo.GetByEmail(ctx, *patch.Email)
+			if err == nil && emailUser != nil && emailUser.ID != id {
+				return entity.ErrUserAlreadyExists
+			}
+			if err != nil && !errors.Is(err, entity.ErrUserNotFound) {
+				return err
+			}
+		}
+
+		if err := uc.userRepo.Patch(ctx, id, existingUser.Version, patch); err != nil {
+			return err
+		}
+
+		user, err = uc.userRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		return uc.outbox.Add(ctx, updateEvents(existingUser, user)...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
 }
 
-// DeleteUser deletes a user by ID
-func (uc *UserUseCase) DeleteUser(ctx context.Context, id uint) error {
+// DeleteUser deletes a user by ID.
+// A non-zero expectedVersion must match the stored version.
+func (uc *UserUseCase) DeleteUser(ctx context.Context, id uint, expectedVersion uint) (err error) {
+	ctx, finish := uc.start(ctx, "delete_user")
+	defer finish(&err)
+
 	if id == 0 {
 		return entity.ErrInvalidUserID
 	}
 
-	// Check if user exists
-	user, err := uc.userRepo.GetByID(ctx, id)
-	if err != nil {
-		return err
-	}
-	if user == nil {
-		return entity.ErrUserNotFound
+	return uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		// Check if user exists
+		user, err := uc.userRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			return entity.ErrUserNotFound
+		}
+		if err := checkVersion(user, expectedVersion); err != nil {
+			return err
+		}
+		if err := uc.authorize(ctx, ActionDeleteUser, user); err != nil {
+			return err
+		}
+
+		if err := uc.userRepo.Delete(ctx, id, user.Version); err != nil {
+			return err
+		}
+		return uc.outbox.Add(ctx, entity.UserDeleted{UserID: id})
+	})
+}
+
+// ActivateUser activates a user.
+// A non-zero expectedVersion must match the stored version.
+func (uc *UserUseCase) ActivateUser(ctx context.Context, id uint, expectedVersion uint) (err error) {
+	ctx, finish := uc.start(ctx, "activate_user")
+	defer finish(&err)
+
+	return uc.setActive(ctx, id, expectedVersion, ActionActivateUser, (*entity.User).Activate)
+}
+
+// DeactivateUser deactivates a user.
+// A non-zero expectedVersion must match the stored version.
+func (uc *UserUseCase) DeactivateUser(ctx context.Context, id uint, expectedVersion uint) (err error) {
+	ctx, finish := uc.start(ctx, "deactivate_user")
+	defer finish(&err)
+
+	return uc.setActive(ctx, id, expectedVersion, ActionDeactivateUser, (*entity.User).Deactivate)
+}
+
+// setActive loads a user, authorizes action and applies change in one
+// transaction
+func (uc *UserUseCase) setActive(ctx context.Context, id, expectedVersion uint, action UserAction, change func(*entity.User)) error {
+	return uc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		user, err := uc.getUser(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion(user, expectedVersion); err != nil {
+			return err
+		}
+		if err := uc.authorize(ctx, action, user); err != nil {
+			return err
+		}
+
+		before := *user
+		change(user)
+		if err := uc.userRepo.Update(ctx, user); err != nil {
+			return err
+		}
+		return uc.outbox.Add(ctx, updateEvents(&before, user)...)
+	})
+}
+
+// updateEvents returns the events raised by changing before into after
+func updateEvents(before, after *entity.User) []entity.Event {
+	events := []entity.Event{entity.UserUpdated{User: *after}}
+	switch {
+	case after.Active && !before.Active:
+		events = append(events, entity.UserActivated{UserID: after.ID, Version: after.Version})
+	case !after.Active && before.Active:
+		events = append(events, entity.UserDeactivated{UserID: after.ID, Version: after.Version})
 	}
+	return events
+}
 
-	return uc.userRepo.Delete(ctx, id)
+// start opens a span for an operation. The returned function ends it and
+// counts the operation by the outcome of its error.
+func (uc *UserUseCase) start(ctx context.Context, operation string) (context.Context, func(err *error)) {
+	return startOperation(ctx, uc.metrics, operation)
 }
 
-// ActivateUser activates a user
-func (uc *UserUseCase) ActivateUser(ctx context.Context, id uint) error {
-	user, err := uc.GetUser(ctx, id)
-	if err != nil {
+// authorize checks the policy for the user stored in ctx
+func (uc *UserUseCase) authorize(ctx context.Context, action UserAction, target *entity.User) error {
+	actor, _ := UserFromContext(ctx)
+	return uc.policy.Authorize(actor, action, target)
+}
+
+// authorizeUpdate checks permission to update a user, including the extra
+// permissions needed when the role or active flag change. nil means unchanged.
+func (uc *UserUseCase) authorizeUpdate(ctx context.Context, existingUser *entity.User, role *string, active *bool) error {
+	if err := uc.authorize(ctx, ActionUpdateUser, existingUser); err != nil {
 		return err
 	}
 
-	user.Activate()
-	return uc.userRepo.Update(ctx, user)
+	if role != nil && *role != existingUser.Role {
+		if !entity.IsValidRole(*role) {
+			return entity.ErrInvalidUserRole
+		}
+		if err := uc.authorize(ctx, ActionAssignRole, existingUser); err != nil {
+			return err
+		}
+	}
+
+	if active != nil && *active != existingUser.Active {
+		action := ActionDeactivateUser
+		if *active {
+			action = ActionActivateUser
+		}
+		if err := uc.authorize(ctx, action, existingUser); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-// DeactivateUser deactivates a user
-func (uc *UserUseCase) DeactivateUser(ctx context.Context, id uint) error {
-	user, err := uc.GetUser(ctx, id)
+// hashPassword replaces a supplied plaintext password with its hash
+func (uc *UserUseCase) hashPassword(user *entity.User) error {
+	if user.Password == "" {
+		return nil
+	}
+
+	hash, err := uc.hasher.Hash(user.Password)
 	if err != nil {
 		return err
 	}
+	user.PasswordHash = hash
+	user.Password = ""
+	return nil
+}
 
-	user.Deactivate()
-	return uc.userRepo.Update(ctx, user)
+// checkVersion rejects writes based on a stale read.
+// A zero expectedVersion means the caller did not ask for a check.
+func checkVersion(user *entity.User, expectedVersion uint) error {
+	if expectedVersion != 0 && user.Version != expectedVersion {
+		return entity.ErrVersionConflict
+	}
+	return nil
 }
@@ -0,0 +1,362 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"go-clean-architecture/internal/adapter/repository"
+	"go-clean-architecture/internal/entity"
+	"go-clean-architecture/internal/infrastructure/metrics"
+	"go-clean-architecture/internal/usecase"
+	"go-clean-architecture/internal/usecase/interfaces"
+	"slices"
+	"testing"
+	"time"
+)
+
+// plainHasher is a reversible PasswordHasher that keeps tests fast
+type plainHasher struct{}
+
+func (plainHasher) Hash(password string) (string, error) { return "hashed:" + password, nil }
+
+func (plainHasher) Compare(hash, password string) error {
+	if hash != "hashed:"+password {
+		return entity.ErrInvalidCredentials
+	}
+	return nil
+}
+
+// newUserUseCase returns a use case backed by the in-memory repositories
+func newUserUseCase(t *testing.T) (*usecase.UserUseCase, interfaces.UserRepository) {
+	t.Helper()
+	users := repository.NewMemoryUserRepository()
+	uc := usecase.NewUserUseCase(users, repository.NewMemoryOutboxRepository(), repository.NewMemoryTxManager(), plainHasher{}, metrics.NewMetrics())
+	return uc, users
+}
+
+// asAdmin returns a context authenticated as an admin that is not stored
+func asAdmin() context.Context {
+	return usecase.ContextWithUser(context.Background(), &entity.User{ID: 1000, Role: entity.RoleAdmin})
+}
+
+// createUsers stores users one minute apart through the repository and
+// returns their IDs in creation order
+func createUsers(t *testing.T, repo interfaces.UserRepository, names ...string) []uint {
+	t.Helper()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ids := make([]uint, len(names))
+	for i, name := range names {
+		user := &entity.User{Name: name, Email: name + "@example.com", CreatedAt: base.Add(time.Duration(i) * time.Minute)}
+		if err := repo.Create(context.Background(), user); err != nil {
+			t.Fatalf("Create(%s) error = %v", name, err)
+		}
+		ids[i] = user.ID
+	}
+	return ids
+}
+
+// userIDs returns the IDs of users in order
+func userIDs(users []*entity.User) []uint {
+	result := make([]uint, len(users))
+	for i, user := range users {
+		result[i] = user.ID
+	}
+	return result
+}
+
+func TestUserUseCaseCreateUser(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		user    *entity.User
+		wantErr error
+	}{
+		{name: "anonymous registration", ctx: context.Background(), user: &entity.User{Name: "Bob", Email: "bob@example.com", Password: "password123"}},
+		{name: "anonymous admin", ctx: context.Background(), user: &entity.User{Name: "Bob", Email: "bob@example.com", Role: entity.RoleAdmin}, wantErr: entity.ErrForbidden},
+		{name: "admin creates operator", ctx: asAdmin(), user: &entity.User{Name: "Bob", Email: "bob@example.com", Role: entity.RoleOperator}},
+		{name: "email taken", ctx: context.Background(), user: &entity.User{Name: "Alice", Email: "alice@example.com"}, wantErr: entity.ErrUserAlreadyExists},
+		{name: "missing name", ctx: context.Background(), user: &entity.User{Email: "bob@example.com"}, wantErr: entity.ErrInvalidUserName},
+		{name: "unknown role", ctx: asAdmin(), user: &entity.User{Name: "Bob", Email: "bob@example.com", Role: "root"}, wantErr: entity.ErrInvalidUserRole},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, repo := newUserUseCase(t)
+			createUsers(t, repo, "alice")
+
+			err := uc.CreateUser(tt.ctx, tt.user)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateUser() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if tt.user.Password != "" {
+				t.Error("CreateUser() kept the plaintext password")
+			}
+			stored, err := repo.GetByEmail(context.Background(), tt.user.Email)
+			if err != nil {
+				t.Fatalf("GetByEmail() error = %v", err)
+			}
+			if stored.ID != tt.user.ID {
+				t.Errorf("stored ID = %d, want %d", stored.ID, tt.user.ID)
+			}
+		})
+	}
+}
+
+func TestUserUseCaseGetAllUsers(t *testing.T) {
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		query     interfaces.UserQuery
+		page      int
+		pageSize  int
+		want      []uint
+		wantTotal int64
+		wantErr   error
+	}{
+		{name: "newest first", ctx: asAdmin(), page: 1, pageSize: 2, want: []uint{4, 3}, wantTotal: 4},
+		{name: "second page", ctx: asAdmin(), page: 2, pageSize: 2, want: []uint{2, 1}, wantTotal: 4},
+		{name: "invalid page size falls back to default", ctx: asAdmin(), page: 0, pageSize: 500, want: []uint{4, 3, 2, 1}, wantTotal: 4},
+		{
+			name:      "search and sort",
+			ctx:       asAdmin(),
+			query:     interfaces.UserQuery{Filter: interfaces.UserFilter{Search: "AL"}, Sort: []interfaces.SortField{{Field: interfaces.UserSortName, Desc: true}}},
+			page:      1,
+			pageSize:  10,
+			want:      []uint{3, 1},
+			wantTotal: 2,
+		},
+		{
+			name:    "unsortable field",
+			ctx:     asAdmin(),
+			query:   interfaces.UserQuery{Sort: []interfaces.SortField{{Field: "password_hash"}}},
+			wantErr: entity.ErrInvalidUserQuery,
+		},
+		{
+			name: "duplicate sort field",
+			ctx:  asAdmin(),
+			query: interfaces.UserQuery{Sort: []interfaces.SortField{
+				{Field: interfaces.UserSortName}, {Field: interfaces.UserSortName, Desc: true},
+			}},
+			wantErr: entity.ErrInvalidUserQuery,
+		},
+		{
+			name:    "inverted date range",
+			ctx:     asAdmin(),
+			query:   interfaces.UserQuery{Filter: interfaces.UserFilter{CreatedAfter: ptr(time.Now()), CreatedBefore: ptr(time.Now().Add(-time.Hour))}},
+			wantErr: entity.ErrInvalidUserQuery,
+		},
+		{name: "self users cannot list", ctx: usecase.ContextWithUser(context.Background(), &entity.User{ID: 1, Role: entity.RoleSelf}), wantErr: entity.ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, repo := newUserUseCase(t)
+			createUsers(t, repo, "alice", "bob", "valerie", "dave")
+
+			users, total, err := uc.GetAllUsers(tt.ctx, tt.query, tt.page, tt.pageSize)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetAllUsers() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := userIDs(users); !slices.Equal(got, tt.want) || total != tt.wantTotal {
+				t.Errorf("GetAllUsers() = %v, total %d, want %v, total %d", got, total, tt.want, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestUserUseCaseGetUsersByCursor(t *testing.T) {
+	ctx := asAdmin()
+	uc, repo := newUserUseCase(t)
+	createUsers(t, repo, "a", "b", "c", "d", "e")
+
+	// Walk forward through every page, then back again from the last one
+	type step struct {
+		want     []uint
+		wantNext bool
+		wantPrev bool
+	}
+	forward := []step{
+		{want: []uint{5, 4}, wantNext: true},
+		{want: []uint{3, 2}, wantNext: true, wantPrev: true},
+		{want: []uint{1}, wantPrev: true},
+	}
+	backward := []step{
+		{want: []uint{3, 2}, wantNext: true, wantPrev: true},
+		{want: []uint{5, 4}, wantNext: true},
+	}
+
+	check := func(t *testing.T, page *usecase.UserCursorPage, s step) {
+		t.Helper()
+		if got := userIDs(page.Users); !slices.Equal(got, s.want) {
+			t.Fatalf("users = %v, want %v", got, s.want)
+		}
+		if (page.Next != nil) != s.wantNext || (page.Prev != nil) != s.wantPrev {
+			t.Fatalf("next %t, prev %t, want %t, %t", page.Next != nil, page.Prev != nil, s.wantNext, s.wantPrev)
+		}
+	}
+
+	request := interfaces.CursorPage{Limit: 2}
+	var page *usecase.UserCursorPage
+	for i, s := range forward {
+		var err error
+		page, err = uc.GetUsersByCursor(ctx, interfaces.UserQuery{}, request)
+		if err != nil {
+			t.Fatalf("forward page %d: GetUsersByCursor() error = %v", i, err)
+		}
+		check(t, page, s)
+		request = interfaces.CursorPage{Cursor: page.Next, Limit: 2}
+	}
+
+	request = interfaces.CursorPage{Cursor: page.Prev, Backward: true, Limit: 2}
+	for i, s := range backward {
+		page, err := uc.GetUsersByCursor(ctx, interfaces.UserQuery{}, request)
+		if err != nil {
+			t.Fatalf("backward page %d: GetUsersByCursor() error = %v", i, err)
+		}
+		check(t, page, s)
+		request = interfaces.CursorPage{Cursor: page.Prev, Backward: true, Limit: 2}
+	}
+
+	_, err := uc.GetUsersByCursor(ctx, interfaces.UserQuery{Sort: []interfaces.SortField{{Field: interfaces.UserSortName}}}, interfaces.CursorPage{})
+	if !errors.Is(err, entity.ErrInvalidUserQuery) {
+		t.Errorf("GetUsersByCursor() with sort=name error = %v, want %v", err, entity.ErrInvalidUserQuery)
+	}
+}
+
+func TestUserUseCaseDeleteUser(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		id      uint
+		version uint
+		wantErr error
+	}{
+		{name: "admin deletes", ctx: asAdmin(), id: 1},
+		{name: "matching version", ctx: asAdmin(), id: 1, version: 1},
+		{name: "stale version", ctx: asAdmin(), id: 1, version: 3, wantErr: entity.ErrVersionConflict},
+		{name: "unknown user", ctx: asAdmin(), id: 9, wantErr: entity.ErrUserNotFound},
+		{name: "zero id", ctx: asAdmin(), wantErr: entity.ErrInvalidUserID},
+		{name: "self cannot delete", ctx: usecase.ContextWithUser(context.Background(), &entity.User{ID: 1, Role: entity.RoleSelf}), id: 1, wantErr: entity.ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, repo := newUserUseCase(t)
+			createUsers(t, repo, "alice")
+
+			err := uc.DeleteUser(tt.ctx, tt.id, tt.version)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("DeleteUser() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if _, err := uc.GetUser(tt.ctx, tt.id); !errors.Is(err, entity.ErrUserNotFound) {
+				t.Errorf("GetUser() after delete error = %v, want %v", err, entity.ErrUserNotFound)
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestUserUseCaseUpdateUserKeepsActive(t *testing.T) {
+	tests := []struct {
+		name    string
+		actor   *entity.User
+		active  bool
+		role    string
+		wantErr error
+	}{
+		{name: "self omits active", actor: &entity.User{ID: 1, Role: entity.RoleSelf}},
+		{name: "self sends active", actor: &entity.User{ID: 1, Role: entity.RoleSelf}, active: true},
+		{name: "admin cannot deactivate through PUT", actor: &entity.User{ID: 1000, Role: entity.RoleAdmin}},
+		{name: "self cannot change role", actor: &entity.User{ID: 1, Role: entity.RoleSelf}, role: entity.RoleAdmin, wantErr: entity.ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, repo := newUserUseCase(t)
+			createUsers(t, repo, "alice")
+			ctx := usecase.ContextWithUser(context.Background(), tt.actor)
+
+			update := &entity.User{Name: "Alicia", Email: "alice@example.com", Active: tt.active, Role: tt.role}
+			err := uc.UpdateUser(ctx, 1, update, 1)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateUser() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			stored, err := repo.GetByID(context.Background(), 1)
+			if err != nil {
+				t.Fatalf("GetByID() error = %v", err)
+			}
+			if stored.Name != "Alicia" || !stored.Active {
+				t.Errorf("stored user = %q active %t, want %q active true", stored.Name, stored.Active, "Alicia")
+			}
+		})
+	}
+}
+
+// failingEmailLookup is a UserRepository whose GetByEmail always fails
+type failingEmailLookup struct {
+	interfaces.UserRepository
+	err error
+}
+
+func (r failingEmailLookup) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
+	return nil, r.err
+}
+
+func TestUserUseCaseEmailLookupFailure(t *testing.T) {
+	lookupErr := errors.New("connection reset")
+	email := "new@example.com"
+
+	tests := []struct {
+		name   string
+		change func(ctx context.Context, uc *usecase.UserUseCase) error
+	}{
+		{
+			name: "update",
+			change: func(ctx context.Context, uc *usecase.UserUseCase) error {
+				return uc.UpdateUser(ctx, 1, &entity.User{Name: "Alice", Email: email}, 0)
+			},
+		},
+		{
+			name: "patch",
+			change: func(ctx context.Context, uc *usecase.UserUseCase) error {
+				_, err := uc.PatchUser(ctx, 1, &entity.UserPatch{Email: &email}, 0)
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := repository.NewMemoryUserRepository()
+			createUsers(t, users, "alice")
+			repo := failingEmailLookup{UserRepository: users, err: lookupErr}
+			uc := usecase.NewUserUseCase(repo, repository.NewMemoryOutboxRepository(), repository.NewMemoryTxManager(), plainHasher{}, metrics.NewMetrics())
+
+			if err := tt.change(asAdmin(), uc); !errors.Is(err, lookupErr) {
+				t.Fatalf("error = %v, want %v", err, lookupErr)
+			}
+			stored, err := users.GetByID(context.Background(), 1)
+			if err != nil {
+				t.Fatalf("GetByID() error = %v", err)
+			}
+			if stored.Email != "alice@example.com" {
+				t.Errorf("email = %q, want it unchanged", stored.Email)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"go-clean-architecture/internal/entity"
+	"go-clean-architecture/internal/usecase/interfaces"
+	"time"
+)
+
+// webhookPayload is the body POSTed to webhook subscribers
+type webhookPayload struct {
+	ID         uint64          `json:"id"`
+	Type       string          `json:"type"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// WebhookUseCase implements business logic for webhook subscriptions.
+// Only admins may manage webhooks.
+type WebhookUseCase struct {
+	webhookRepo  interfaces.WebhookRepository
+	deliveryRepo interfaces.WebhookDeliveryRepository
+	metrics      interfaces.OperationMetrics
+}
+
+// NewWebhookUseCase creates a new webhook use case instance
+func NewWebhookUseCase(webhookRepo interfaces.WebhookRepository, deliveryRepo interfaces.WebhookDeliveryRepository, metrics interfaces.OperationMetrics) *WebhookUseCase {
+	return &WebhookUseCase{
+		webhookRepo:  webhookRepo,
+		deliveryRepo: deliveryRepo,
+		metrics:      metrics,
+	}
+}
+
+// CreateWebhook creates a webhook, generating a secret when none is given
+func (uc *WebhookUseCase) CreateWebhook(ctx context.Context, webhook *entity.Webhook) (err error) {
+	ctx, finish := startOperation(ctx, uc.metrics, "create_webhook")
+	defer finish(&err)
+
+	if err := authorizeAdmin(ctx); err != nil {
+		return err
+	}
+	if err := webhook.Validate(); err != nil {
+		return err
+	}
+
+	if webhook.Secret == "" {
+		secret, err := newWebhookSecret()
+		if err != nil {
+			return err
+		}
+		webhook.Secret = secret
+	}
+
+	return uc.webhookRepo.Create(ctx, webhook)
+}
+
+// GetWebhook retrieves a webhook by ID
+func (uc *WebhookUseCase) GetWebhook(ctx context.Context, id uint) (_ *entity.Webhook, err error) {
+	ctx, finish := startOperation(ctx, uc.metrics, "get_webhook")
+	defer finish(&err)
+
+	if err := authorizeAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if id == 0 {
+		return nil, entity.ErrInvalidWebhookID
+	}
+
+	return uc.webhookRepo.GetByID(ctx, id)
+}
+
+// ListWebhooks retrieves every webhook
+func (uc *WebhookUseCase) ListWebhooks(ctx context.Context) (_ []*entity.Webhook, err error) {
+	ctx, finish := startOperation(ctx, uc.metrics, "list_webhooks")
+	defer finish(&err)
+
+	if err := authorizeAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	return uc.webhookRepo.GetAll(ctx)
+}
+
+// UpdateWebhook replaces a webhook. The secret is kept unless a new one is given.
+func (uc *WebhookUseCase) UpdateWebhook(ctx context.Context, id uint, webhook *entity.Webhook) (err error) {
+	ctx, finish := startOperation(ctx, uc.metrics, "update_webhook")
+	defer finish(&err)
+
+	if err := authorizeAdmin(ctx); err != nil {
+		return err
+	}
+	if id == 0 {
+		return entity.ErrInvalidWebhookID
+	}
+	if err := webhook.Validate(); err != nil {
+		return err
+	}
+
+	existing, err := uc.webhookRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	webhook.ID = id
+	webhook.CreatedAt = existing.CreatedAt
+	if webhook.Secret == "" {
+		webhook.Secret = existing.Secret
+	}
+	return uc.webhookRepo.Update(ctx, webhook)
+}
+
+// DeleteWebhook deletes a webhook and its delivery log
+func (uc *WebhookUseCase) DeleteWebhook(ctx context.Context, id uint) (err error) {
+	ctx, finish := startOperation(ctx, uc.metrics, "delete_webhook")
+	defer finish(&err)
+
+	if err := authorizeAdmin(ctx); err != nil {
+		return err
+	}
+	if id == 0 {
+		return entity.ErrInvalidWebhookID
+	}
+
+	return uc.webhookRepo.Delete(ctx, id)
+}
+
+// ListDeliveries retrieves the delivery log of a webhook with pagination
+func (uc *WebhookUseCase) ListDeliveries(ctx context.Context, webhookID uint, page, pageSize int) (_ []*entity.WebhookDelivery, _ int64, err error) {
+	ctx, finish := startOperation(ctx, uc.metrics, "list_webhook_deliveries")
+	defer finish(&err)
+
+	if err := authorizeAdmin(ctx); err != nil {
+		return nil, 0, err
+	}
+	if webhookID == 0 {
+		return nil, 0, entity.ErrInvalidWebhookID
+	}
+	if _, err := uc.webhookRepo.GetByID(ctx, webhookID); err != nil {
+		return nil, 0, err
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	deliveries, err := uc.deliveryRepo.GetByWebhook(ctx, webhookID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := uc.deliveryRepo.CountByWebhook(ctx, webhookID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return deliveries, total, nil
+}
+
+// Publish queues a delivery of an outbox message for every subscribed
+// webhook. It implements interfaces.EventPublisher so the outbox relay can
+// fan events out; the webhook dispatcher sends the queued deliveries.
+func (uc *WebhookUseCase) Publish(ctx context.Context, message *interfaces.OutboxMessage) error {
+	webhooks, err := uc.webhookRepo.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(webhookPayload{
+		ID:         message.ID,
+		Type:       message.EventType,
+		OccurredAt: message.OccurredAt,
+		Data:       message.Payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	var deliveries []*entity.WebhookDelivery
+	now := time.Now()
+	for _, webhook := range webhooks {
+		if !webhook.Subscribes(message.EventType) {
+			continue
+		}
+		deliveries = append(deliveries, &entity.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventID:       message.ID,
+			EventType:     message.EventType,
+			Payload:       string(payload),
+			Status:        entity.DeliveryPending,
+			NextAttemptAt: now,
+		})
+	}
+	return uc.deliveryRepo.Create(ctx, deliveries...)
+}
+
+// authorizeAdmin allows only admins
+func authorizeAdmin(ctx context.Context) error {
+	actor, ok := UserFromContext(ctx)
+	if !ok || !actor.HasRole(entity.RoleAdmin) {
+		return entity.ErrForbidden
+	}
+	return nil
+}
+
+// newWebhookSecret generates a random signing secret
+func newWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
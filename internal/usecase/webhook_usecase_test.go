@@ -0,0 +1,56 @@
+package usecase_test
+
+import (
+	"errors"
+	"go-clean-architecture/internal/adapter/repository"
+	"go-clean-architecture/internal/entity"
+	"go-clean-architecture/internal/infrastructure/metrics"
+	"go-clean-architecture/internal/usecase"
+	"testing"
+)
+
+func TestWebhookUseCaseValidatesURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr error
+	}{
+		{url: "https://partner.example.com/hooks"},
+		{url: "http://203.0.113.10:8080/hooks"},
+		{url: "http://[2001:db8::1]/hooks"},
+		{url: "ftp://partner.example.com/hooks", wantErr: entity.ErrInvalidWebhookURL},
+		{url: "file:///etc/passwd", wantErr: entity.ErrInvalidWebhookURL},
+		{url: "gopher://partner.example.com", wantErr: entity.ErrInvalidWebhookURL},
+		{url: "/hooks", wantErr: entity.ErrInvalidWebhookURL},
+		{url: "http://localhost:8080/hooks", wantErr: entity.ErrInvalidWebhookURL},
+		{url: "http://LOCALHOST./hooks", wantErr: entity.ErrInvalidWebhookURL},
+		{url: "http://admin.localhost/hooks", wantErr: entity.ErrInvalidWebhookURL},
+		{url: "http://127.0.0.1/hooks", wantErr: entity.ErrInvalidWebhookURL},
+		{url: "http://127.8.9.10/hooks", wantErr: entity.ErrInvalidWebhookURL},
+		{url: "http://[::1]:8080/hooks", wantErr: entity.ErrInvalidWebhookURL},
+		{url: "http://[::ffff:127.0.0.1]/hooks", wantErr: entity.ErrInvalidWebhookURL},
+		{url: "http://0.0.0.0/hooks", wantErr: entity.ErrInvalidWebhookURL},
+		{url: "http://169.254.169.254/latest/meta-data", wantErr: entity.ErrInvalidWebhookURL},
+		{url: "http://[fe80::1%25eth0]/hooks", wantErr: entity.ErrInvalidWebhookURL},
+		{url: "http://:8080/hooks", wantErr: entity.ErrInvalidWebhookURL},
+	}
+
+	webhooks, deliveries := repository.NewMemoryWebhookRepositories()
+	uc := usecase.NewWebhookUseCase(webhooks, deliveries, metrics.NewMetrics())
+	existing := &entity.Webhook{URL: "https://partner.example.com/hooks", Events: []string{entity.EventAll}, Active: true}
+	if err := uc.CreateWebhook(asAdmin(), existing); err != nil {
+		t.Fatalf("CreateWebhook() error = %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			created := &entity.Webhook{URL: tt.url, Events: []string{entity.EventAll}, Active: true}
+			if err := uc.CreateWebhook(asAdmin(), created); !errors.Is(err, tt.wantErr) {
+				t.Errorf("CreateWebhook() error = %v, want %v", err, tt.wantErr)
+			}
+			updated := &entity.Webhook{URL: tt.url, Events: []string{entity.EventAll}, Active: true}
+			if err := uc.UpdateWebhook(asAdmin(), existing.ID, updated); !errors.Is(err, tt.wantErr) {
+				t.Errorf("UpdateWebhook() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+// Package backoff computes retry delays that grow exponentially
+package backoff
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Exponential returns the delay before the given attempt, starting at
+// minDelay for the first retry and doubling up to maxDelay, plus up to 20%
+// jitter so clients that failed together do not retry together
+func Exponential(attempt int, minDelay, maxDelay time.Duration) time.Duration {
+	delay := minDelay
+	for i := 1; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, maxDelay)
+	if delay <= 0 {
+		return 0
+	}
+	return delay + rand.N(delay/5+1)
+}
@@ -0,0 +1,41 @@
+package backoff
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExponential(t *testing.T) {
+	tests := []struct {
+		attempt  int
+		minDelay time.Duration
+		maxDelay time.Duration
+		want     time.Duration
+	}{
+		{attempt: 1, minDelay: time.Second, maxDelay: time.Minute, want: time.Second},
+		{attempt: 2, minDelay: time.Second, maxDelay: time.Minute, want: 2 * time.Second},
+		{attempt: 4, minDelay: time.Second, maxDelay: time.Minute, want: 8 * time.Second},
+		{attempt: 10, minDelay: time.Second, maxDelay: time.Minute, want: time.Minute},
+		{attempt: 1, minDelay: 0, maxDelay: time.Minute, want: 0},
+	}
+
+	for _, tt := range tests {
+		// Jitter adds up to 20% on top of the base delay
+		for i := 0; i < 100; i++ {
+			got := Exponential(tt.attempt, tt.minDelay, tt.maxDelay)
+			if got < tt.want || got > tt.want+tt.want/5 {
+				t.Fatalf("Exponential(%d, %s, %s) = %s, want %s plus up to 20%%", tt.attempt, tt.minDelay, tt.maxDelay, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestExponentialJitter(t *testing.T) {
+	seen := make(map[time.Duration]bool)
+	for i := 0; i < 100; i++ {
+		seen[Exponential(3, time.Second, time.Minute)] = true
+	}
+	if len(seen) < 2 {
+		t.Errorf("Exponential() returned %d distinct delays, want jitter", len(seen))
+	}
+}
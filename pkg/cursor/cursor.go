@@ -0,0 +1,69 @@
+package cursor
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// MinSecretLength is the shortest signing secret accepted, in bytes
+const MinSecretLength = 32
+
+// ErrInvalidCursor is returned when a token is malformed or its signature does not match
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Codec encodes values into opaque, tamper-proof pagination tokens.
+// A token is the base64url JSON payload followed by its HMAC-SHA256 signature.
+type Codec struct {
+	secret []byte
+}
+
+// NewCodec creates a new cursor codec signing tokens with the given secret
+func NewCodec(secret []byte) *Codec {
+	return &Codec{
+		secret: secret,
+	}
+}
+
+// Encode serializes and signs a value
+func (c *Codec) Encode(v interface{}) (string, error) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(c.sign(encoded)), nil
+}
+
+// Decode verifies a token and deserializes its payload into v
+func (c *Codec) Decode(token string, v interface{}) error {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return ErrInvalidCursor
+	}
+
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, c.sign(encoded)) {
+		return ErrInvalidCursor
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+	if err := json.Unmarshal(payload, v); err != nil {
+		return ErrInvalidCursor
+	}
+	return nil
+}
+
+// sign computes the HMAC of the encoded payload
+func (c *Codec) sign(encoded string) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,79 @@
+package cursor
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// position is a typical cursor payload
+type position struct {
+	CreatedAt time.Time `json:"c"`
+	ID        uint      `json:"i"`
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	codec := NewCodec([]byte("0123456789abcdef0123456789abcdef"))
+	want := position{CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC), ID: 42}
+
+	token, err := codec.Encode(want)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if strings.ContainsAny(token, "+/=") {
+		t.Errorf("Encode() = %q, want a URL safe token", token)
+	}
+
+	var got position
+	if err := codec.Decode(token, &got); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if !got.CreatedAt.Equal(want.CreatedAt) || got.ID != want.ID {
+		t.Errorf("Decode() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCodecRejectsTampering(t *testing.T) {
+	codec := NewCodec([]byte("0123456789abcdef0123456789abcdef"))
+	token, err := codec.Encode(position{ID: 42})
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	payload, signature, _ := strings.Cut(token, ".")
+	forged := base64.RawURLEncoding.EncodeToString([]byte(`{"c":"0001-01-01T00:00:00Z","i":1}`))
+	otherToken, err := NewCodec([]byte("another secret of the same length!")).Encode(position{ID: 42})
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "empty", token: ""},
+		{name: "missing signature", token: payload},
+		{name: "forged payload", token: forged + "." + signature},
+		{name: "truncated signature", token: payload + "." + signature[:len(signature)-2]},
+		{name: "signature not base64", token: payload + ".!!!"},
+		{name: "signed with another secret", token: otherToken},
+		{name: "signed payload not JSON", token: mustSign(t, codec, "not json")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got position
+			if err := codec.Decode(tt.token, &got); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("Decode() error = %v, want %v", err, ErrInvalidCursor)
+			}
+		})
+	}
+}
+
+// mustSign returns a correctly signed token carrying raw as its payload
+func mustSign(t *testing.T, codec *Codec, raw string) string {
+	t.Helper()
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(raw))
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(codec.sign(encoded))
+}
@@ -0,0 +1,181 @@
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-clean-architecture/pkg/requestid"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// ProblemMediaType is the media type of RFC 7807 problem details
+const ProblemMediaType = "application/problem+json"
+
+// Error formats selectable with SetErrorFormat
+const (
+	FormatProblem  = "problem"
+	FormatEnvelope = "envelope"
+)
+
+// errorFormat is the format used when the client does not ask for problem details
+var errorFormat atomic.Value
+
+func init() {
+	errorFormat.Store(FormatEnvelope)
+}
+
+// SetErrorFormat selects the default error format. FormatEnvelope, the
+// default, keeps the APIResponse envelope existing clients expect, while
+// FormatProblem sends problem details to everyone. Clients sending
+// "Accept: application/problem+json" always get problem details.
+func SetErrorFormat(format string) error {
+	switch format {
+	case FormatProblem, FormatEnvelope:
+		errorFormat.Store(format)
+		return nil
+	default:
+		return fmt.Errorf("unknown error format %q, expected %q or %q", format, FormatProblem, FormatEnvelope)
+	}
+}
+
+// Problem is an RFC 7807 problem details object
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// Code is the stable machine readable code of the error
+	Code string `json:"code,omitempty"`
+	// Errors lists the request fields that failed validation
+	Errors    []FieldError `json:"errors,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+	// Data carries additional details, such as a failing health report
+	Data interface{} `json:"data,omitempty"`
+}
+
+// FieldError describes why a single request field was rejected
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// FieldErrors is an error made of field level validation failures
+type FieldErrors []FieldError
+
+// Error joins the messages of every field error
+func (e FieldErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, fieldError := range e {
+		messages[i] = fieldError.Field + " " + fieldError.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// UseJSONFieldNames makes validation errors report fields by their JSON name
+func UseJSONFieldNames(validate *validator.Validate) {
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		switch name {
+		case "-":
+			return ""
+		case "":
+			return field.Name
+		}
+		return name
+	})
+}
+
+// wantsProblem reports whether the error should be sent as problem details
+func wantsProblem(c *gin.Context) bool {
+	for _, accept := range strings.Split(c.GetHeader("Accept"), ",") {
+		if mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accept)); err == nil && mediaType == ProblemMediaType {
+			return true
+		}
+	}
+	return errorFormat.Load() == FormatProblem
+}
+
+// newProblem builds problem details, translating validation and decoding
+// errors into field errors
+func newProblem(c *gin.Context, status int, message string, err interface{}, data interface{}) *Problem {
+	problem := &Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    message,
+		Instance:  c.Request.URL.RequestURI(),
+		RequestID: requestid.FromContext(c.Request.Context()),
+		Data:      data,
+	}
+
+	switch e := err.(type) {
+	case nil:
+	case error:
+		if fieldErrors := toFieldErrors(e); len(fieldErrors) > 0 {
+			problem.Errors = fieldErrors
+		} else {
+			problem.Detail = message + ": " + e.Error()
+		}
+	default:
+		problem.Detail = fmt.Sprintf("%s: %v", message, e)
+	}
+	return problem
+}
+
+// toFieldErrors extracts field errors from validator and JSON decoding errors
+func toFieldErrors(err error) []FieldError {
+	var fieldErrors FieldErrors
+	if errors.As(err, &fieldErrors) {
+		return fieldErrors
+	}
+
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		result := make([]FieldError, 0, len(validationErrors))
+		for _, fe := range validationErrors {
+			result = append(result, FieldError{
+				Field:   fe.Field(),
+				Rule:    fe.Tag(),
+				Message: ruleMessage(fe.Tag(), fe.Param()),
+			})
+		}
+		return result
+	}
+
+	var typeError *json.UnmarshalTypeError
+	if errors.As(err, &typeError) && typeError.Field != "" {
+		return []FieldError{{
+			Field:   typeError.Field,
+			Rule:    "type",
+			Message: "must be a " + typeError.Type.Kind().String(),
+		}}
+	}
+	return nil
+}
+
+// ruleMessage describes a failed validation rule in plain words
+func ruleMessage(rule, param string) string {
+	switch rule {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "min":
+		return "must be at least " + param + " characters long"
+	case "max":
+		return "must be at most " + param + " characters long"
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(param, " ", ", ")
+	default:
+		return "failed the " + rule + " rule"
+	}
+}
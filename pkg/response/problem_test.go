@@ -0,0 +1,175 @@
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// sendTestError writes err through Error for a request with the given Accept
+// header and returns the recorded response
+func sendTestError(t *testing.T, accept string, err interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/users?x=1", nil)
+	if accept != "" {
+		c.Request.Header.Set("Accept", accept)
+	}
+	Error(c, http.StatusBadRequest, "bad_request", "Invalid request body", err)
+	return w
+}
+
+// useErrorFormat sets the default error format for the duration of a test
+func useErrorFormat(t *testing.T, format string) {
+	t.Helper()
+	if err := SetErrorFormat(format); err != nil {
+		t.Fatalf("SetErrorFormat() error = %v", err)
+	}
+	t.Cleanup(func() { SetErrorFormat(FormatEnvelope) })
+}
+
+func TestErrorFormatNegotiation(t *testing.T) {
+	tests := []struct {
+		name        string
+		format      string
+		accept      string
+		wantProblem bool
+	}{
+		{name: "envelope by default", format: FormatEnvelope},
+		{name: "envelope for plain JSON", format: FormatEnvelope, accept: "application/json"},
+		{name: "problem when asked for", format: FormatEnvelope, accept: ProblemMediaType, wantProblem: true},
+		{name: "problem among other types", format: FormatEnvelope, accept: "application/json, application/problem+json;q=0.9", wantProblem: true},
+		{name: "problem for everyone", format: FormatProblem, wantProblem: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useErrorFormat(t, tt.format)
+			w := sendTestError(t, tt.accept, errors.New("unexpected EOF"))
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+			var body map[string]interface{}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("body %s is not JSON: %v", w.Body.String(), err)
+			}
+			if body["code"] != "bad_request" {
+				t.Errorf("code = %v, want bad_request", body["code"])
+			}
+
+			contentType := w.Header().Get("Content-Type")
+			if tt.wantProblem {
+				if contentType != ProblemMediaType {
+					t.Errorf("Content-Type = %q, want %q", contentType, ProblemMediaType)
+				}
+				if body["status"] != float64(http.StatusBadRequest) || body["instance"] != "/api/v1/users?x=1" {
+					t.Errorf("problem = %v", body)
+				}
+				if body["detail"] != "Invalid request body: unexpected EOF" {
+					t.Errorf("detail = %v", body["detail"])
+				}
+				return
+			}
+			if contentType == ProblemMediaType {
+				t.Errorf("Content-Type = %q for an envelope", contentType)
+			}
+			if body["success"] != false || body["message"] != "Invalid request body" || body["error"] != "unexpected EOF" {
+				t.Errorf("envelope = %v", body)
+			}
+		})
+	}
+}
+
+func TestSetErrorFormatRejectsUnknown(t *testing.T) {
+	if err := SetErrorFormat("xml"); err == nil {
+		t.Error("SetErrorFormat(xml) error = nil")
+	}
+}
+
+func TestProblemFieldErrors(t *testing.T) {
+	validate := validator.New()
+	UseJSONFieldNames(validate)
+	type request struct {
+		Name     string `json:"name" validate:"min=3"`
+		Email    string `json:"email,omitempty" validate:"required,email"`
+		Role     string `json:"role" validate:"oneof=admin self"`
+		Internal string `json:"-" validate:"required"`
+	}
+	validationErr := validate.Struct(request{Name: "Al", Email: "nope", Role: "root", Internal: "x"})
+
+	var into struct {
+		Age int `json:"age"`
+	}
+	typeErr := json.Unmarshal([]byte(`{"age":"ten"}`), &into)
+
+	tests := []struct {
+		name       string
+		err        error
+		want       []FieldError
+		wantDetail string
+	}{
+		{
+			name: "validator errors by JSON name",
+			err:  validationErr,
+			want: []FieldError{
+				{Field: "name", Rule: "min", Message: "must be at least 3 characters long"},
+				{Field: "email", Rule: "email", Message: "must be a valid email address"},
+				{Field: "role", Rule: "oneof", Message: "must be one of: admin, self"},
+			},
+			wantDetail: "Invalid request body",
+		},
+		{
+			name:       "field errors",
+			err:        FieldErrors{{Field: "password", Rule: "required", Message: "is required"}},
+			want:       []FieldError{{Field: "password", Rule: "required", Message: "is required"}},
+			wantDetail: "Invalid request body",
+		},
+		{
+			name:       "decoding type mismatch",
+			err:        typeErr,
+			want:       []FieldError{{Field: "age", Rule: "type", Message: "must be a int"}},
+			wantDetail: "Invalid request body",
+		},
+		{
+			name:       "other errors stay in the detail",
+			err:        errors.New("unexpected EOF"),
+			wantDetail: "Invalid request body: unexpected EOF",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := sendTestError(t, ProblemMediaType, tt.err)
+
+			var problem Problem
+			if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+				t.Fatalf("body %s is not a problem: %v", w.Body.String(), err)
+			}
+			if !reflect.DeepEqual(problem.Errors, tt.want) {
+				t.Errorf("errors = %+v, want %+v", problem.Errors, tt.want)
+			}
+			if problem.Detail != tt.wantDetail {
+				t.Errorf("detail = %q, want %q", problem.Detail, tt.wantDetail)
+			}
+		})
+	}
+
+	// The envelope never had structured errors, so they become the message
+	w := sendTestError(t, "", FieldErrors{{Field: "password", Rule: "required", Message: "is required"}})
+	var envelope APIResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &envelope); err != nil {
+		t.Fatalf("body %s is not an envelope: %v", w.Body.String(), err)
+	}
+	if envelope.Error != "password is required" {
+		t.Errorf("envelope error = %v, want %q", envelope.Error, "password is required")
+	}
+}
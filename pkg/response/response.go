@@ -1,6 +1,7 @@
 package response
 
 import (
+	"go-clean-architecture/pkg/requestid"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -12,6 +13,10 @@ type APIResponse struct {
 	Message string      `json:"message"`
 	Data    interface{} `json:"data,omitempty"`
 	Error   interface{} `json:"error,omitempty"`
+	// Code is the stable machine readable code of an error
+	Code string `json:"code,omitempty"`
+	// RequestID is set on error responses so clients can report it
+	RequestID string `json:"request_id,omitempty"`
 }
 
 // PaginatedResponse represents paginated response
@@ -23,6 +28,14 @@ type PaginatedResponse struct {
 	TotalPages int         `json:"total_pages"`
 }
 
+// CursorPaginatedResponse represents a keyset paginated response
+type CursorPaginatedResponse struct {
+	Items      interface{} `json:"items"`
+	PageSize   int         `json:"page_size"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+	PrevCursor string      `json:"prev_cursor,omitempty"`
+}
+
 // Success sends a successful response
 func Success(c *gin.Context, message string, data interface{}) {
 	response := APIResponse{
@@ -45,40 +58,83 @@ func Created(c *gin.Context, message string, data interface{}) {
 
 // BadRequest sends a bad request response
 func BadRequest(c *gin.Context, message string, err interface{}) {
-	response := APIResponse{
-		Success: false,
-		Message: message,
-		Error:   err,
-	}
-	c.JSON(http.StatusBadRequest, response)
+	sendError(c, http.StatusBadRequest, "", message, err, nil)
+}
+
+// Unauthorized sends an unauthorized response with a Bearer challenge
+func Unauthorized(c *gin.Context, message string) {
+	c.Header("WWW-Authenticate", `Bearer realm="api"`)
+	sendError(c, http.StatusUnauthorized, "", message, nil, nil)
+}
+
+// Forbidden sends a forbidden response
+func Forbidden(c *gin.Context, message string) {
+	sendError(c, http.StatusForbidden, "", message, nil, nil)
 }
 
 // NotFound sends a not found response
 func NotFound(c *gin.Context, message string) {
-	response := APIResponse{
-		Success: false,
-		Message: message,
-	}
-	c.JSON(http.StatusNotFound, response)
+	sendError(c, http.StatusNotFound, "", message, nil, nil)
 }
 
 // InternalError sends an internal server error response
 func InternalError(c *gin.Context, message string, err interface{}) {
-	response := APIResponse{
-		Success: false,
-		Message: message,
-		Error:   err,
-	}
-	c.JSON(http.StatusInternalServerError, response)
+	sendError(c, http.StatusInternalServerError, "", message, err, nil)
 }
 
 // Conflict sends a conflict response
 func Conflict(c *gin.Context, message string) {
+	sendError(c, http.StatusConflict, "", message, nil, nil)
+}
+
+// PreconditionFailed sends a precondition failed response
+func PreconditionFailed(c *gin.Context, message string) {
+	sendError(c, http.StatusPreconditionFailed, "", message, nil, nil)
+}
+
+// UnsupportedMediaType sends an unsupported media type response
+func UnsupportedMediaType(c *gin.Context, message string) {
+	sendError(c, http.StatusUnsupportedMediaType, "", message, nil, nil)
+}
+
+// UnprocessableEntity sends an unprocessable entity response
+func UnprocessableEntity(c *gin.Context, message string, err interface{}) {
+	sendError(c, http.StatusUnprocessableEntity, "", message, err, nil)
+}
+
+// ServiceUnavailable sends a service unavailable response with details in data
+func ServiceUnavailable(c *gin.Context, message string, data interface{}) {
+	sendError(c, http.StatusServiceUnavailable, "", message, nil, data)
+}
+
+// Error sends an error response with a machine readable code
+func Error(c *gin.Context, status int, code, message string, err interface{}) {
+	sendError(c, status, code, message, err, nil)
+}
+
+// sendError writes an error in the format negotiated for the request
+func sendError(c *gin.Context, status int, code, message string, err interface{}, data interface{}) {
+	if wantsProblem(c) {
+		problem := newProblem(c, status, message, err, data)
+		problem.Code = code
+		c.Header("Content-Type", ProblemMediaType)
+		c.JSON(status, problem)
+		return
+	}
+
+	// Errors are sent as their message, the envelope never had structured errors
+	if e, ok := err.(error); ok {
+		err = e.Error()
+	}
 	response := APIResponse{
-		Success: false,
-		Message: message,
+		Success:   false,
+		RequestID: requestid.FromContext(c.Request.Context()),
+		Message:   message,
+		Data:      data,
+		Error:     err,
+		Code:      code,
 	}
-	c.JSON(http.StatusConflict, response)
+	c.JSON(status, response)
 }
 
 // Paginated sends a paginated response
@@ -100,3 +156,20 @@ func Paginated(c *gin.Context, message string, items interface{}, total int64, p
 	}
 	c.JSON(http.StatusOK, response)
 }
+
+// CursorPaginated sends a keyset paginated response
+func CursorPaginated(c *gin.Context, message string, items interface{}, pageSize int, nextCursor, prevCursor string) {
+	paginatedData := CursorPaginatedResponse{
+		Items:      items,
+		PageSize:   pageSize,
+		NextCursor: nextCursor,
+		PrevCursor: prevCursor,
+	}
+
+	response := APIResponse{
+		Success: true,
+		Message: message,
+		Data:    paginatedData,
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,10 @@
+// Package version holds build information injected at link time, e.g.
+//
+//	go build -ldflags "-X go-clean-architecture/pkg/version.Version=v1.2.3 -X go-clean-architecture/pkg/version.Commit=abc123"
+package version
+
+// Version is the release version of the build
+var Version = "dev"
+
+// Commit is the VCS revision the build was made from
+var Commit = "unknown"